@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"user-service/internal/logger"
+)
+
+func main() {
+	var patterns string
+	var mappingOnly bool
+	flag.StringVar(&patterns, "patterns", "user-service-*", "Comma-separated index patterns for the template")
+	flag.BoolVar(&mappingOnly, "mapping-only", false, "Print only the mappings object instead of a full index template")
+	flag.Parse()
+
+	var out interface{} = logger.IndexTemplate(strings.Split(patterns, ",")...)
+	if mappingOnly {
+		out = logger.IndexMapping()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		log.Fatalf("Failed to write mapping: %v", err)
+	}
+}
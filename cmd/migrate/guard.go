@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errDownNotConfirmed = errors.New("refusing to roll back without -yes")
+	errDownInProduction = errors.New("refusing to roll back in production without -force")
+)
+
+// checkDownAllowed decides whether the down command may run. Rolling back
+// always needs confirmation, and in production it must also be forced, in
+// which case a warning is logged through warnf.
+func checkDownAllowed(environment string, confirmed, forced bool, warnf func(format string, args ...any)) error {
+	if !confirmed {
+		return errDownNotConfirmed
+	}
+	if strings.EqualFold(strings.TrimSpace(environment), "production") {
+		if !forced {
+			return errDownInProduction
+		}
+		warnf("WARNING: forcing a migration rollback in production")
+	}
+	return nil
+}
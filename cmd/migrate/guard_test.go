@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDownAllowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		confirmed   bool
+		forced      bool
+		wantErr     error
+		wantWarning bool
+	}{
+		{"unconfirmed in development", "development", false, false, errDownNotConfirmed, false},
+		{"confirmed in development", "development", true, false, nil, false},
+		{"unconfirmed in production", "production", false, true, errDownNotConfirmed, false},
+		{"confirmed but not forced in production", "production", true, false, errDownInProduction, false},
+		{"environment name is case-insensitive", " Production ", true, false, errDownInProduction, false},
+		{"forced in production", "production", true, true, nil, true},
+		{"force is ignored outside production", "staging", true, true, nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			warnf := func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			}
+
+			err := checkDownAllowed(tc.environment, tc.confirmed, tc.forced, warnf)
+
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantWarning {
+				assert.Len(t, warnings, 1)
+			} else {
+				assert.Empty(t, warnings)
+			}
+		})
+	}
+}
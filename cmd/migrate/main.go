@@ -1,24 +1,40 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"user-service/configs"
 	"user-service/internal/app/migrations"
+	"user-service/pkg/db"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 func main() {
-	var command string
+	var command, target string
+	var jsonOutput, confirmed, forced bool
 	flag.StringVar(&command, "command", "up", "Migration command: up, down, status")
+	flag.StringVar(&target, "to", "", "Migrate up or down to this version, e.g. 012, instead of all pending or the last applied")
+	flag.BoolVar(&jsonOutput, "json", false, "Print the status command's result as JSON on stdout")
+	flag.BoolVar(&confirmed, "yes", false, "Confirm rolling back migrations with the down command")
+	flag.BoolVar(&forced, "force", false, "Allow the down command when ENVIRONMENT=production")
 	flag.Parse()
 
 	// Load configuration
 	cfg := configs.LoadConfig()
 
+	// Check before connecting so a refused rollback never touches the database
+	if command == "down" {
+		if err := checkDownAllowed(cfg.Environment, confirmed, forced, log.Printf); err != nil {
+			log.Fatalf("Migration down refused: %v", err)
+		}
+	}
+
 	// Build MySQL DSN (Data Source Name)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		cfg.DBUser,
@@ -36,7 +52,7 @@ func main() {
 	defer database.Close()
 
 	// Test connection
-	if err := database.Ping(); err != nil {
+	if err := db.PingSQL(context.Background(), database); err != nil {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
@@ -46,14 +62,34 @@ func main() {
 	// Execute command
 	switch command {
 	case "up":
-		if err := runner.MigrateUp(); err != nil {
+		migrate := runner.MigrateUp
+		if target != "" {
+			migrate = func() error { return runner.MigrateUpTo(target) }
+		}
+		if err := migrate(); err != nil {
 			log.Fatalf("Migration up failed: %v", err)
 		}
 	case "down":
-		if err := runner.MigrateDown(); err != nil {
+		migrate := runner.MigrateDown
+		if target != "" {
+			migrate = func() error { return runner.MigrateDownTo(target) }
+		}
+		if err := migrate(); err != nil {
 			log.Fatalf("Migration down failed: %v", err)
 		}
 	case "status":
+		if jsonOutput {
+			report, err := runner.Report()
+			if err != nil {
+				log.Fatalf("Migration status failed: %v", err)
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				log.Fatalf("Failed to write migration status: %v", err)
+			}
+			return
+		}
 		if err := runner.Status(); err != nil {
 			log.Fatalf("Migration status failed: %v", err)
 		}
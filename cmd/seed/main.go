@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"user-service/internal/app/repository"
+	"user-service/internal/app/seed"
+	"user-service/pkg/db"
+)
+
+func main() {
+	var cfg seed.Config
+	flag.IntVar(&cfg.Users, "users", 10, "Number of sample users to create")
+	flag.IntVar(&cfg.ContactsPerUser, "contacts", 5, "Number of contacts to create for each user")
+	flag.Int64Var(&cfg.Seed, "seed", 1, "Random seed; the same seed creates the same data")
+	flag.StringVar(&cfg.Password, "password", "password123", "Password of every sample user")
+	flag.Parse()
+
+	// Initialize DB
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	if err := db.Ping(context.Background(), database); err != nil {
+		log.Fatalf("failed to ping database: %v", err)
+	}
+
+	result, err := seed.Run(context.Background(), repository.NewRepository(database), cfg)
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	if result.Skipped {
+		log.Printf("Seed data already present (%s exists), skipping", seed.Email(0))
+		return
+	}
+	log.Printf("Seeded %d users with %d contacts", result.Users, result.Contacts)
+}
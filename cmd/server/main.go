@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
+	"time"
 	"user-service/configs"
+	"user-service/internal/app/fieldcrypt"
 	"user-service/internal/app/handlers"
+	"user-service/internal/app/jobs"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/qrcode"
 	"user-service/internal/app/repository"
 	"user-service/internal/app/routes"
 	"user-service/internal/app/service"
+	"user-service/internal/app/storage"
+	"user-service/internal/app/token"
+	"user-service/internal/tracing"
+	sharedcache "user-service/pkg/cache"
 	"user-service/pkg/db"
+	redisclient "user-service/pkg/redis"
 
 	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 // @title Contact Management API
@@ -24,25 +37,161 @@ func main() {
 	// Load configuration
 	cfg := configs.LoadConfig()
 
+	// Enable contact field encryption when a key is configured
+	if cfg.FieldEncryptionKey != "" {
+		cipher, err := fieldcrypt.NewCipher(cfg.FieldEncryptionKey)
+		if err != nil {
+			log.Fatalf("failed to initialize field encryption: %v", err)
+		}
+		fieldcrypt.Enable(cipher)
+	}
+
+	// Send trace spans to the collector when one is configured
+	if cfg.OTelExporterEndpoint != "" {
+		exporter := tracing.NewOTLPExporter(cfg.OTelExporterEndpoint, cfg.OTelServiceName)
+		tracing.SetExporter(exporter)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = exporter.Shutdown(ctx)
+		}()
+	}
+
 	// Initialize DB
 	database, err := db.InitDB()
 	if err != nil {
 		log.Fatalf("failed to initialize database: %v", err)
 	}
+	if tracing.Enabled() {
+		if err := db.UseTracing(database); err != nil {
+			log.Fatalf("failed to trace database calls: %v", err)
+		}
+	}
+	if err := db.UseQueryTimeout(database, cfg.DBQueryTimeout); err != nil {
+		log.Fatalf("failed to bound database calls: %v", err)
+	}
+	if err := db.Ping(context.Background(), database); err != nil {
+		log.Fatalf("failed to ping database: %v", err)
+	}
+	if cfg.DBPoolWarmup {
+		took, err := db.Warmup(context.Background(), database, cfg.DBMaxIdleConns)
+		if err != nil {
+			log.Fatalf("failed to warm up database pool: %v", err)
+		}
+		log.Printf("Warmed up %d database connections in %s", cfg.DBMaxIdleConns, took)
+	}
 
 	// Run migrations
 	if err := db.RunMigrations(database); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
+	if cfg.UserPhoneUnique {
+		if err := db.EnsureUniqueUserPhone(database); err != nil {
+			log.Fatalf("failed to enforce unique user phone: %v", err)
+		}
+	}
 
 	// Initialize repository
 	repo := repository.NewRepository(database)
 
+	// Index phone numbers of contacts written before encryption was enabled
+	if backfilled, err := repo.BackfillPhoneHashes(context.Background()); err != nil {
+		log.Fatalf("failed to backfill contact phone hashes: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("Backfilled phone hashes for %d contacts", backfilled)
+	}
+	if backfilled, err := repo.BackfillSearchText(context.Background()); err != nil {
+		log.Fatalf("failed to backfill contact search text: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("Backfilled search text for %d contacts", backfilled)
+	}
+
+	// Start the inactivity purge job when enabled
+	if cfg.InactivityPurgeEnabled {
+		var notifier jobs.Notifier = jobs.LogNotifier{}
+		if cfg.InactivityWebhookURL != "" {
+			notifier = jobs.NewWebhookNotifier(cfg.InactivityWebhookURL)
+		}
+		purger, err := jobs.NewInactivityPurger(repo, jobs.InactivityConfig{
+			Threshold:     time.Duration(cfg.InactivityThresholdDays) * 24 * time.Hour,
+			WarningPeriod: time.Duration(cfg.InactivityWarningDays) * 24 * time.Hour,
+			Action:        cfg.InactivityAction,
+			Interval:      cfg.InactivityCheckInterval,
+		}, notifier)
+		if err != nil {
+			log.Fatalf("invalid inactivity purge configuration: %v", err)
+		}
+		purger.Start(context.Background())
+	}
+
+	// Load the JWT key set
+	keys, err := token.NewKeySet(cfg.JWTSecret, cfg.JWTKeys, cfg.JWTCurrentKID)
+	if err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+
+	// Password reset tokens are logged in development unless a webhook delivers them
+	var resetSender service.ResetSender = service.LogResetSender{IncludeToken: cfg.Environment == "development"}
+	if cfg.PasswordResetWebhookURL != "" {
+		resetSender = service.NewWebhookResetSender(cfg.PasswordResetWebhookURL)
+	}
+
+	// Profiles and contacts are cached in Redis when enabled; the service
+	// falls back to the database whenever Redis cannot be reached
+	var sharedCache sharedcache.Store
+	var redisClient *goredis.Client
+	if cfg.RedisCacheEnabled {
+		redisClient = redisclient.NewRedisClient(net.JoinHostPort(cfg.RedisHost, cfg.RedisPort), cfg.RedisPassword, cfg.RedisDB)
+		if err := redisclient.PingRedis(redisClient); err != nil {
+			log.Printf("Redis is unavailable, serving from the database until it is: %v", err)
+		}
+		sharedCache = sharedcache.NewRedisStore(redisClient)
+	}
+
 	// Initialize service
-	svc := service.NewService(repo, cfg.JWTSecret)
+	svc := service.NewService(repo, token.NewKeySetService(keys, token.WithTTL(cfg.JWTTTL)),
+		service.WithRegistration(cfg.RegistrationEnabled, cfg.RegistrationInviteCodes),
+		service.WithSearchMode(cfg.ContactSearchMode),
+		service.WithContactQuota(cfg.ContactQuota),
+		service.WithContactCountCap(cfg.ContactCountCap),
+		service.WithNameCasing(cfg.ContactNameCasing),
+		service.WithUniqueUserPhone(cfg.UserPhoneUnique),
+		service.WithRegistrationPhonePolicy(cfg.RegistrationPhonePolicy),
+		service.WithProfileCache(cfg.ProfileCacheSize, cfg.ProfileCacheTTL),
+		service.WithPasswordReset(cfg.PasswordResetTTL, resetSender),
+		service.WithLoginLockout(cfg.LoginMaxAttempts, cfg.LoginLockoutDuration),
+		service.WithSharedCache(sharedCache, cfg.RedisCacheTTL),
+	)
+	if tracing.Enabled() {
+		svc = service.NewTracedService(svc)
+	}
 
 	// Initialize handler
-	handler := handlers.NewHandler(svc, cfg.JWTSecret)
+	qrLevel, err := qrcode.ParseLevel(cfg.ContactQRLevel)
+	if err != nil {
+		log.Fatalf("invalid CONTACT_QR_LEVEL: %v", err)
+	}
+	avatars, err := storage.New(cfg.AvatarStorage, cfg.AvatarLocalDir, cfg.AvatarPublicURL, storage.S3Config{
+		Endpoint:  cfg.AvatarS3Endpoint,
+		Region:    cfg.AvatarS3Region,
+		Bucket:    cfg.AvatarS3Bucket,
+		AccessKey: cfg.AvatarS3AccessKey,
+		SecretKey: cfg.AvatarS3SecretKey,
+	})
+	if err != nil {
+		log.Fatalf("invalid AVATAR_STORAGE: %v", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		log.Fatalf("failed to get database handle: %v", err)
+	}
+	handler := handlers.NewHandler(svc,
+		handlers.WithMaxResultWindow(cfg.ContactMaxWindow),
+		handlers.WithMaxListLimit(cfg.ListMaxLimit),
+		handlers.WithQRCode(cfg.ContactQRSize, qrLevel),
+		handlers.WithAvatars(avatars, cfg.AvatarMaxBytes),
+		handlers.WithMigrations(migrations.NewRunner(sqlDB)),
+	)
 
 	// Set Gin to release mode
 	gin.SetMode(gin.ReleaseMode)
@@ -51,7 +200,9 @@ func main() {
 	router := gin.New()
 
 	// Configure routes
-	routes.SetupRoutes(router, handler, cfg.JWTSecret)
+	if err := routes.SetupRoutes(router, handler, cfg, database, redisClient, keys); err != nil {
+		log.Fatalf("failed to set up routes: %v", err)
+	}
 
 	// Start server
 	if err := router.Run(":" + cfg.Port); err != nil {
@@ -3,6 +3,9 @@ package configs
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,14 @@ type Config struct {
 	Port           string
 	Environment    string
 	AllowedOrigins string
+	LogBodies      bool
+	// LogLevel is the lowest level logged: debug, info, warn or error
+	LogLevel string
+
+	// OTelExporterEndpoint is the OTLP/HTTP collector receiving trace spans;
+	// tracing is off when it is empty
+	OTelExporterEndpoint string
+	OTelServiceName      string
 
 	// Database configurations
 	DBHost     string
@@ -22,14 +33,95 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// DBMaxIdleConns is the idle connection pool size; DBPoolWarmup opens that
+	// many connections at startup
+	DBMaxIdleConns int
+	DBPoolWarmup   bool
+	// DBMaxOpenConns caps open connections and DBConnMaxLifetime recycles
+	// them; zero means no limit
+	DBMaxOpenConns    int
+	DBConnMaxLifetime time.Duration
+	// DBQueryTimeout bounds each statement; zero leaves only the request deadline
+	DBQueryTimeout time.Duration
+
 	// Redis configurations
 	RedisHost     string
 	RedisPort     string
 	RedisPassword string
-	RedisDB       string
+	RedisDB       int
+	// RedisCacheEnabled shares cached profiles and contacts through Redis
+	RedisCacheEnabled bool
+	RedisCacheTTL     time.Duration
+
+	// API configurations
+	APIPrefix   string
+	APIVersions []string
+	// ValidationStatusCode is the status of request validation failures (400 or 422)
+	ValidationStatusCode int
+	// ValidationExamples adds example payloads to bind failures outside production
+	ValidationExamples bool
 
 	// JWT configurations
-	JWTSecret string
+	JWTSecret     string
+	JWTKeys       string
+	JWTCurrentKID string
+	JWTTTL        time.Duration
+	AuthCheckUser bool
+
+	// Rate limit configurations
+	AuthRateLimitPerMinute            int
+	VerifyPasswordRateLimitPerMinute  int
+	DirectoryLookupRateLimitPerMinute int
+
+	// Login lockout configurations
+	LoginMaxAttempts     int
+	LoginLockoutDuration time.Duration
+
+	// Registration configurations
+	RegistrationEnabled     bool
+	RegistrationInviteCodes []string
+	UserPhoneUnique         bool
+	RegistrationPhonePolicy string
+
+	// Password reset configurations
+	PasswordResetTTL        time.Duration
+	PasswordResetWebhookURL string
+
+	// Avatar upload configurations
+	AvatarStorage     string
+	AvatarMaxBytes    int64
+	AvatarLocalDir    string
+	AvatarPublicURL   string
+	AvatarS3Endpoint  string
+	AvatarS3Region    string
+	AvatarS3Bucket    string
+	AvatarS3AccessKey string
+	AvatarS3SecretKey string
+
+	// Profile cache configurations
+	ProfileCacheSize int
+	ProfileCacheTTL  time.Duration
+
+	// Contact configurations
+	ContactSearchMode string
+	ContactQuota      int
+	ContactCountCap   int
+	ContactNameCasing string
+	ContactMaxWindow  int
+	ListMaxLimit      int
+	ContactQRSize     int
+	ContactQRLevel    string
+
+	// Field encryption configurations
+	FieldEncryptionKey string
+
+	// Inactivity purge configurations
+	InactivityPurgeEnabled  bool
+	InactivityThresholdDays int
+	InactivityWarningDays   int
+	InactivityAction        string
+	InactivityWebhookURL    string
+	InactivityCheckInterval time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
@@ -50,6 +142,11 @@ func LoadConfig() Config {
 		Environment:    getEnv("ENVIRONMENT", "development"),
 		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "*"),
 
+		OTelExporterEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTelServiceName:      getEnv("OTEL_SERVICE_NAME", "user-service"),
+		LogBodies:            getEnvBool("LOG_BODIES", true),
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+
 		// Database configurations
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "3306"),
@@ -58,14 +155,94 @@ func LoadConfig() Config {
 		DBName:     getEnv("DB_NAME", "getcontact"),
 		DBSSLMode:  getEnv("DB_SSL_MODE", "false"),
 
+		DBMaxIdleConns: getEnvInt("DB_MAX_IDLE_CONNS", 2),
+		DBPoolWarmup:   getEnvBool("DB_POOL_WARMUP", false),
+
+		DBMaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBQueryTimeout:    getEnvDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+
 		// Redis configurations
 		RedisHost:     getEnv("REDIS_HOST", "localhost"),
 		RedisPort:     getEnv("REDIS_PORT", "6379"),
 		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-		RedisDB:       getEnv("REDIS_DB", "0"),
+		RedisDB:       getEnvInt("REDIS_DB", 0),
+
+		RedisCacheEnabled: getEnvBool("REDIS_CACHE_ENABLED", false),
+		RedisCacheTTL:     getEnvDuration("REDIS_CACHE_TTL", 5*time.Minute),
+
+		// API configurations
+		APIPrefix:            getEnv("API_PREFIX", "/api"),
+		APIVersions:          getEnvList("API_VERSIONS", []string{"v1"}),
+		ValidationStatusCode: getEnvInt("VALIDATION_STATUS_CODE", 400),
+		ValidationExamples:   getEnvBool("VALIDATION_EXAMPLES", false),
 
 		// JWT configurations
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key"),
+		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
+		JWTKeys:       getEnv("JWT_KEYS", ""),
+		JWTCurrentKID: getEnv("JWT_CURRENT_KID", ""),
+		JWTTTL:        getEnvDuration("JWT_TTL", 24*time.Hour),
+		AuthCheckUser: getEnvBool("AUTH_CHECK_USER", false),
+
+		// Rate limit configurations
+		AuthRateLimitPerMinute:            getEnvInt("AUTH_RATE_LIMIT_PER_MINUTE", 20),
+		VerifyPasswordRateLimitPerMinute:  getEnvInt("VERIFY_PASSWORD_RATE_LIMIT_PER_MINUTE", 5),
+		DirectoryLookupRateLimitPerMinute: getEnvInt("DIRECTORY_LOOKUP_RATE_LIMIT_PER_MINUTE", 3),
+
+		// Login lockout configurations
+		LoginMaxAttempts:     getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginLockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+
+		// Registration configurations
+		RegistrationEnabled:     getEnvBool("REGISTRATION_ENABLED", true),
+		RegistrationInviteCodes: getEnvList("REGISTRATION_INVITE_CODES", nil),
+		UserPhoneUnique:         getEnvBool("USER_PHONE_UNIQUE", false),
+		RegistrationPhonePolicy: getEnv("REGISTRATION_PHONE_POLICY", "allow"),
+
+		// Password reset configurations
+		PasswordResetTTL:        getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetWebhookURL: getEnv("PASSWORD_RESET_WEBHOOK_URL", ""),
+
+		// Avatar upload configurations
+		AvatarStorage:     getEnv("AVATAR_STORAGE", "local"),
+		AvatarMaxBytes:    int64(getEnvInt("AVATAR_MAX_BYTES", 2<<20)),
+		AvatarLocalDir:    getEnv("AVATAR_LOCAL_DIR", "uploads/avatars"),
+		AvatarPublicURL:   getEnv("AVATAR_PUBLIC_URL", ""),
+		AvatarS3Endpoint:  getEnv("AVATAR_S3_ENDPOINT", ""),
+		AvatarS3Region:    getEnv("AVATAR_S3_REGION", "us-east-1"),
+		AvatarS3Bucket:    getEnv("AVATAR_S3_BUCKET", ""),
+		AvatarS3AccessKey: getEnv("AVATAR_S3_ACCESS_KEY", ""),
+		AvatarS3SecretKey: getEnv("AVATAR_S3_SECRET_KEY", ""),
+
+		// Profile cache configurations
+		ProfileCacheSize: getEnvInt("PROFILE_CACHE_SIZE", 0),
+		ProfileCacheTTL:  getEnvDuration("PROFILE_CACHE_TTL", 30*time.Second),
+
+		// Contact configurations
+		ContactSearchMode: getEnv("CONTACT_SEARCH_MODE", "optional"),
+		ContactQuota:      getEnvInt("CONTACT_QUOTA", 0),
+		ContactCountCap:   getEnvInt("CONTACT_COUNT_CAP", 0),
+		ContactNameCasing: getEnv("CONTACT_NAME_CASING", "preserve"),
+		ContactMaxWindow:  getEnvInt("CONTACT_MAX_RESULT_WINDOW", 10000),
+		ListMaxLimit:      getEnvInt("LIST_MAX_LIMIT", 100),
+		ContactQRSize:     getEnvInt("CONTACT_QR_SIZE", 256),
+		ContactQRLevel:    getEnv("CONTACT_QR_LEVEL", "M"),
+
+		// Field encryption configurations
+		FieldEncryptionKey: getEnv("FIELD_ENCRYPTION_KEY", ""),
+
+		// Inactivity purge configurations
+		InactivityPurgeEnabled:  getEnvBool("INACTIVITY_PURGE_ENABLED", false),
+		InactivityThresholdDays: getEnvInt("INACTIVITY_THRESHOLD_DAYS", 365),
+		InactivityWarningDays:   getEnvInt("INACTIVITY_WARNING_DAYS", 14),
+		InactivityAction:        getEnv("INACTIVITY_ACTION", "flag"),
+		InactivityWebhookURL:    getEnv("INACTIVITY_WEBHOOK_URL", ""),
+		InactivityCheckInterval: getEnvDuration("INACTIVITY_CHECK_INTERVAL", 24*time.Hour),
+	}
+
+	// Locally stored avatars are served by this server unless a public URL is set
+	if config.AvatarStorage == "local" && config.AvatarPublicURL == "" {
+		config.AvatarPublicURL = "/avatars"
 	}
 
 	return config
@@ -78,3 +255,51 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvBool gets a boolean environment variable with fallback
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Warning: invalid boolean for %s, using default", key)
+	}
+	return fallback
+}
+
+// getEnvInt gets an integer environment variable with fallback
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Warning: invalid integer for %s, using default", key)
+	}
+	return fallback
+}
+
+// getEnvDuration gets a duration environment variable (e.g. "24h") with fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Warning: invalid duration for %s, using default", key)
+	}
+	return fallback
+}
+
+// getEnvList gets a comma-separated environment variable as a list with fallback
+func getEnvList(key string, fallback []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
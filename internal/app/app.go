@@ -1,17 +1,78 @@
 package app
 
 import (
+	"net"
 	"user-service/configs"
 	"user-service/internal/app/handlers"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/qrcode"
 	"user-service/internal/app/repository"
 	"user-service/internal/app/service"
+	"user-service/internal/app/storage"
+	"user-service/internal/app/token"
+	"user-service/internal/tracing"
+	sharedcache "user-service/pkg/cache"
+	redisclient "user-service/pkg/redis"
 
 	"gorm.io/gorm"
 )
 
 // NewHandler creates a new handler instance
-func NewHandler(cfg configs.Config, db *gorm.DB) *handlers.Handler {
+func NewHandler(cfg configs.Config, db *gorm.DB) (*handlers.Handler, error) {
+	keys, err := token.NewKeySet(cfg.JWTSecret, cfg.JWTKeys, cfg.JWTCurrentKID)
+	if err != nil {
+		return nil, err
+	}
+
 	repo := repository.NewRepository(db)
-	svc := service.NewService(repo, cfg.JWTSecret)
-	return handlers.NewHandler(svc, cfg.JWTSecret)
+	var resetSender service.ResetSender = service.LogResetSender{IncludeToken: cfg.Environment == "development"}
+	if cfg.PasswordResetWebhookURL != "" {
+		resetSender = service.NewWebhookResetSender(cfg.PasswordResetWebhookURL)
+	}
+	var sharedCache sharedcache.Store
+	if cfg.RedisCacheEnabled {
+		client := redisclient.NewRedisClient(net.JoinHostPort(cfg.RedisHost, cfg.RedisPort), cfg.RedisPassword, cfg.RedisDB)
+		sharedCache = sharedcache.NewRedisStore(client)
+	}
+	svc := service.NewService(repo, token.NewKeySetService(keys, token.WithTTL(cfg.JWTTTL)),
+		service.WithRegistration(cfg.RegistrationEnabled, cfg.RegistrationInviteCodes),
+		service.WithSearchMode(cfg.ContactSearchMode),
+		service.WithContactQuota(cfg.ContactQuota),
+		service.WithContactCountCap(cfg.ContactCountCap),
+		service.WithNameCasing(cfg.ContactNameCasing),
+		service.WithUniqueUserPhone(cfg.UserPhoneUnique),
+		service.WithRegistrationPhonePolicy(cfg.RegistrationPhonePolicy),
+		service.WithProfileCache(cfg.ProfileCacheSize, cfg.ProfileCacheTTL),
+		service.WithPasswordReset(cfg.PasswordResetTTL, resetSender),
+		service.WithLoginLockout(cfg.LoginMaxAttempts, cfg.LoginLockoutDuration),
+		service.WithSharedCache(sharedCache, cfg.RedisCacheTTL),
+	)
+	if tracing.Enabled() {
+		svc = service.NewTracedService(svc)
+	}
+	qrLevel, err := qrcode.ParseLevel(cfg.ContactQRLevel)
+	if err != nil {
+		return nil, err
+	}
+	avatars, err := storage.New(cfg.AvatarStorage, cfg.AvatarLocalDir, cfg.AvatarPublicURL, storage.S3Config{
+		Endpoint:  cfg.AvatarS3Endpoint,
+		Region:    cfg.AvatarS3Region,
+		Bucket:    cfg.AvatarS3Bucket,
+		AccessKey: cfg.AvatarS3AccessKey,
+		SecretKey: cfg.AvatarS3SecretKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return handlers.NewHandler(svc,
+		handlers.WithMaxResultWindow(cfg.ContactMaxWindow),
+		handlers.WithMaxListLimit(cfg.ListMaxLimit),
+		handlers.WithQRCode(cfg.ContactQRSize, qrLevel),
+		handlers.WithAvatars(avatars, cfg.AvatarMaxBytes),
+		handlers.WithMigrations(migrations.NewRunner(sqlDB)),
+	), nil
 }
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/middleware"
+	"user-service/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RealAuth(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	_, err = CreateTestContact(ctx, repo, user.ID)
+	require.NoError(t, err)
+
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := testutil.NewAuthRouter(t, "/api/v1", user.ID, models.RoleUser)
+	router.Protected.GET("/me", handler.GetProfile)
+	router.Protected.GET("/contacts", handler.ListContacts)
+
+	t.Run("valid token reaches the handler as its user", func(t *testing.T) {
+		w := router.Do(http.MethodGet, "/api/v1/me", nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, user.Email, response.Data.(map[string]interface{})["email"])
+	})
+
+	t.Run("contacts are scoped to the token's user", func(t *testing.T) {
+		other := testutil.AuthToken(t, user.ID+1, models.RoleUser)
+
+		mine := router.Do(http.MethodGet, "/api/v1/contacts", nil)
+		theirs := router.DoWithToken(http.MethodGet, "/api/v1/contacts", nil, other)
+
+		require.Equal(t, http.StatusOK, mine.Code)
+		require.Equal(t, http.StatusOK, theirs.Code)
+		assert.Contains(t, mine.Body.String(), `"count":1`)
+		assert.Contains(t, theirs.Body.String(), `"count":0`)
+	})
+
+	t.Run("missing token is rejected", func(t *testing.T) {
+		w := router.DoWithToken(http.MethodGet, "/api/v1/me", nil, "")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token signed with another secret is rejected", func(t *testing.T) {
+		forged, err := token.NewService("some_other_secret").Generate(user.ID, models.RoleUser)
+		require.NoError(t, err)
+
+		w := router.DoWithToken(http.MethodGet, "/api/v1/me", nil, forged)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandler_RequireUser(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithProfileCache(10, time.Minute))
+	handler := handlers.NewHandler(svc)
+	router := testutil.NewAuthRouter(t, "/api/v1", user.ID, models.RoleUser)
+	router.Protected.Use(handler.RequireUser)
+	router.Protected.GET("/me", handler.GetProfile)
+
+	t.Run("existing user is let through", func(t *testing.T) {
+		w := router.Do(http.MethodGet, "/api/v1/me", nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), user.Email)
+	})
+
+	t.Run("token of a deleted user is rejected", func(t *testing.T) {
+		// Deleted behind the service's back, as the inactivity job does, with the profile still cached
+		_, err := svc.GetUserProfile(ctx, user.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteUser(ctx, user.ID))
+
+		w := router.Do(http.MethodGet, "/api/v1/me", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "User no longer exists")
+	})
+
+	t.Run("token of a user that never existed is rejected", func(t *testing.T) {
+		w := router.DoWithToken(http.MethodGet, "/api/v1/me", nil, testutil.AuthToken(t, user.ID+100, models.RoleUser))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandler_Logout(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := testutil.NewAuthRouter(t, "/api/v1", user.ID, models.RoleUser)
+	router.Protected = router.Engine.Group("/api/v1", middleware.AuthMiddlewareWithKeys(
+		token.SecretKeySet(GetTestJWTSecret()),
+		middleware.WithRevocationCheck(handler.IsTokenRevoked),
+	))
+	router.Protected.GET("/me", handler.GetProfile)
+	router.Protected.POST("/auth/logout", handler.Logout)
+
+	other := testutil.AuthToken(t, user.ID, models.RoleUser)
+	require.Equal(t, http.StatusOK, router.Do(http.MethodGet, "/api/v1/me", nil).Code)
+
+	w := router.Do(http.MethodPost, "/api/v1/auth/logout", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Logged out successfully", response.Message)
+
+	t.Run("revoked token is rejected", func(t *testing.T) {
+		w := router.Do(http.MethodGet, "/api/v1/me", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Token has been revoked")
+	})
+
+	t.Run("other tokens of the user stay valid", func(t *testing.T) {
+		w := router.DoWithToken(http.MethodGet, "/api/v1/me", nil, other)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("tokens without a jti cannot be revoked", func(t *testing.T) {
+		legacy := router.Engine.Group("/legacy", func(c *gin.Context) {
+			c.Set("user_id", user.ID)
+			c.Next()
+		})
+		legacy.POST("/auth/logout", handler.Logout)
+
+		w := router.Do(http.MethodPost, "/legacy/auth/logout", nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/storage"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_UploadAvatar(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+	gin.SetMode(gin.TestMode)
+	upload := func(h *handlers.Handler, data []byte) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/me/avatar", func(c *gin.Context) {
+			c.Set("user_id", user.ID)
+			h.UploadAvatar(c)
+		})
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if data != nil {
+			part, _ := writer.CreateFormFile("avatar", "avatar.png")
+			_, _ = part.Write(data)
+		}
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/me/avatar", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, image.NewGray(image.Rect(0, 0, 8, 8))))
+	h := handlers.NewHandler(svc, handlers.WithAvatars(storage.NewLocalStore(dir, "/avatars"), 1024))
+
+	t.Run("stores the image and sets the avatar url", func(t *testing.T) {
+		w := upload(h, pngData.Bytes())
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		avatarURL := response.Data.(map[string]interface{})["avatar_url"].(string)
+		assert.True(t, strings.HasPrefix(avatarURL, "/avatars/"), avatarURL)
+		assert.True(t, strings.HasSuffix(avatarURL, ".png"), avatarURL)
+
+		stored, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(avatarURL, "/avatars/")))
+		require.NoError(t, err)
+		assert.Equal(t, pngData.Bytes(), stored)
+
+		profile, err := svc.GetUserProfile(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, profile.AvatarURL)
+		assert.Equal(t, avatarURL, *profile.AvatarURL)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		w := upload(h, []byte("GIF89a not really an image"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unsupported avatar type")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		w := upload(h, append(append([]byte{}, pngData.Bytes()...), make([]byte, 1024)...))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := upload(h, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("uploads not configured", func(t *testing.T) {
+		w := upload(handlers.NewHandler(svc), pngData.Bytes())
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
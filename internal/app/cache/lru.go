@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a concurrency-safe least-recently-used cache whose entries also
+// expire after a fixed TTL. A nil *LRU is valid and caches nothing, which is
+// how callers bypass caching.
+type LRU[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache holding at most size entries, each for at most ttl.
+// A non-positive size returns nil (caching disabled); a non-positive ttl keeps
+// entries until they are evicted.
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	return &LRU[K, V]{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[K]*list.Element, size),
+	}
+}
+
+// Get returns the cached value for key and marks it as recently used
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if c.ttl > 0 && !c.now().Before(e.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry when full
+func (c *LRU[K, V]) Set(key K, value V) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete invalidates key
+func (c *LRU[K, V]) Delete(key K) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Purge invalidates every entry
+func (c *LRU[K, V]) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[K]*list.Element, c.size)
+}
+
+// Len returns the number of cached entries, including expired ones not yet removed
+func (c *LRU[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("hit and miss", func(t *testing.T) {
+		c := NewLRU[string, int](2, time.Minute)
+		c.Set("5550001111", 1)
+
+		value, ok := c.Get("5550001111")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		_, ok = c.Get("5550002222")
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		c := NewLRU[string, int](2, time.Minute)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		_, _ = c.Get("a")
+		c.Set("c", 3)
+
+		_, ok := c.Get("b")
+		assert.False(t, ok)
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+		_, ok = c.Get("c")
+		assert.True(t, ok)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		c := NewLRU[string, int](2, time.Minute)
+		c.now = func() time.Time { return now }
+		c.Set("a", 1)
+
+		now = now.Add(59 * time.Second)
+		_, ok := c.Get("a")
+		assert.True(t, ok)
+
+		now = now.Add(time.Second)
+		_, ok = c.Get("a")
+		assert.False(t, ok)
+		assert.Zero(t, c.Len())
+	})
+
+	t.Run("invalidation on a new report", func(t *testing.T) {
+		c := NewLRU[string, int](2, time.Minute)
+		c.Set("5550001111", 0)
+
+		// A report against the number must drop the stale score
+		c.Delete("5550001111")
+
+		_, ok := c.Get("5550001111")
+		assert.False(t, ok)
+	})
+
+	t.Run("purge clears everything", func(t *testing.T) {
+		c := NewLRU[string, int](2, time.Minute)
+		c.Set("a", 1)
+		c.Set("b", 2)
+
+		c.Purge()
+
+		assert.Zero(t, c.Len())
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("disabled cache is bypassed", func(t *testing.T) {
+		c := NewLRU[string, int](0, time.Minute)
+		require.Nil(t, c)
+
+		c.Set("a", 1)
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		c.Delete("a")
+		c.Purge()
+		assert.Zero(t, c.Len())
+	})
+
+	t.Run("safe under concurrent use", func(t *testing.T) {
+		c := NewLRU[string, int](16, time.Minute)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					key := fmt.Sprintf("%d", (i*j)%32)
+					c.Set(key, j)
+					c.Get(key)
+					if j%10 == 0 {
+						c.Delete(key)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, c.Len(), 16)
+	})
+}
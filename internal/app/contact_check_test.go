@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CheckContactPhone(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	other, err := repo.CreateUser(ctx, &models.User{FullName: "Other User", Email: "other@example.com", Password: "hashedpassword"})
+	require.NoError(t, err)
+
+	_, err = repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Mine", Phone: "14155550001"})
+	require.NoError(t, err)
+	_, err = repo.CreateContact(ctx, &models.Contact{UserID: other.ID, FullName: "Theirs", Phone: "14155550002"})
+	require.NoError(t, err)
+	deleted, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Gone", Phone: "14155550003"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteContact(ctx, user.ID, deleted.ID))
+
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	router.GET("/api/v1/contacts/check", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		h.CheckContactPhone(c)
+	})
+
+	check := func(phone string) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/contacts/check?phone="+url.QueryEscape(phone), nil)
+		router.ServeHTTP(w, req)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data, _ := response.Data.(map[string]interface{})
+		return w.Code, data
+	}
+
+	t.Run("existing number in any format", func(t *testing.T) {
+		for _, phone := range []string{"14155550001", "+14155550001", "+1 (415) 555-0001"} {
+			code, data := check(phone)
+			require.Equal(t, http.StatusOK, code, phone)
+			assert.Equal(t, map[string]interface{}{"exists": true}, data, phone)
+		}
+	})
+
+	t.Run("unknown number", func(t *testing.T) {
+		code, data := check("+14155550009")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, false, data["exists"])
+	})
+
+	t.Run("another user's number", func(t *testing.T) {
+		code, data := check("+14155550002")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, false, data["exists"])
+	})
+
+	t.Run("deleted contact", func(t *testing.T) {
+		code, data := check("+14155550003")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, false, data["exists"])
+	})
+
+	t.Run("missing or invalid phone", func(t *testing.T) {
+		code, _ := check("")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = check("not a phone")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/app/vcard"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactCompanyAndJobTitle(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	runner := &fakeRunner{}
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithRunner(runner))
+	str := func(s string) *string { return &s }
+
+	created, err := svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{
+		FullName: "Jane Doe",
+		Phone:    "1111111111",
+		Company:  str("  Acme Corp "),
+		JobTitle: str("CTO"),
+	})
+	require.NoError(t, err)
+
+	t.Run("stored and returned", func(t *testing.T) {
+		loaded, err := svc.GetContact(ctx, user.ID, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Corp", *loaded.Company)
+		assert.Equal(t, "CTO", *loaded.JobTitle)
+	})
+
+	t.Run("searchable", func(t *testing.T) {
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Query: "acme", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, created.ID, contacts[0].ID)
+
+		contacts, _, err = svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Query: "CTO", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, contacts, 1)
+	})
+
+	t.Run("updated only when sent and cleared by an empty string", func(t *testing.T) {
+		updated, err := svc.UpdateContact(ctx, user.ID, created.ID, &models.UpdateContactRequest{
+			FullName: "Jane Doe",
+			Phone:    "1111111111",
+			JobTitle: str(""),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Corp", *updated.Company)
+		assert.Nil(t, updated.JobTitle)
+	})
+
+	t.Run("imported from csv columns", func(t *testing.T) {
+		_, err := svc.StartContactImport(ctx, user.ID, []byte("full_name,phone,company,job_title\nBob,2222222222,Globex,Engineer\n"), "")
+		require.NoError(t, err)
+		runner.RunAll()
+
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Query: "globex", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Engineer", *contacts[0].JobTitle)
+	})
+
+	t.Run("vcard export round-trips through import", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		h := handlers.NewHandler(svc)
+		router := gin.New()
+		router.GET("/contacts/:id/vcard", func(c *gin.Context) {
+			c.Set("user_id", user.ID)
+			h.ExportContactVCard(c)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/contacts/%d/vcard", created.ID), nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, vcard.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "ORG:Acme Corp\r\n")
+
+		other := TestUser()
+		other.Email, other.Phone = "other@example.com", nil
+		other, err = repo.CreateUser(ctx, other)
+		require.NoError(t, err)
+		_, err = svc.StartContactImport(ctx, other.ID, w.Body.Bytes(), "")
+		require.NoError(t, err)
+		runner.RunAll()
+
+		contacts, _, err := svc.ListContacts(ctx, other.ID, &models.ListContactsRequest{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Jane Doe", contacts[0].FullName)
+		assert.Equal(t, "Acme Corp", *contacts[0].Company)
+		assert.Nil(t, contacts[0].JobTitle)
+		assert.Equal(t, models.ContactSourceVCardImport, contacts[0].Source)
+	})
+}
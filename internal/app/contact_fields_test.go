@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ContactFieldsMeta(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/contacts/meta", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Fields []models.ContactField `json:"fields"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, models.ContactFields, response.Data.Fields)
+
+	// Every advertised capability must be accepted by list validation, and
+	// everything else rejected
+	mockService.On("ContactsVersion", mock.Anything, uint(1)).Return(models.ContactsVersion{}, nil)
+	mockService.On("ListContacts", mock.Anything, uint(1), mock.Anything).Return([]models.Contact{}, int64(0), nil)
+	list := func(query url.Values) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/contacts?"+query.Encode(), nil)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	for _, field := range response.Data.Fields {
+		for _, sort := range []string{field.Name, "-" + field.Name} {
+			want := http.StatusBadRequest
+			if field.Sortable {
+				want = http.StatusOK
+			}
+			assert.Equal(t, want, list(url.Values{"sort": {sort}}), "sort=%s", sort)
+		}
+		for _, value := range field.Values {
+			assert.Equal(t, http.StatusOK, list(url.Values{field.Param: {value}}), "%s=%s", field.Param, value)
+		}
+		if field.Type == models.FieldTypeEnum {
+			assert.Equal(t, http.StatusBadRequest, list(url.Values{field.Param: {"not-a-value"}}))
+		}
+	}
+}
+
+func TestListContacts_Sort(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	for i, name := range []string{"Bravo", "Alpha", "Charlie", "Alpha"} {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: name, Phone: fmt.Sprintf("%s-phone-%d", name, i)})
+		require.NoError(t, err)
+	}
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+	names := func(contacts []models.Contact) []string {
+		var out []string
+		for _, c := range contacts {
+			out = append(out, c.FullName)
+		}
+		return out
+	}
+
+	t.Run("list", func(t *testing.T) {
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Sort: "-full_name", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Charlie", "Bravo", "Alpha", "Alpha"}, names(contacts))
+		// Ties keep ID order
+		assert.Less(t, contacts[2].ID, contacts[3].ID)
+
+		contacts, _, err = svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Sort: "full_name", Page: 2, Limit: 3, Offset: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Charlie"}, names(contacts))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var streamed []models.Contact
+		err := svc.StreamContacts(ctx, user.ID, &models.ListContactsRequest{Sort: "full_name", Page: 1, Limit: 3}, func(c *models.Contact) error {
+			streamed = append(streamed, *c)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alpha", "Alpha", "Bravo"}, names(streamed))
+	})
+
+	t.Run("unsortable fields are rejected listing the sortable ones", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/contacts?sort=phone", nil)
+
+		_, err := models.ParseListContactsRequest(c, 0, 0)
+
+		var paramsErr *models.ListParamsError
+		require.ErrorAs(t, err, &paramsErr)
+		assert.Equal(t, "sort", paramsErr.Field)
+		assert.Contains(t, err.Error(), "full_name")
+	})
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactPhoneUnique(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	other, err := repo.CreateUser(ctx, &models.User{FullName: "Other User", Email: "other@example.com", Password: "hashedpassword"})
+	require.NoError(t, err)
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+	t.Run("concurrent creates with one phone", func(t *testing.T) {
+		const creates = 2
+		errs := make([]error, creates)
+		var wg sync.WaitGroup
+		for i := range creates {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{FullName: "Racer", Phone: "+14155550001"})
+			}()
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, service.ErrPhoneExists)
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("the index rejects a duplicate that skipped the check", func(t *testing.T) {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Sneaky", Phone: "14155550001"})
+		assert.Error(t, err)
+	})
+
+	t.Run("other users and deleted contacts do not conflict", func(t *testing.T) {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: other.ID, FullName: "Theirs", Phone: "14155550001"})
+		require.NoError(t, err)
+
+		deleted, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Old", Phone: "14155550002"})
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteContact(ctx, user.ID, deleted.ID))
+		_, err = svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{FullName: "New", Phone: "+14155550002"})
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, svc.RestoreContact(ctx, user.ID, deleted.ID), service.ErrPhoneExists)
+	})
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/qrcode"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/app/vcard"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetContactQRCode(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	contact, err := CreateTestContact(ctx, repo, user.ID)
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+	h := handlers.NewHandler(svc, handlers.WithQRCode(200, qrcode.Quartile))
+
+	gin.SetMode(gin.TestMode)
+	get := func(userID, contactID uint) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/contacts/:id/qr", func(c *gin.Context) {
+			c.Set("user_id", userID)
+			h.GetContactQRCode(c)
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/contacts/%d/qr", contactID), nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("png of the contact's vcard", func(t *testing.T) {
+		w := get(user.ID, contact.ID)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+
+		img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, img.Bounds().Dx(), 200)
+
+		// The qrcode package tests scan images back to their data; here the
+		// image must be exactly the symbol for the contact's vCard
+		stored, err := svc.GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		want, err := qrcode.PNG(vcard.Marshal(*stored), qrcode.Quartile, 200)
+		require.NoError(t, err)
+		assert.Equal(t, want, w.Body.Bytes())
+	})
+
+	t.Run("other users' contacts are not found", func(t *testing.T) {
+		w := get(user.ID+1, contact.ID)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupDirectory(t *testing.T) {
+	ctx := context.Background()
+
+	seed := func(t *testing.T, repo repository.Repository) {
+		t.Helper()
+		for _, u := range []struct {
+			email, name, phone string
+			optIn              bool
+		}{
+			{"listed@example.com", "Listed User", "5550000001", true},
+			{"private@example.com", "Private User", "5550000002", false},
+			{"clean@example.com", "Clean User", "5550000003", true},
+		} {
+			user := TestUser()
+			user.Email, user.FullName, user.Phone = u.email, u.name, stringPtr(u.phone)
+			user, err := repo.CreateUser(ctx, user)
+			require.NoError(t, err)
+			if u.optIn {
+				_, err = repo.UpdateUser(ctx, user.ID, map[string]interface{}{"directory_opt_in": true})
+				require.NoError(t, err)
+			}
+		}
+
+		// Two people blocked the listed number, one only saved it; the private
+		// number was blocked too
+		for i, c := range []struct {
+			phone   string
+			blocked bool
+		}{
+			{"5550000001", true},
+			{"5550000001", true},
+			{"5550000001", false},
+			{"5550000002", true},
+		} {
+			owner := TestUser()
+			owner.Email, owner.Phone = fmt.Sprintf("owner%d@example.com", i), nil
+			owner, err := repo.CreateUser(ctx, owner)
+			require.NoError(t, err)
+			_, err = repo.CreateContact(ctx, &models.Contact{UserID: owner.ID, FullName: "Saved", Phone: c.phone, Blocked: c.blocked})
+			require.NoError(t, err)
+		}
+	}
+
+	lookup := func(t *testing.T, svc service.Service) []models.DirectoryEntry {
+		t.Helper()
+		entries, err := svc.LookupDirectory(ctx, []string{"5550000001", "5550000002", " 5550000003 ", "5550000001", "5559999999"})
+		require.NoError(t, err)
+		return entries
+	}
+	want := []models.DirectoryEntry{
+		{Phone: "5550000001", FullName: "Listed User", SpamReports: 2},
+		{Phone: "5550000003", FullName: "Clean User", SpamReports: 0},
+	}
+
+	t.Run("only opted-in users are returned", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		seed(t, repo)
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+		assert.Equal(t, want, lookup(t, svc))
+	})
+
+	t.Run("spam reports match encrypted phones", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		enableTestEncryption(t)
+		seed(t, repo)
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+		assert.Equal(t, want, lookup(t, svc))
+	})
+
+	t.Run("opting out removes the user", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		seed(t, repo)
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+		listed, err := repo.GetUserByEmail(ctx, "listed@example.com")
+		require.NoError(t, err)
+		optOut := false
+		_, err = svc.UpdateProfile(ctx, listed.ID, models.UpdateProfileRequest{FullName: listed.FullName, DirectoryOptIn: &optOut})
+		require.NoError(t, err)
+
+		assert.Equal(t, want[1:], lookup(t, svc))
+	})
+
+	t.Run("empty and oversized lookups are rejected", func(t *testing.T) {
+		svc := service.NewService(new(MockRepository), token.NewService(GetTestJWTSecret()))
+
+		_, err := svc.LookupDirectory(ctx, []string{" ", ""})
+		assert.Equal(t, service.ErrLookupEmpty, err)
+
+		phones := make([]string, service.MaxLookupPhones+1)
+		for i := range phones {
+			phones[i] = fmt.Sprintf("555%07d", i)
+		}
+		_, err = svc.LookupDirectory(ctx, phones)
+		assert.Equal(t, service.ErrLookupTooLarge, err)
+	})
+}
+
+func TestHandler_LookupDirectory(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/lookup/batch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockService.On("LookupDirectory", mock.Anything, []string{"5550000001", "5550000002"}).
+		Return([]models.DirectoryEntry{{Phone: "5550000001", FullName: "Listed User", SpamReports: 2}}, nil).Once()
+
+	w := post(`{"phones": ["5550000001", "5550000002"]}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Results []models.DirectoryEntry `json:"results"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []models.DirectoryEntry{{Phone: "5550000001", FullName: "Listed User", SpamReports: 2}}, response.Data.Results)
+
+	mockService.On("LookupDirectory", mock.Anything, []string{}).Return(nil, service.ErrLookupEmpty).Once()
+	assert.Equal(t, http.StatusBadRequest, post(`{"phones": []}`).Code)
+
+	// The limiter allows two lookups a minute in the test router
+	assert.Equal(t, http.StatusTooManyRequests, post(`{"phones": ["5550000001"]}`).Code)
+	mockService.AssertExpectations(t)
+}
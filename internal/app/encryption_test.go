@@ -0,0 +1,216 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/fieldcrypt"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableTestEncryption turns on field encryption for the duration of a test
+func enableTestEncryption(t *testing.T) {
+	t.Helper()
+	cipher, err := fieldcrypt.NewCipher(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+	require.NoError(t, err)
+	fieldcrypt.Enable(cipher)
+	t.Cleanup(func() { fieldcrypt.Enable(nil) })
+}
+
+func TestFieldEncryption(t *testing.T) {
+	testDB, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+	enableTestEncryption(t)
+
+	user, err := CreateTestUser(context.Background(), repo)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	api.POST("/contacts", handler.CreateContact)
+	api.GET("/contacts/:id", handler.GetContact)
+	api.PUT("/contacts/:id", handler.UpdateContact)
+
+	// storedColumns reads the raw column values, bypassing the model serializer
+	storedColumns := func(id uint) (string, string) {
+		var row struct {
+			Phone string
+			Email string
+		}
+		require.NoError(t, testDB.DB.Raw("SELECT phone, email FROM contacts WHERE id = ?", id).Scan(&row).Error)
+		return row.Phone, row.Email
+	}
+
+	// contactFrom decodes the contact returned in a response envelope
+	contactFrom := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.(map[string]interface{})
+	}
+
+	body, _ := json.Marshal(models.CreateContactRequest{
+		FullName: "Secret Contact",
+		Phone:    "5551234567",
+		Email:    stringPtr("secret@example.com"),
+	})
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/contacts", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	created := contactFrom(w)
+	id := uint(created["id"].(float64))
+	assert.Equal(t, "5551234567", created["phone"])
+
+	t.Run("stored values are ciphertext", func(t *testing.T) {
+		phone, email := storedColumns(id)
+
+		assert.True(t, fieldcrypt.IsEncrypted(phone))
+		assert.True(t, fieldcrypt.IsEncrypted(email))
+		assert.NotContains(t, phone, "5551234567")
+		assert.NotContains(t, email, "secret@example.com")
+	})
+
+	t.Run("API returns plaintext", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/contacts/%d", id), nil)
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		contact := contactFrom(w)
+		assert.Equal(t, "5551234567", contact["phone"])
+		assert.Equal(t, "secret@example.com", contact["email"])
+	})
+
+	t.Run("updates are encrypted too", func(t *testing.T) {
+		body, _ := json.Marshal(models.UpdateContactRequest{
+			FullName: "Secret Contact",
+			Phone:    "5559876543",
+			Email:    models.NewOptionalString("updated@example.com"),
+		})
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/contacts/%d", id), bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		contact := contactFrom(w)
+		assert.Equal(t, "5559876543", contact["phone"])
+		assert.Equal(t, "updated@example.com", contact["email"])
+
+		phone, email := storedColumns(id)
+		assert.True(t, fieldcrypt.IsEncrypted(phone))
+		assert.True(t, fieldcrypt.IsEncrypted(email))
+	})
+}
+
+func TestFieldEncryption_PhoneLookups(t *testing.T) {
+	testDB, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	// Written before encryption was enabled, so it has no blind index yet
+	legacy, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Legacy", Phone: "5550000001"})
+	require.NoError(t, err)
+	assert.Nil(t, legacy.PhoneHash)
+
+	enableTestEncryption(t)
+
+	contact, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Encrypted", Phone: "5550000002"})
+	require.NoError(t, err)
+
+	t.Run("CheckContactExists matches encrypted phones", func(t *testing.T) {
+		exists, err := repo.CheckContactExists(ctx, user.ID, "5550000002", 0)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.CheckContactExists(ctx, user.ID, "5559999999", 0)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		exists, err = repo.CheckContactExists(ctx, user.ID+1, "5550000002", 0)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("GetContactByPhone returns the decrypted contact", func(t *testing.T) {
+		found, err := repo.GetContactByPhone(ctx, user.ID, "5550000002")
+		require.NoError(t, err)
+		assert.Equal(t, contact.ID, found.ID)
+		assert.Equal(t, "5550000002", found.Phone)
+	})
+
+	t.Run("updating the phone moves the blind index", func(t *testing.T) {
+		_, err := repo.UpdateContact(ctx, user.ID, contact.ID, map[string]interface{}{"phone": "5550000003"})
+		require.NoError(t, err)
+
+		exists, err := repo.CheckContactExists(ctx, user.ID, "5550000003", 0)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.CheckContactExists(ctx, user.ID, "5550000002", 0)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("duplicates are grouped by the blind index", func(t *testing.T) {
+		// Duplicates survive only in databases from before the unique phone key
+		_, err := testDB.SqlDB.Exec("DROP INDEX idx_contacts_user_phone_key")
+		require.NoError(t, err)
+
+		first, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "First", Phone: "5550000004"})
+		require.NoError(t, err)
+		second, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Second", Phone: "5550000004"})
+		require.NoError(t, err)
+
+		groups, total, err := repo.ListDuplicateGroups(ctx, user.ID, "phone", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "5550000004", groups[0].Key)
+		assert.Equal(t, 2, groups[0].Count)
+		require.Len(t, groups[0].Contacts, 2)
+		assert.Equal(t, first.ID, groups[0].Contacts[0].ID)
+		assert.Equal(t, second.ID, groups[0].Contacts[1].ID)
+		assert.Equal(t, "5550000004", groups[0].Contacts[1].Phone)
+	})
+
+	t.Run("backfill indexes contacts written without encryption", func(t *testing.T) {
+		exists, err := repo.CheckContactExists(ctx, user.ID, "5550000001", 0)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		backfilled, err := repo.BackfillPhoneHashes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), backfilled)
+
+		exists, err = repo.CheckContactExists(ctx, user.ID, "5550000001", 0)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		var stored string
+		require.NoError(t, testDB.DB.Raw("SELECT phone_hash FROM contacts WHERE id = ?", legacy.ID).Scan(&stored).Error)
+		assert.Len(t, stored, 64)
+	})
+}
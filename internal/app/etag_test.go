@@ -0,0 +1,236 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListContacts_ETag(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	contact, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Alice", Phone: "1111111111"})
+	require.NoError(t, err)
+	_, err = repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Bob", Phone: "2222222222"})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	api.GET("/contacts", handler.ListContacts)
+	api.PUT("/contacts/:id", handler.UpdateContact)
+
+	list := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := list("/api/v1/contacts", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("unchanged list is not modified", func(t *testing.T) {
+		w := list("/api/v1/contacts", etag)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("another page has its own tag", func(t *testing.T) {
+		w := list("/api/v1/contacts?page=2&limit=1", etag)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("an updated contact changes the tag", func(t *testing.T) {
+		body, _ := json.Marshal(models.UpdateContactRequest{FullName: "Alice Smith", Phone: contact.Phone})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/contacts/%d", contact.ID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = list("/api/v1/contacts", etag)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "Alice Smith")
+	})
+
+	t.Run("every write changes the tag within one second", func(t *testing.T) {
+		// MySQL stores updated_at to the second; pin it so every write lands in the same second
+		second := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		pin := func() {
+			require.NoError(t, tdb.DB.Model(&models.Contact{}).Where("user_id = ?", user.ID).UpdateColumn("updated_at", second).Error)
+		}
+		current := func() string {
+			t.Helper()
+			w := list("/api/v1/contacts", "")
+			require.Equal(t, http.StatusOK, w.Code)
+			return w.Header().Get("ETag")
+		}
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+		pin()
+		seen := map[string]bool{current(): true}
+		for _, step := range []struct {
+			name  string
+			write func() error
+		}{
+			{"edit", func() error {
+				_, err := svc.PatchContact(ctx, user.ID, contact.ID, map[string]interface{}{"full_name": "Alice Jones"})
+				return err
+			}},
+			{"tag", func() error {
+				_, err := repo.TagContacts(ctx, user.ID, models.ContactFilter{}, "friends")
+				return err
+			}},
+			{"delete", func() error { return repo.DeleteContact(ctx, user.ID, contact.ID) }},
+			{"restore", func() error { return repo.RestoreContact(ctx, user.ID, contact.ID) }},
+		} {
+			require.NoError(t, step.write(), step.name)
+			pin()
+			etag := current()
+			assert.False(t, seen[etag], step.name)
+			seen[etag] = true
+		}
+	})
+}
+
+func TestDeleteContact_IfMatch(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	api.GET("/contacts/:id", handler.GetContact)
+	api.PUT("/contacts/:id", handler.UpdateContact)
+	api.DELETE("/contacts/:id", handler.DeleteContact)
+
+	do := func(method string, id uint, ifMatch string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, fmt.Sprintf("/api/v1/contacts/%d", id), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	newContact := func(t *testing.T, phone string) (*models.Contact, string) {
+		contact, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Alice", Phone: phone})
+		require.NoError(t, err)
+		w := do("GET", contact.ID, "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		return contact, etag
+	}
+
+	t.Run("matching precondition deletes", func(t *testing.T) {
+		contact, etag := newContact(t, "1111111111")
+
+		w := do("DELETE", contact.ID, etag, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusNotFound, do("GET", contact.ID, "", nil).Code)
+	})
+
+	t.Run("contact changed since it was loaded", func(t *testing.T) {
+		contact, etag := newContact(t, "2222222222")
+		body, _ := json.Marshal(models.UpdateContactRequest{FullName: "Alice Edited", Phone: contact.Phone})
+		updated := do("PUT", contact.ID, "", body)
+		require.Equal(t, http.StatusOK, updated.Code)
+		assert.NotEqual(t, etag, updated.Header().Get("ETag"))
+
+		w := do("DELETE", contact.ID, etag, nil)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, http.StatusOK, do("GET", contact.ID, "", nil).Code)
+
+		// Retrying with the current tag succeeds
+		assert.Equal(t, http.StatusOK, do("DELETE", contact.ID, updated.Header().Get("ETag"), nil).Code)
+	})
+
+	t.Run("writes within one second of a stored timestamp", func(t *testing.T) {
+		contact, etag := newContact(t, "6666666666")
+		// MySQL stores updated_at to the second; pin it so every write lands in the same second
+		second := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		pin := func() {
+			require.NoError(t, tdb.DB.Model(&models.Contact{}).Where("id = ?", contact.ID).UpdateColumn("updated_at", second).Error)
+		}
+		pin()
+
+		body, _ := json.Marshal(models.UpdateContactRequest{FullName: "Alice Again", Phone: contact.Phone})
+		updated := do("PUT", contact.ID, "", body)
+		require.Equal(t, http.StatusOK, updated.Code)
+		pin()
+
+		// The tag sent with the update is the one of the stored row
+		loaded := do("GET", contact.ID, "", nil)
+		assert.Equal(t, updated.Header().Get("ETag"), loaded.Header().Get("ETag"))
+		assert.NotEqual(t, etag, loaded.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusPreconditionFailed, do("DELETE", contact.ID, etag, nil).Code)
+		assert.Equal(t, http.StatusOK, do("DELETE", contact.ID, updated.Header().Get("ETag"), nil).Code)
+	})
+
+	t.Run("weak tags never match", func(t *testing.T) {
+		contact, etag := newContact(t, "3333333333")
+
+		w := do("DELETE", contact.ID, "W/"+etag, nil)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("wildcard matches any existing contact", func(t *testing.T) {
+		contact, _ := newContact(t, "4444444444")
+
+		assert.Equal(t, http.StatusOK, do("DELETE", contact.ID, "*", nil).Code)
+		assert.Equal(t, http.StatusNotFound, do("DELETE", contact.ID, "*", nil).Code)
+	})
+
+	t.Run("without If-Match deletes unconditionally", func(t *testing.T) {
+		contact, _ := newContact(t, "5555555555")
+
+		assert.Equal(t, http.StatusOK, do("DELETE", contact.ID, "", nil).Code)
+	})
+}
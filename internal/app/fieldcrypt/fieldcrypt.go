@@ -0,0 +1,134 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+// prefix marks values written by this package so plaintext rows stay readable
+const prefix = "enc:v1:"
+
+var (
+	ErrInvalidKey    = errors.New("field encryption key must be base64 encoding of 16, 24 or 32 bytes")
+	ErrMalformed     = errors.New("malformed encrypted value")
+	ErrDecrypt       = errors.New("failed to decrypt value")
+	ErrNotConfigured = errors.New("encrypted value found but field encryption is not configured")
+)
+
+// activeCipher is the cipher used by the package-level helpers; nil disables encryption
+var activeCipher atomic.Pointer[Cipher]
+
+// Cipher encrypts and decrypts individual field values with AES-GCM
+type Cipher struct {
+	aead     cipher.AEAD
+	indexKey []byte
+}
+
+// NewCipher creates a cipher from a base64 encoded AES key
+func NewCipher(key string) (*Cipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The blind index key is derived so a leaked index never exposes the encryption key
+	mac := hmac.New(sha256.New, raw)
+	mac.Write([]byte("contact-blind-index"))
+
+	return &Cipher{aead: aead, indexKey: mac.Sum(nil)}, nil
+}
+
+// BlindIndex returns a deterministic HMAC-SHA256 of value, usable for exact-match
+// lookups over encrypted columns
+func (c *Cipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encrypt returns the prefixed, base64 encoded ciphertext of plaintext
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Values without the encryption prefix are returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Enable makes c the cipher used for encrypted model fields. Passing nil disables encryption.
+func Enable(c *Cipher) {
+	activeCipher.Store(c)
+}
+
+// Enabled reports whether field encryption is active
+func Enabled() bool {
+	return activeCipher.Load() != nil
+}
+
+// Encrypt encrypts plaintext with the active cipher, or returns it unchanged when disabled
+func Encrypt(plaintext string) (string, error) {
+	c := activeCipher.Load()
+	if c == nil {
+		return plaintext, nil
+	}
+	return c.Encrypt(plaintext)
+}
+
+// BlindIndex returns the blind index of value with the active cipher, or "" when disabled
+func BlindIndex(value string) string {
+	c := activeCipher.Load()
+	if c == nil {
+		return ""
+	}
+	return c.BlindIndex(value)
+}
+
+// Decrypt decrypts value with the active cipher. Plaintext values are returned unchanged.
+func Decrypt(value string) (string, error) {
+	c := activeCipher.Load()
+	if c == nil {
+		if IsEncrypted(value) {
+			return "", ErrNotConfigured
+		}
+		return value, nil
+	}
+	return c.Decrypt(value)
+}
@@ -0,0 +1,94 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey() string {
+	return base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+}
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher(testKey())
+	require.NoError(t, err)
+
+	t.Run("round trips values", func(t *testing.T) {
+		encrypted, err := c.Encrypt("5551234567")
+		require.NoError(t, err)
+
+		assert.True(t, IsEncrypted(encrypted))
+		assert.NotContains(t, encrypted, "5551234567")
+
+		decrypted, err := c.Decrypt(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "5551234567", decrypted)
+	})
+
+	t.Run("uses a fresh nonce per value", func(t *testing.T) {
+		first, err := c.Encrypt("same")
+		require.NoError(t, err)
+		second, err := c.Encrypt("same")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("passes plaintext through", func(t *testing.T) {
+		decrypted, err := c.Decrypt("legacy@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "legacy@example.com", decrypted)
+	})
+
+	t.Run("rejects a value encrypted with another key", func(t *testing.T) {
+		other, err := NewCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+		require.NoError(t, err)
+		encrypted, err := other.Encrypt("secret")
+		require.NoError(t, err)
+
+		_, err = c.Decrypt(encrypted)
+		assert.Equal(t, ErrDecrypt, err)
+	})
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	_, err := NewCipher("not base64!")
+	assert.Equal(t, ErrInvalidKey, err)
+
+	_, err = NewCipher(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestActiveCipher(t *testing.T) {
+	c, err := NewCipher(testKey())
+	require.NoError(t, err)
+
+	Enable(c)
+	encrypted, err := Encrypt("value")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+
+	Enable(nil)
+	plain, err := Encrypt("value")
+	require.NoError(t, err)
+	assert.Equal(t, "value", plain)
+
+	_, err = Decrypt(encrypted)
+	assert.Equal(t, ErrNotConfigured, err)
+}
+
+func TestBlindIndex(t *testing.T) {
+	c, err := NewCipher(testKey())
+	require.NoError(t, err)
+
+	assert.Equal(t, c.BlindIndex("5551234567"), c.BlindIndex("5551234567"))
+	assert.NotEqual(t, c.BlindIndex("5551234567"), c.BlindIndex("5551234568"))
+	assert.Len(t, c.BlindIndex("5551234567"), 64)
+
+	Enable(nil)
+	assert.Empty(t, BlindIndex("5551234567"))
+}
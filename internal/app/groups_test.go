@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"user-service/configs"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/routes"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ContactGroups(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	other, err := repo.CreateUser(ctx, &models.User{FullName: "Other User", Email: "other@example.com", Password: "hashedpassword"})
+	require.NoError(t, err)
+
+	newContact := func(userID uint, name, phone string) *models.Contact {
+		contact, err := repo.CreateContact(ctx, &models.Contact{UserID: userID, FullName: name, Phone: phone})
+		require.NoError(t, err)
+		return contact
+	}
+	alice := newContact(user.ID, "Alice", "+14155550001")
+	bob := newContact(user.ID, "Bob", "+14155550002")
+	carol := newContact(user.ID, "Carol", "+14155550003")
+	stranger := newContact(other.ID, "Stranger", "+14155550004")
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+	family, err := svc.CreateGroup(ctx, user.ID, models.ContactGroupRequest{Name: "  Family  "})
+	require.NoError(t, err)
+	assert.Equal(t, "Family", family.Name)
+	work, err := svc.CreateGroup(ctx, user.ID, models.ContactGroupRequest{Name: "Work"})
+	require.NoError(t, err)
+
+	// A contact can be in several groups, and adding it again is harmless
+	require.NoError(t, svc.AddContactToGroup(ctx, user.ID, family.ID, alice.ID))
+	require.NoError(t, svc.AddContactToGroup(ctx, user.ID, family.ID, alice.ID))
+	require.NoError(t, svc.AddContactToGroup(ctx, user.ID, family.ID, bob.ID))
+	require.NoError(t, svc.AddContactToGroup(ctx, user.ID, work.ID, alice.ID))
+	require.NoError(t, svc.AddContactToGroup(ctx, user.ID, work.ID, carol.ID))
+
+	listNames := func(groupID uint) []string {
+		t.Helper()
+		contacts, total, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{GroupID: groupID, Sort: "full_name", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		names := []string{}
+		for _, contact := range contacts {
+			names = append(names, contact.FullName)
+		}
+		assert.Equal(t, int64(len(names)), total)
+		return names
+	}
+
+	t.Run("filter by group", func(t *testing.T) {
+		assert.Equal(t, []string{"Alice", "Bob"}, listNames(family.ID))
+		assert.Equal(t, []string{"Alice", "Carol"}, listNames(work.ID))
+		assert.Equal(t, []string{"Alice", "Bob", "Carol"}, listNames(0))
+	})
+
+	t.Run("groups list their contact counts", func(t *testing.T) {
+		groups, err := svc.ListGroups(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Family", groups[0].Name)
+		assert.Equal(t, int64(2), groups[0].ContactCount)
+		assert.Equal(t, int64(2), groups[1].ContactCount)
+
+		groups, err = svc.ListGroups(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("names are unique per user", func(t *testing.T) {
+		_, err := svc.CreateGroup(ctx, user.ID, models.ContactGroupRequest{Name: "Family"})
+		assert.ErrorIs(t, err, service.ErrGroupNameTaken)
+		_, err = svc.RenameGroup(ctx, user.ID, work.ID, models.ContactGroupRequest{Name: "Family"})
+		assert.ErrorIs(t, err, service.ErrGroupNameTaken)
+
+		_, err = svc.CreateGroup(ctx, other.ID, models.ContactGroupRequest{Name: "Family"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("other users' groups and contacts are off limits", func(t *testing.T) {
+		assert.ErrorIs(t, svc.AddContactToGroup(ctx, other.ID, family.ID, stranger.ID), service.ErrGroupNotFound)
+		assert.ErrorIs(t, svc.AddContactToGroup(ctx, user.ID, family.ID, stranger.ID), service.ErrContactNotFound)
+		_, err := svc.GetGroup(ctx, other.ID, family.ID)
+		assert.ErrorIs(t, err, service.ErrGroupNotFound)
+		assert.ErrorIs(t, svc.DeleteGroup(ctx, other.ID, family.ID), service.ErrGroupNotFound)
+	})
+
+	t.Run("remove a contact", func(t *testing.T) {
+		require.NoError(t, svc.RemoveContactFromGroup(ctx, user.ID, work.ID, carol.ID))
+		require.NoError(t, svc.RemoveContactFromGroup(ctx, user.ID, work.ID, carol.ID))
+		assert.Equal(t, []string{"Alice"}, listNames(work.ID))
+	})
+
+	t.Run("deleting a group drops its memberships and keeps the contacts", func(t *testing.T) {
+		require.NoError(t, svc.DeleteGroup(ctx, user.ID, family.ID))
+
+		_, err := svc.GetGroup(ctx, user.ID, family.ID)
+		assert.ErrorIs(t, err, service.ErrGroupNotFound)
+		assert.Empty(t, listNames(family.ID))
+		assert.Equal(t, []string{"Alice", "Bob", "Carol"}, listNames(0))
+
+		var members int64
+		require.NoError(t, tdb.DB.Model(&models.ContactGroupMember{}).Where("group_id = ?", family.ID).Count(&members).Error)
+		assert.Zero(t, members)
+		// Other groups keep their members
+		assert.Equal(t, []string{"Alice"}, listNames(work.ID))
+	})
+}
+
+func TestHandler_ContactGroups(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	contact, err := CreateTestContact(ctx, repo, user.ID)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	setUser := func(c *gin.Context) { c.Set("user_id", user.ID) }
+	routes.RegisterVersion(router, "/api", "v1", nil, []gin.HandlerFunc{setUser}, routes.Versions(h, configs.Config{})["v1"])
+
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, response := do(http.MethodPost, "/api/v1/groups", `{"name":"Family"}`)
+	require.Equal(t, http.StatusCreated, code)
+	groupPath := "/api/v1/groups/" + strconv.Itoa(int(response["data"].(map[string]interface{})["id"].(float64)))
+	contactPath := groupPath + "/contacts/" + strconv.Itoa(int(contact.ID))
+
+	code, response = do(http.MethodPost, "/api/v1/groups", `{"name":"Family"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "name", response["data"].(map[string]interface{})["field"])
+
+	code, _ = do(http.MethodPut, contactPath, "")
+	require.Equal(t, http.StatusOK, code)
+
+	code, response = do(http.MethodGet, groupPath, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["contact_count"])
+
+	groupID := strings.TrimPrefix(groupPath, "/api/v1/groups/")
+	code, response = do(http.MethodGet, "/api/v1/contacts?group_id="+groupID, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["count"])
+
+	code, _ = do(http.MethodPut, groupPath+"/contacts/9999", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, response = do(http.MethodPut, groupPath, `{"name":"Relatives"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Relatives", response["data"].(map[string]interface{})["name"])
+
+	code, _ = do(http.MethodDelete, groupPath, "")
+	require.Equal(t, http.StatusOK, code)
+	code, _ = do(http.MethodGet, groupPath, "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, response = do(http.MethodGet, "/api/v1/contacts?group_id="+groupID, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(0), response["data"].(map[string]interface{})["count"])
+	code, _ = do(http.MethodGet, "/api/v1/contacts/"+strconv.Itoa(int(contact.ID)), "")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"user-service/internal/app/handlers"
 	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/middleware"
+	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -21,12 +26,12 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) Register(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+func (m *MockService) Register(ctx context.Context, req models.RegisterRequest) (*models.User, string, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, "", args.Error(2)
 	}
-	return args.Get(0).(*models.User), args.Error(1)
+	return args.Get(0).(*models.User), args.String(1), args.Error(2)
 }
 
 func (m *MockService) Login(ctx context.Context, req models.LoginRequest) (map[string]interface{}, error) {
@@ -45,6 +50,30 @@ func (m *MockService) GetUserProfile(ctx context.Context, userID uint) (*models.
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockService) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockService) GetUserProfileStats(ctx context.Context, userID uint) (*models.UserProfile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserProfile), args.Error(1)
+}
+
+func (m *MockService) PatchProfile(ctx context.Context, userID uint, req models.PatchProfileRequest) (*models.User, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockService) UpdateProfile(ctx context.Context, userID uint, req models.UpdateProfileRequest) (*models.User, error) {
 	args := m.Called(ctx, userID, req)
 	if args.Get(0) == nil {
@@ -53,6 +82,68 @@ func (m *MockService) UpdateProfile(ctx context.Context, userID uint, req models
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockService) LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error) {
+	args := m.Called(ctx, phones)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DirectoryEntry), args.Error(1)
+}
+
+func (m *MockService) VerifyPassword(ctx context.Context, userID uint, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func (m *MockService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockService) UpdateAvatar(ctx context.Context, userID uint, url string) (*models.User, error) {
+	args := m.Called(ctx, userID, url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockService) Logout(ctx context.Context, userID uint, jti string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockService) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) CreateInviteCode(ctx context.Context, adminID uint, req models.CreateInviteCodeRequest) (*models.InviteCode, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InviteCode), args.Error(1)
+}
+
+func (m *MockService) ListInviteCodes(ctx context.Context) ([]models.InviteCode, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InviteCode), args.Error(1)
+}
+
 func (m *MockService) ListContacts(ctx context.Context, userID uint, req *models.ListContactsRequest) ([]models.Contact, int64, error) {
 	args := m.Called(ctx, userID, req)
 	return args.Get(0).([]models.Contact), args.Get(1).(int64), args.Error(2)
@@ -66,6 +157,22 @@ func (m *MockService) CreateContact(ctx context.Context, userID uint, req *model
 	return args.Get(0).(*models.Contact), args.Error(1)
 }
 
+func (m *MockService) ValidateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contact), args.Error(1)
+}
+
+func (m *MockService) ListDuplicates(ctx context.Context, userID uint, req *models.ListDuplicatesRequest) ([]models.DuplicateGroup, int64, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.DuplicateGroup), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockService) GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error) {
 	args := m.Called(ctx, userID, contactID)
 	if args.Get(0) == nil {
@@ -87,18 +194,145 @@ func (m *MockService) DeleteContact(ctx context.Context, userID, contactID uint)
 	return args.Error(0)
 }
 
+func (m *MockService) CreateContactsBatch(ctx context.Context, userID uint, reqs []models.CreateContactRequest) ([]models.BatchContactResult, error) {
+	args := m.Called(ctx, userID, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BatchContactResult), args.Error(1)
+}
+
+func (m *MockService) RestoreContact(ctx context.Context, userID, contactID uint) error {
+	args := m.Called(ctx, userID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockService) DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error {
+	args := m.Called(ctx, userID, contactID, version)
+	return args.Error(0)
+}
+
+func (m *MockService) PatchContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error) {
+	args := m.Called(ctx, userID, contactID, updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contact), args.Error(1)
+}
+
+func (m *MockService) ContactPhoneExists(ctx context.Context, userID uint, phone string) (bool, error) {
+	args := m.Called(ctx, userID, phone)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) MergeContacts(ctx context.Context, userID, primaryID uint, duplicateIDs []uint) (*models.Contact, error) {
+	args := m.Called(ctx, userID, primaryID, duplicateIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contact), args.Error(1)
+}
+
+func (m *MockService) StreamContacts(ctx context.Context, userID uint, req *models.ListContactsRequest, fn func(*models.Contact) error) error {
+	args := m.Called(ctx, userID, req, fn)
+	return args.Error(0)
+}
+
+func (m *MockService) ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.ContactsVersion), args.Error(1)
+}
+
+func (m *MockService) TagContactsByQuery(ctx context.Context, userID uint, req *models.TagByQueryRequest) (int64, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockService) AdminListContacts(ctx context.Context, req *models.AdminListContactsRequest) ([]models.AdminContact, int64, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.AdminContact), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockService) StartContactImport(ctx context.Context, userID uint, data []byte, dedup string) (*models.ImportJob, error) {
+	args := m.Called(ctx, userID, data, dedup)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *MockService) GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error) {
+	args := m.Called(ctx, userID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *MockService) CreateGroup(ctx context.Context, userID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockService) ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ContactGroup), args.Error(1)
+}
+
+func (m *MockService) GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error) {
+	args := m.Called(ctx, userID, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockService) RenameGroup(ctx context.Context, userID, groupID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	args := m.Called(ctx, userID, groupID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockService) DeleteGroup(ctx context.Context, userID, groupID uint) error {
+	args := m.Called(ctx, userID, groupID)
+	return args.Error(0)
+}
+
+func (m *MockService) AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	args := m.Called(ctx, userID, groupID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockService) RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	args := m.Called(ctx, userID, groupID, contactID)
+	return args.Error(0)
+}
+
 func setupTestRouter(mockService *MockService) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
 	// Create handler with mock service
-	handler := handlers.NewHandler(mockService, "test_secret")
+	handler := handlers.NewHandler(mockService)
 
 	// Setup routes
 	api := router.Group("/api/v1")
 	{
 		api.POST("/auth/register", handler.Register)
 		api.POST("/auth/login", handler.Login)
+		api.POST("/auth/forgot-password", handler.ForgotPassword)
+		api.POST("/auth/reset-password", handler.ResetPassword)
 
 		protected := api.Group("")
 		protected.Use(func(c *gin.Context) {
@@ -109,12 +343,35 @@ func setupTestRouter(mockService *MockService) *gin.Engine {
 		{
 			protected.GET("/me", handler.GetProfile)
 			protected.PUT("/me", handler.UpdateProfile)
+			protected.PATCH("/me", handler.PatchProfile)
+			protected.POST("/me/verify-password",
+				middleware.RateLimit(middleware.NewRateLimiter(3, time.Minute), middleware.UserKey),
+				handler.VerifyPassword,
+			)
+			protected.PUT("/me/password", handler.ChangePassword)
+			protected.POST("/lookup/batch",
+				middleware.RateLimit(middleware.NewRateLimiter(2, time.Minute), middleware.UserKey),
+				handler.LookupDirectory,
+			)
 
 			protected.GET("/contacts", handler.ListContacts)
 			protected.POST("/contacts", handler.CreateContact)
+			protected.POST("/contacts/validate", handler.ValidateContact)
+			protected.POST("/contacts/batch", handler.CreateContactsBatch)
+			protected.POST("/contacts/tag-by-query", handler.TagContactsByQuery)
+			protected.POST("/contacts/merge", handler.MergeContacts)
+			protected.GET("/contacts/duplicates", handler.ListDuplicates)
+			protected.GET("/contacts/meta", handler.ContactFieldsMeta)
+			protected.GET("/contacts/check", handler.CheckContactPhone)
+			protected.POST("/contacts/import", handler.ImportContacts)
+			protected.GET("/contacts/import/:jobId", handler.GetImportJob)
 			protected.GET("/contacts/:id", handler.GetContact)
+			protected.GET("/contacts/:id/vcard", handler.ExportContactVCard)
+			protected.GET("/contacts/:id/qr", handler.GetContactQRCode)
 			protected.PUT("/contacts/:id", handler.UpdateContact)
+			protected.PATCH("/contacts/:id", handler.PatchContact)
 			protected.DELETE("/contacts/:id", handler.DeleteContact)
+			protected.POST("/contacts/:id/restore", handler.RestoreContact)
 		}
 	}
 
@@ -129,7 +386,7 @@ func TestHandler_Register(t *testing.T) {
 		req := models.RegisterRequest{
 			FullName: "John Doe",
 			Email:    "john@example.com",
-			Phone:    "+1234567890",
+			Phone:    stringPtr("1234567890"),
 			Password: "password123",
 		}
 
@@ -140,7 +397,7 @@ func TestHandler_Register(t *testing.T) {
 			Phone:    req.Phone,
 		}
 
-		mockService.On("Register", mock.Anything, req).Return(expectedUser, nil).Once()
+		mockService.On("Register", mock.Anything, req).Return(expectedUser, "jwt_token_here", nil).Once()
 
 		body, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -160,6 +417,10 @@ func TestHandler_Register(t *testing.T) {
 		assert.Contains(t, response.Data, "id")
 		assert.Contains(t, response.Data, "token")
 
+		data := response.Data.(map[string]interface{})
+		tokenData := data["token"].(map[string]interface{})
+		assert.Equal(t, "jwt_token_here", tokenData["access_token"])
+
 		mockService.AssertExpectations(t)
 	})
 
@@ -184,11 +445,11 @@ func TestHandler_Register(t *testing.T) {
 		req := models.RegisterRequest{
 			FullName: "Jane Doe",
 			Email:    "jane@example.com",
-			Phone:    "+0987654321",
+			Phone:    stringPtr("0987654321"),
 			Password: "password123",
 		}
 
-		mockService.On("Register", mock.Anything, req).Return(nil, assert.AnError).Once()
+		mockService.On("Register", mock.Anything, req).Return(nil, "", assert.AnError).Once()
 
 		body, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -206,6 +467,33 @@ func TestHandler_Register(t *testing.T) {
 		assert.Equal(t, 0, response.Status)
 		assert.Equal(t, "Registration failed", response.Message)
 	})
+
+	t.Run("registration disabled", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Closed Door",
+			Email:    "closed@example.com",
+			Password: "password123",
+		}
+
+		mockService.On("Register", mock.Anything, req).Return(nil, "", service.ErrRegistrationClosed).Once()
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var response models.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, response.Status)
+		assert.Equal(t, "Registration disabled", response.Message)
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestHandler_Login(t *testing.T) {
@@ -291,6 +579,32 @@ func TestHandler_Login(t *testing.T) {
 		assert.Equal(t, 0, response.Status)
 		assert.Equal(t, "Invalid email or password", response.Message)
 	})
+
+	t.Run("account locked", func(t *testing.T) {
+		req := models.LoginRequest{
+			Email:    "john@example.com",
+			Password: "password123",
+		}
+
+		mockService.On("Login", mock.Anything, req).
+			Return(nil, &service.AccountLockedError{Until: time.Now().Add(90 * time.Second)}).Once()
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "90", w.Header().Get("Retry-After"))
+
+		var response models.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, middleware.RateLimitedCode, response.Data.(map[string]interface{})["code"])
+	})
 }
 
 func TestHandler_GetProfile(t *testing.T) {
@@ -303,7 +617,7 @@ func TestHandler_GetProfile(t *testing.T) {
 			ID:       userID,
 			FullName: "John Doe",
 			Email:    "john@example.com",
-			Phone:    "+1234567890",
+			Phone:    stringPtr("1234567890"),
 		}
 
 		mockService.On("GetUserProfile", mock.Anything, userID).Return(expectedUser, nil).Once()
@@ -326,8 +640,55 @@ func TestHandler_GetProfile(t *testing.T) {
 		assert.Equal(t, float64(expectedUser.ID), data["id"])
 		assert.Equal(t, expectedUser.FullName, data["full_name"])
 		assert.Equal(t, expectedUser.Email, data["email"])
-		assert.Equal(t, expectedUser.Phone, data["phone"])
+		assert.Equal(t, *expectedUser.Phone, data["phone"])
+		assert.NotContains(t, data, "member_since")
+		assert.NotContains(t, data, "total_contacts")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("stats are included when requested", func(t *testing.T) {
+		userID := uint(1)
+		memberSince := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+		profile := &models.UserProfile{
+			User:          models.User{ID: userID, FullName: "John Doe", Email: "john@example.com", CreatedAt: memberSince},
+			MemberSince:   memberSince,
+			TotalContacts: 42,
+		}
+
+		mockService.On("GetUserProfileStats", mock.Anything, userID).Return(profile, nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/me?include=stats", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, float64(userID), data["id"])
+		assert.Equal(t, "john@example.com", data["email"])
+		assert.Equal(t, "2025-03-01T09:30:00Z", data["member_since"])
+		assert.Equal(t, float64(42), data["total_contacts"])
+		assert.NotContains(t, data, "password")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("stats of a missing user", func(t *testing.T) {
+		userID := uint(1)
+
+		mockService.On("GetUserProfileStats", mock.Anything, userID).Return(nil, assert.AnError).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/me?include=stats", nil)
 
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
 		mockService.AssertExpectations(t)
 	})
 
@@ -360,7 +721,7 @@ func TestHandler_UpdateProfile(t *testing.T) {
 		userID := uint(1)
 		req := models.UpdateProfileRequest{
 			FullName: "Updated Name",
-			Phone:    "+0987654321",
+			Phone:    stringPtr("0987654321"),
 		}
 
 		expectedUser := &models.User{
@@ -392,7 +753,7 @@ func TestHandler_UpdateProfile(t *testing.T) {
 		assert.Equal(t, float64(expectedUser.ID), data["id"])
 		assert.Equal(t, expectedUser.FullName, data["full_name"])
 		assert.Equal(t, expectedUser.Email, data["email"])
-		assert.Equal(t, expectedUser.Phone, data["phone"])
+		assert.Equal(t, *expectedUser.Phone, data["phone"])
 
 		mockService.AssertExpectations(t)
 	})
@@ -415,35 +776,144 @@ func TestHandler_UpdateProfile(t *testing.T) {
 	})
 }
 
-func TestHandler_ListContacts(t *testing.T) {
-	mockService := new(MockService)
-	router := setupTestRouter(mockService)
-
-	t.Run("successful contact listing", func(t *testing.T) {
-		userID := uint(1)
-		expectedContacts := []models.Contact{
-			{ID: 1, FullName: "Alice", Phone: "+1111111111"},
-			{ID: 2, FullName: "Bob", Phone: "+2222222222"},
-		}
-		expectedTotal := int64(2)
-
-		req := &models.ListContactsRequest{
-			Page:  1,
-			Limit: 10,
-		}
+func TestHandler_VerifyPassword(t *testing.T) {
+	verify := func(router *gin.Engine, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.VerifyPasswordRequest{Password: password})
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/me/verify-password", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
 
-		mockService.On("ListContacts", mock.Anything, userID, req).Return(expectedContacts, expectedTotal, nil).Once()
+	t.Run("correct password", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
 
-		w := httptest.NewRecorder()
-		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?page=1&limit=10", nil)
+		mockService.On("VerifyPassword", mock.Anything, uint(1), "password123").Return(nil).Once()
 
-		router.ServeHTTP(w, httpReq)
+		w := verify(router, "password123")
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
 		var response models.Response
-		err := json.Unmarshal(w.Body.Bytes(), &response)
-		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Password verified", response.Message)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("incorrect password", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		mockService.On("VerifyPassword", mock.Anything, uint(1), "wrong").Return(service.ErrIncorrectPassword).Once()
+
+		w := verify(router, "wrong")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid password", response.Message)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rate limited after repeated attempts", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		mockService.On("VerifyPassword", mock.Anything, uint(1), "wrong").Return(service.ErrIncorrectPassword).Times(3)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, verify(router, "wrong").Code)
+		}
+
+		w := verify(router, "wrong")
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandler_ChangePassword(t *testing.T) {
+	change := func(router *gin.Engine, req models.ChangePasswordRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", "/api/v1/me/password", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("password changed", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		mockService.On("ChangePassword", mock.Anything, uint(1), "password123", "newpassword456").Return(nil).Once()
+
+		w := change(router, models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Password changed successfully", response.Message)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("incorrect current password", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		mockService.On("ChangePassword", mock.Anything, uint(1), "wrong", "newpassword456").Return(service.ErrIncorrectPassword).Once()
+
+		w := change(router, models.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "newpassword456"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("new password too short", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		w := change(router, models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "short"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_ListContacts(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+	mockService.On("ContactsVersion", mock.Anything, uint(1)).Return(models.ContactsVersion{}, nil)
+
+	t.Run("successful contact listing", func(t *testing.T) {
+		userID := uint(1)
+		expectedContacts := []models.Contact{
+			{ID: 1, FullName: "Alice", Phone: "+1111111111"},
+			{ID: 2, FullName: "Bob", Phone: "+2222222222"},
+		}
+		expectedTotal := int64(2)
+
+		req := &models.ListContactsRequest{
+			Page:  1,
+			Limit: 10,
+		}
+
+		mockService.On("ListContacts", mock.Anything, userID, req).Return(expectedContacts, expectedTotal, nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?page=1&limit=10", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
 
 		assert.Equal(t, 1, response.Status)
 		assert.Equal(t, "Contacts loaded successfully", response.Message)
@@ -452,7 +922,100 @@ func TestHandler_ListContacts(t *testing.T) {
 		assert.Equal(t, expectedTotal, int64(data["count"].(float64)))
 		assert.Equal(t, float64(1), data["page"])
 		assert.Equal(t, float64(10), data["limit"])
+		assert.Equal(t, true, data["count_exact"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("capped count is flagged as inexact", func(t *testing.T) {
+		req := &models.ListContactsRequest{Page: 1, Limit: 10}
+		mockService.On("ListContacts", mock.Anything, uint(1), req).
+			Run(func(args mock.Arguments) {
+				args.Get(2).(*models.ListContactsRequest).CountCapped = true
+			}).
+			Return([]models.Contact{}, int64(1000), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, float64(1000), data["count"])
+		assert.Equal(t, false, data["count_exact"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("phones are formatted as requested", func(t *testing.T) {
+		for format, want := range map[string]string{
+			"e164":          "+442079460958",
+			"national":      "020 7946 0958",
+			"international": "+44 20 7946 0958",
+		} {
+			req := &models.ListContactsRequest{PhoneFormat: format, Page: 1, Limit: 10}
+			mockService.On("ListContacts", mock.Anything, uint(1), req).
+				Return([]models.Contact{{ID: 1, FullName: "Test Contact", Phone: "442079460958"}}, int64(1), nil).Once()
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?phone_format="+format, nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var response models.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			contacts := response.Data.(map[string]interface{})["contacts"].([]interface{})
+			assert.Equal(t, want, contacts[0].(map[string]interface{})["phone"], format)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("has_avatar filter is bound", func(t *testing.T) {
+		hasAvatar := false
+		req := &models.ListContactsRequest{HasAvatar: &hasAvatar, Page: 1, Limit: 10}
+		mockService.On("ListContacts", mock.Anything, uint(1), req).Return([]models.Contact{}, int64(0), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?has_avatar=false", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("blocked filter is bound", func(t *testing.T) {
+		blocked := true
+		req := &models.ListContactsRequest{Blocked: &blocked, Page: 1, Limit: 10}
+		mockService.On("ListContacts", mock.Anything, uint(1), req).Return([]models.Contact{}, int64(0), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?blocked=true", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
 
+	t.Run("blank search rejected when search is required", func(t *testing.T) {
+		req := &models.ListContactsRequest{Page: 1, Limit: 10}
+		mockService.On("ListContacts", mock.Anything, uint(1), req).Return([]models.Contact(nil), int64(0), service.ErrSearchRequired).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?q=", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Search query is required", response.Message)
 		mockService.AssertExpectations(t)
 	})
 
@@ -473,6 +1036,122 @@ func TestHandler_ListContacts(t *testing.T) {
 	})
 }
 
+func TestHandler_ListContacts_ResultWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockService)
+	handler := handlers.NewHandler(mockService, handlers.WithMaxResultWindow(1000))
+	router := gin.New()
+	router.GET("/api/v1/contacts", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		handler.ListContacts(c)
+	})
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/contacts?page=11&limit=100", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "page", response.Data.(map[string]interface{})["field"])
+	mockService.AssertNotCalled(t, "ListContacts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_ListPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockService)
+	handler := handlers.NewHandler(mockService, handlers.WithMaxListLimit(50))
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", uint(1)) })
+	router.GET("/api/v1/contacts", handler.ListContacts)
+	router.GET("/api/v1/contacts/duplicates", handler.ListDuplicates)
+	router.GET("/api/v1/admin/contacts", handler.AdminListContacts)
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, httpReq)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data, _ := response.Data.(map[string]interface{})
+		return w, data
+	}
+
+	mockService.On("ContactsVersion", mock.Anything, uint(1)).Return(models.ContactsVersion{}, nil)
+	mockService.On("ListContacts", mock.Anything, uint(1), mock.Anything).Return([]models.Contact{}, int64(0), nil)
+	mockService.On("ListDuplicates", mock.Anything, uint(1), mock.Anything).Return([]models.DuplicateGroup{}, int64(0), nil)
+	mockService.On("AdminListContacts", mock.Anything, mock.Anything).Return([]models.AdminContact{}, int64(0), nil)
+
+	for _, path := range []string{"/api/v1/contacts", "/api/v1/contacts/duplicates", "/api/v1/admin/contacts"} {
+		t.Run(path, func(t *testing.T) {
+			clamped := []struct {
+				query       string
+				page, limit float64
+			}{
+				{"page=0&limit=20", 1, 20},
+				{"page=2&limit=0", 2, models.DefaultListLimit},
+				{"page=2&limit=500", 2, 50},
+			}
+			for _, tc := range clamped {
+				w, data := get(path + "?" + tc.query)
+				require.Equal(t, http.StatusOK, w.Code, tc.query)
+				assert.Equal(t, tc.page, data["page"], tc.query)
+				assert.Equal(t, tc.limit, data["limit"], tc.query)
+			}
+
+			for query, field := range map[string]string{"page=-1": "page", "limit=-5": "limit"} {
+				w, data := get(path + "?" + query)
+				assert.Equal(t, http.StatusBadRequest, w.Code, query)
+				assert.Equal(t, field, data["field"], query)
+			}
+		})
+	}
+}
+
+func TestHandler_ListContacts_LinkHeader(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+	mockService.On("ContactsVersion", mock.Anything, uint(1)).Return(models.ContactsVersion{}, nil)
+
+	linkFor := func(t *testing.T, target string) string {
+		mockService.On("ListContacts", mock.Anything, uint(1), mock.Anything).Return([]models.Contact{}, int64(25), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", target, nil)
+		router.ServeHTTP(w, httpReq)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Header().Get("Link")
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		link := linkFor(t, "/api/v1/contacts?q=ali&page=1&limit=10")
+
+		assert.Equal(t, `</api/v1/contacts?limit=10&page=1&q=ali>; rel="first", `+
+			`</api/v1/contacts?limit=10&page=2&q=ali>; rel="next", `+
+			`</api/v1/contacts?limit=10&page=3&q=ali>; rel="last"`, link)
+	})
+
+	t.Run("middle page", func(t *testing.T) {
+		link := linkFor(t, "/api/v1/contacts?page=2&limit=10")
+
+		assert.Equal(t, `</api/v1/contacts?limit=10&page=1>; rel="first", `+
+			`</api/v1/contacts?limit=10&page=1>; rel="prev", `+
+			`</api/v1/contacts?limit=10&page=3>; rel="next", `+
+			`</api/v1/contacts?limit=10&page=3>; rel="last"`, link)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		link := linkFor(t, "/api/v1/contacts?page=3&limit=10")
+
+		assert.Equal(t, `</api/v1/contacts?limit=10&page=1>; rel="first", `+
+			`</api/v1/contacts?limit=10&page=2>; rel="prev", `+
+			`</api/v1/contacts?limit=10&page=3>; rel="last"`, link)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
 func TestHandler_CreateContact(t *testing.T) {
 	mockService := new(MockService)
 	router := setupTestRouter(mockService)
@@ -533,6 +1212,273 @@ func TestHandler_CreateContact(t *testing.T) {
 		assert.Equal(t, 0, response.Status)
 		assert.Equal(t, "Invalid request format", response.Message)
 	})
+
+	t.Run("configured validation status is used", func(t *testing.T) {
+		require.NoError(t, utils.SetValidationStatus(http.StatusUnprocessableEntity))
+		t.Cleanup(func() { _ = utils.SetValidationStatus(http.StatusBadRequest) })
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts", bytes.NewBufferString(`{"phone": "1234567890"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+		assert.Equal(t, "Invalid request format", response.Message)
+	})
+}
+
+func TestHandler_CreateContact_PayloadExample(t *testing.T) {
+	router := setupTestRouter(new(MockService))
+
+	create := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts", bytes.NewBufferString(`{"phone": "1234567890"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.(map[string]interface{})
+	}
+
+	t.Run("omitted by default", func(t *testing.T) {
+		assert.NotContains(t, create(), "example")
+	})
+
+	t.Run("included in development", func(t *testing.T) {
+		utils.SetValidationExamples(true)
+		t.Cleanup(func() { utils.SetValidationExamples(false) })
+
+		example := create()["example"].(map[string]interface{})
+		assert.Equal(t, "string", example["full_name"])
+		assert.Equal(t, "string", example["phone"])
+		assert.Equal(t, "https://example.com", example["avatar_url"])
+		assert.Equal(t, false, example["blocked"])
+	})
+}
+
+func TestHandler_CreateContact_PhoneConflict(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	userID := uint(1)
+	req := &models.CreateContactRequest{
+		FullName: "Duplicate Contact",
+		Phone:    "+1234567890",
+	}
+	existing := &models.Contact{ID: 42, UserID: userID, FullName: "Existing Contact", Phone: req.Phone}
+
+	mockService.On("CreateContact", mock.Anything, userID, req).
+		Return(nil, &service.PhoneConflictError{Contact: existing}).Once()
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/contacts", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response models.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, service.ErrPhoneExists.Error(), data["error"])
+	assert.Equal(t, "phone", data["field"])
+	conflicting := data["conflicting_contact"].(map[string]interface{})
+	assert.Equal(t, float64(existing.ID), conflicting["id"])
+	assert.Equal(t, existing.FullName, conflicting["full_name"])
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_CreateContactsBatch(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	t.Run("mixed batch returns per-item results", func(t *testing.T) {
+		reqs := []models.CreateContactRequest{
+			{FullName: "Alice", Phone: "1111111111"},
+			{Phone: "2222222222"},
+		}
+		results := []models.BatchContactResult{
+			{Index: 0, ID: 10},
+			{Index: 1, Error: "full_name is required"},
+		}
+		mockService.On("CreateContactsBatch", mock.Anything, uint(1), reqs).Return(results, nil).Once()
+
+		body, _ := json.Marshal(reqs)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/batch", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, float64(1), data["created"])
+		assert.Equal(t, float64(1), data["failed"])
+
+		items := data["results"].([]interface{})
+		require.Len(t, items, 2)
+		assert.Equal(t, float64(10), items[0].(map[string]interface{})["id"])
+		assert.Equal(t, "full_name is required", items[1].(map[string]interface{})["error"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		reqs := []models.CreateContactRequest{{FullName: "Alice", Phone: "1111111111"}}
+		mockService.On("CreateContactsBatch", mock.Anything, uint(1), reqs).Return(nil, service.ErrContactQuotaExceeded).Once()
+
+		body, _ := json.Marshal(reqs)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/batch", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("body must be an array", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/batch", bytes.NewBufferString(`{"full_name":"Alice"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_TagContactsByQuery(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	t.Run("returns the tagged count", func(t *testing.T) {
+		req := &models.TagByQueryRequest{Query: "work", Tag: "colleagues"}
+		mockService.On("TagContactsByQuery", mock.Anything, uint(1), req).Return(int64(3), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/tag-by-query", bytes.NewBufferString(`{"q":"work","tag":"colleagues"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(3), response.Data.(map[string]interface{})["tagged"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty filter without confirm", func(t *testing.T) {
+		req := &models.TagByQueryRequest{Tag: "everyone"}
+		mockService.On("TagContactsByQuery", mock.Anything, uint(1), req).Return(int64(0), service.ErrTagFilterRequired).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/tag-by-query", bytes.NewBufferString(`{"tag":"everyone"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("tag is required", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/tag-by-query", bytes.NewBufferString(`{"q":"work"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_ValidateContact(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	t.Run("valid payload", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.CreateContactRequest{
+			FullName: "New Contact",
+			Phone:    "+1234567890",
+		}
+
+		wouldBe := &models.Contact{
+			UserID:   userID,
+			FullName: req.FullName,
+			Phone:    req.Phone,
+		}
+
+		mockService.On("ValidateContact", mock.Anything, userID, req).Return(wouldBe, nil).Once()
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/validate", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, response.Status)
+		assert.Equal(t, "Contact is valid", response.Message)
+
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, float64(0), data["id"])
+		assert.Equal(t, wouldBe.FullName, data["full_name"])
+		assert.Equal(t, wouldBe.Phone, data["phone"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("duplicate phone", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.CreateContactRequest{
+			FullName: "Duplicate Contact",
+			Phone:    "+1234567890",
+		}
+
+		mockService.On("ValidateContact", mock.Anything, userID, req).Return(nil, ErrPhoneExists).Once()
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/validate", bytes.NewBuffer(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.Response
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, response.Status)
+		assert.Equal(t, "Contact validation failed", response.Message)
+
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestHandler_GetContact(t *testing.T) {
@@ -574,6 +1520,33 @@ func TestHandler_GetContact(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("phone formatted as requested", func(t *testing.T) {
+		contact := &models.Contact{ID: 2, UserID: 1, FullName: "Test Contact", Phone: "14155552671"}
+		mockService.On("GetContact", mock.Anything, uint(1), uint(2)).Return(contact, nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts/2?phone_format=national", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "(415) 555-2671", response.Data.(map[string]interface{})["phone"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown phone format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts/2?phone_format=local", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "phone_format")
+	})
+
 	t.Run("invalid contact ID", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts/invalid", nil)
@@ -657,6 +1630,48 @@ func TestHandler_UpdateContact(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("email presence is tracked", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			body  string
+			email models.OptionalString
+		}{
+			{"omitted", `{"full_name":"Alice","phone":"+1111111111"}`, models.OptionalString{}},
+			{"null", `{"full_name":"Alice","phone":"+1111111111","email":null}`, models.NullString()},
+			{"value", `{"full_name":"Alice","phone":"+1111111111","email":"alice@example.com"}`, models.NewOptionalString("alice@example.com")},
+			{"empty", `{"full_name":"Alice","phone":"+1111111111","email":""}`, models.NewOptionalString("")},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				req := &models.UpdateContactRequest{FullName: "Alice", Phone: "+1111111111", Email: tc.email}
+				mockService.On("UpdateContact", mock.Anything, uint(1), uint(1), req).Return(&models.Contact{ID: 1}, nil).Once()
+
+				w := httptest.NewRecorder()
+				httpReq, _ := http.NewRequest("PUT", "/api/v1/contacts/1", bytes.NewBufferString(tc.body))
+				httpReq.Header.Set("Content-Type", "application/json")
+
+				router.ServeHTTP(w, httpReq)
+
+				assert.Equal(t, http.StatusOK, w.Code)
+				mockService.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("invalid email is rejected", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", "/api/v1/contacts/1", bytes.NewBufferString(`{"full_name":"Alice","phone":"+1111111111","email":"alice"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateContact", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("invalid contact ID", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		httpReq, _ := http.NewRequest("PUT", "/api/v1/contacts/invalid", bytes.NewBufferString(`{"full_name":"test","phone":"123"}`))
@@ -756,3 +1771,285 @@ func TestHandler_DeleteContact(t *testing.T) {
 		assert.Equal(t, "Contact not found", response.Message)
 	})
 }
+
+func TestHandler_RestoreContact(t *testing.T) {
+	restore := func(router *gin.Engine, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/"+id+"/restore", nil)
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("successful restore", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+		mockService.On("RestoreContact", mock.Anything, uint(1), uint(5)).Return(nil).Once()
+
+		w := restore(router, "5")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Contact restored successfully", response.Message)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("no deleted contact", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+		mockService.On("RestoreContact", mock.Anything, uint(1), uint(999)).Return(service.ErrContactNotFound).Once()
+
+		assert.Equal(t, http.StatusNotFound, restore(router, "999").Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupTestRouter(mockService)
+		mockService.On("RestoreContact", mock.Anything, uint(1), uint(5)).Return(service.ErrContactQuotaExceeded).Once()
+
+		assert.Equal(t, http.StatusForbidden, restore(router, "5").Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandler_AdminListContacts(t *testing.T) {
+	// setupAdminRouter mounts the admin listing behind the real role guard
+	setupAdminRouter := func(mockService *MockService, role string) *gin.Engine {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		handler := handlers.NewHandler(mockService)
+
+		admin := router.Group("/api/v1/admin")
+		admin.Use(func(c *gin.Context) {
+			c.Set("user_id", uint(1))
+			c.Set("role", role)
+			c.Next()
+		}, middleware.RequireRole(models.RoleAdmin))
+		admin.GET("/contacts", handler.AdminListContacts)
+		return router
+	}
+
+	t.Run("admin sees deleted contacts marked as deleted", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupAdminRouter(mockService, models.RoleAdmin)
+
+		deletedAt := time.Now()
+		contacts := []models.AdminContact{
+			{Contact: models.Contact{ID: 1, FullName: "Live"}, UserID: 2},
+			{Contact: models.Contact{ID: 2, FullName: "Gone"}, UserID: 2, Deleted: true, DeletedAt: &deletedAt},
+		}
+		expectedReq := &models.AdminListContactsRequest{UserID: 2, IncludeDeleted: true, Page: 1, Limit: 10}
+		mockService.On("AdminListContacts", mock.Anything, expectedReq).Return(contacts, int64(2), nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/admin/contacts?user_id=2&include_deleted=true", nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, true, data["include_deleted"])
+
+		items := data["contacts"].([]interface{})
+		require.Len(t, items, 2)
+		assert.Equal(t, false, items[0].(map[string]interface{})["deleted"])
+		assert.Equal(t, true, items[1].(map[string]interface{})["deleted"])
+		assert.Contains(t, items[1], "deleted_at")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("non-admin cannot list deleted contacts", func(t *testing.T) {
+		mockService := new(MockService)
+		router := setupAdminRouter(mockService, models.RoleUser)
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/admin/contacts?include_deleted=true", nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "AdminListContacts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("handler refuses deleted rows without the admin role", func(t *testing.T) {
+		mockService := new(MockService)
+		router := gin.New()
+		router.GET("/contacts", func(c *gin.Context) {
+			c.Set("role", models.RoleUser)
+			c.Next()
+		}, handlers.NewHandler(mockService).AdminListContacts)
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/contacts?include_deleted=true", nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "AdminListContacts", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_MissingUserID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockService)
+	handler := handlers.NewHandler(mockService)
+
+	// Routes registered without the auth middleware, so no user_id is set
+	router := gin.New()
+	router.GET("/me", handler.GetProfile)
+	router.GET("/contacts", handler.ListContacts)
+	router.POST("/contacts", handler.CreateContact)
+	router.DELETE("/contacts/:id", handler.DeleteContact)
+	router.GET("/guarded", handler.RequireUser, handler.GetProfile)
+
+	for _, tc := range []struct{ method, path, body string }{
+		{"GET", "/me", ""},
+		{"GET", "/contacts", ""},
+		{"POST", "/contacts", `{"full_name":"John Doe","phone":"081234567890"}`},
+		{"DELETE", "/contacts/1", ""},
+		{"GET", "/guarded", ""},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+
+	mockService.AssertNotCalled(t, "GetUserProfile", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ListContacts", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "CreateContact", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "DeleteContact", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_RequireUserLoadError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockService)
+	handler := handlers.NewHandler(mockService)
+
+	router := gin.New()
+	router.GET("/guarded", func(c *gin.Context) { c.Set("user_id", uint(1)) }, handler.RequireUser, handler.GetProfile)
+
+	mockService.On("GetUser", mock.Anything, uint(1)).Return(nil, errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodGet, "/guarded", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockService.AssertNotCalled(t, "GetUserProfile", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ServiceErrorStatuses(t *testing.T) {
+	register := `{"full_name":"John Doe","email":"john@example.com","password":"password123"}`
+	contact := `{"full_name":"John Doe","phone":"14155552671"}`
+
+	registerFails := func(err error) func(*MockService) {
+		return func(m *MockService) { m.On("Register", mock.Anything, mock.Anything).Return(nil, "", err) }
+	}
+	updateProfileFails := func(err error) func(*MockService) {
+		return func(m *MockService) { m.On("UpdateProfile", mock.Anything, uint(1), mock.Anything).Return(nil, err) }
+	}
+	createFails := func(err error) func(*MockService) {
+		return func(m *MockService) { m.On("CreateContact", mock.Anything, uint(1), mock.Anything).Return(nil, err) }
+	}
+	updateFails := func(err error) func(*MockService) {
+		return func(m *MockService) {
+			m.On("UpdateContact", mock.Anything, uint(1), uint(1), mock.Anything).Return(nil, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		setup   func(*MockService)
+		status  int
+		message string
+	}{
+		{"register with a taken email", "POST", "/api/v1/auth/register", register, registerFails(service.ErrEmailTaken), http.StatusConflict, "Email already registered"},
+		{"register with a taken phone", "POST", "/api/v1/auth/register", register, registerFails(service.ErrPhoneTaken), http.StatusConflict, "Phone number already registered"},
+		{"register with an invalid phone", "POST", "/api/v1/auth/register", register, registerFails(service.ErrInvalidPhone), http.StatusBadRequest, "Invalid phone number"},
+		{"register with an expired invite", "POST", "/api/v1/auth/register", register, registerFails(service.ErrInviteCodeExpired), http.StatusForbidden, "Invalid invite code"},
+		{"register failing otherwise", "POST", "/api/v1/auth/register", register, registerFails(errors.New("boom")), http.StatusBadRequest, "Registration failed"},
+		{"profile with a taken phone", "PUT", "/api/v1/me", `{"full_name":"John Doe","phone":"14155552671"}`, updateProfileFails(service.ErrPhoneTaken), http.StatusConflict, "Phone number already registered"},
+		{"profile with an invalid phone", "PUT", "/api/v1/me", `{"full_name":"John Doe","phone":"abc"}`, updateProfileFails(service.ErrInvalidPhone), http.StatusBadRequest, "Invalid phone number"},
+		{"create with a duplicate phone", "POST", "/api/v1/contacts", contact, createFails(&service.PhoneConflictError{}), http.StatusConflict, "Phone number already exists"},
+		{"create with an invalid phone", "POST", "/api/v1/contacts", contact, createFails(service.ErrInvalidPhone), http.StatusBadRequest, "Invalid phone number"},
+		{"create over quota", "POST", "/api/v1/contacts", contact, createFails(service.ErrContactQuotaExceeded), http.StatusForbidden, "Contact quota exceeded"},
+		{"update a missing contact", "PUT", "/api/v1/contacts/1", contact, updateFails(service.ErrContactNotFound), http.StatusNotFound, "Contact not found"},
+		{"update with a duplicate phone", "PUT", "/api/v1/contacts/1", contact, updateFails(service.ErrPhoneExists), http.StatusConflict, "Phone number already exists"},
+		{"update with an invalid phone", "PUT", "/api/v1/contacts/1", contact, updateFails(service.ErrInvalidPhone), http.StatusBadRequest, "Invalid phone number"},
+		{"get a missing contact", "GET", "/api/v1/contacts/1", "", func(m *MockService) {
+			m.On("GetContact", mock.Anything, uint(1), uint(1)).Return(nil, service.ErrContactNotFound)
+		}, http.StatusNotFound, "Contact not found"},
+		{"delete a missing contact", "DELETE", "/api/v1/contacts/1", "", func(m *MockService) {
+			m.On("DeleteContact", mock.Anything, uint(1), uint(1)).Return(service.ErrContactNotFound)
+		}, http.StatusNotFound, "Contact not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			router := setupTestRouter(mockService)
+			tc.setup(mockService)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tc.status, w.Code)
+			var response models.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tc.status, response.StatusCode)
+			assert.Equal(t, tc.message, response.Message)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ConflictNamesField(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		setup  func(*MockService)
+		field  string
+	}{
+		{"register with a taken email", "POST", "/api/v1/auth/register", `{"full_name":"John Doe","email":"john@example.com","password":"password123"}`, func(m *MockService) {
+			m.On("Register", mock.Anything, mock.Anything).Return(nil, "", service.ErrEmailTaken)
+		}, "email"},
+		{"register with a taken phone", "POST", "/api/v1/auth/register", `{"full_name":"John Doe","email":"john@example.com","password":"password123","phone":"14155552671"}`, func(m *MockService) {
+			m.On("Register", mock.Anything, mock.Anything).Return(nil, "", service.ErrPhoneTaken)
+		}, "phone"},
+		{"profile with a taken phone", "PUT", "/api/v1/me", `{"full_name":"John Doe","phone":"14155552671"}`, func(m *MockService) {
+			m.On("UpdateProfile", mock.Anything, uint(1), mock.Anything).Return(nil, service.ErrPhoneTaken)
+		}, "phone"},
+		{"update with a duplicate phone", "PUT", "/api/v1/contacts/1", `{"full_name":"John Doe","phone":"14155552671"}`, func(m *MockService) {
+			m.On("UpdateContact", mock.Anything, uint(1), uint(1), mock.Anything).Return(nil, service.ErrPhoneExists)
+		}, "phone"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			router := setupTestRouter(mockService)
+			tc.setup(mockService)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			var response models.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tc.field, response.Data.(map[string]interface{})["field"])
+		})
+	}
+}
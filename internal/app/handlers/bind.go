@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"user-service/internal/app/models"
+	"user-service/internal/logger"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// bindErrorData is the data of a bind failure: the error, the message of each
+// invalid field under "fields" and, when enabled, an example of the payload
+// req expects
+func bindErrorData(err error, req interface{}) gin.H {
+	data := gin.H{"error": err.Error()}
+	if fields := fieldErrors(err, req); len(fields) > 0 {
+		data["fields"] = fields
+	}
+	if utils.ValidationExamplesEnabled() {
+		data["example"] = utils.PayloadExample(req)
+	}
+	return data
+}
+
+// bindFailed logs a request body that failed to bind to req and answers it
+// with every invalid field
+func bindFailed(c *gin.Context, handler string, err error, req interface{}) {
+	fields := fieldErrors(err, req)
+	if len(fields) == 0 {
+		fields = map[string]string{"request_body": "Invalid JSON format"}
+	}
+	logger.LogValidationError(c, handler, fields, map[string]interface{}{
+		"validation_error": err.Error(),
+	})
+	c.JSON(utils.ValidationStatus(), models.Response{
+		Status:     0,
+		StatusCode: utils.ValidationStatus(),
+		Message:    "Invalid request format",
+		Data:       bindErrorData(err, req),
+	})
+}
+
+// fieldErrors maps each invalid field of a bind error to what is wrong with
+// it, keyed by the field's JSON name. Errors that do not concern a field, such
+// as malformed JSON, yield no entries.
+func fieldErrors(err error, req interface{}) map[string]string {
+	fields := map[string]string{}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		fields[typeErr.Field] = "must be a " + typeErr.Type.String()
+		return fields
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return fields
+	}
+	for _, fe := range validationErrs {
+		fields[jsonFieldName(req, fe)] = fieldErrorMessage(fe)
+	}
+	return fields
+}
+
+// jsonFieldName returns the JSON name of the field a validation error is
+// about, falling back to its Go name
+func jsonFieldName(req interface{}, fe validator.FieldError) string {
+	t := reflect.TypeOf(req)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fe.Field()
+	}
+	if field, ok := t.FieldByName(fe.StructField()); ok {
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return fe.Field()
+}
+
+// fieldErrorMessage describes a failed validation rule in words
+func fieldErrorMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		if isString {
+			return fmt.Sprintf("min %s characters", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if isString {
+			return fmt.Sprintf("max %s characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	}
+	return "failed the " + fe.Tag() + " check"
+}
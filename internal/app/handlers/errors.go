@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"user-service/internal/app/service"
+	"user-service/pkg/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse is the status and message a service error is answered with
+type errorResponse struct {
+	err     error
+	status  int
+	message string
+}
+
+// serviceErrors maps the service's sentinel errors to their responses, so an
+// error is answered the same way by every endpoint that can return it
+var serviceErrors = []errorResponse{
+	{service.ErrInvalidCredentials, http.StatusUnauthorized, "Invalid email or password"},
+	{service.ErrIncorrectPassword, http.StatusUnauthorized, "Invalid password"},
+
+	{service.ErrRegistrationClosed, http.StatusForbidden, "Registration disabled"},
+	{service.ErrInviteCodeInvalid, http.StatusForbidden, "Invalid invite code"},
+	{service.ErrInviteCodeUsedUp, http.StatusForbidden, "Invalid invite code"},
+	{service.ErrInviteCodeExpired, http.StatusForbidden, "Invalid invite code"},
+	{service.ErrContactQuotaExceeded, http.StatusForbidden, "Contact quota exceeded"},
+
+	{service.ErrContactNotFound, http.StatusNotFound, "Contact not found"},
+	{service.ErrImportJobNotFound, http.StatusNotFound, "Import job not found"},
+	{service.ErrGroupNotFound, http.StatusNotFound, "Group not found"},
+
+	{service.ErrEmailTaken, http.StatusConflict, "Email already registered"},
+	{service.ErrPhoneTaken, http.StatusConflict, "Phone number already registered"},
+	{service.ErrPhoneExists, http.StatusConflict, "Phone number already exists"},
+	{service.ErrGroupNameTaken, http.StatusConflict, "Group name already exists"},
+
+	{service.ErrContactChanged, http.StatusPreconditionFailed, "Contact was modified"},
+
+	{service.ErrInvalidPhone, http.StatusBadRequest, "Invalid phone number"},
+	{service.ErrInvalidEmail, http.StatusBadRequest, "Invalid email"},
+	{service.ErrFullNameRequired, http.StatusBadRequest, "Full name is required"},
+	{service.ErrPhoneRequired, http.StatusBadRequest, "Phone is required"},
+	{service.ErrSearchRequired, http.StatusBadRequest, "Search query is required"},
+	{service.ErrInvalidDuplicateBy, http.StatusBadRequest, "Invalid query parameters"},
+	{service.ErrBatchEmpty, http.StatusBadRequest, "Invalid batch"},
+	{service.ErrBatchTooLarge, http.StatusBadRequest, "Invalid batch"},
+	{service.ErrLookupEmpty, http.StatusBadRequest, "Invalid lookup"},
+	{service.ErrLookupTooLarge, http.StatusBadRequest, "Invalid lookup"},
+	{service.ErrTagRequired, http.StatusBadRequest, "Invalid tag request"},
+	{service.ErrTagFilterRequired, http.StatusBadRequest, "Invalid tag request"},
+	{service.ErrGroupNameRequired, http.StatusBadRequest, "Group name is required"},
+	{service.ErrInvalidPatchField, http.StatusBadRequest, "Invalid request format"},
+	{service.ErrMergeDuplicatesRequired, http.StatusBadRequest, "Invalid merge"},
+	{service.ErrMergeTooLarge, http.StatusBadRequest, "Invalid merge"},
+	{service.ErrMergeIntoSelf, http.StatusBadRequest, "Invalid merge"},
+	{service.ErrInvalidImportFile, http.StatusBadRequest, "Invalid import file"},
+	{service.ErrInvalidDedupMode, http.StatusBadRequest, "Invalid dedup mode"},
+	{service.ErrResetTokenInvalid, http.StatusBadRequest, "Invalid or expired reset token"},
+	{service.ErrResetTokenExpired, http.StatusBadRequest, "Invalid or expired reset token"},
+	{service.ErrResetTokenUsed, http.StatusBadRequest, "Invalid or expired reset token"},
+	{service.ErrTokenNotRevocable, http.StatusBadRequest, "Token cannot be revoked"},
+
+	{db.ErrQueryTimeout, http.StatusGatewayTimeout, "Database timeout"},
+}
+
+// conflictFields names the request field holding the taken value of each
+// conflict error
+var conflictFields = []struct {
+	err   error
+	field string
+}{
+	{service.ErrEmailTaken, "email"},
+	{service.ErrPhoneTaken, "phone"},
+	{service.ErrPhoneExists, "phone"},
+	{service.ErrGroupNameTaken, "name"},
+}
+
+// httpError returns the status and message err is answered with. Errors the
+// service does not define get fallbackStatus and fallbackMessage, which say
+// what the endpoint failed to do.
+func httpError(err error, fallbackStatus int, fallbackMessage string) (int, string) {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return e.status, e.message
+		}
+	}
+	return fallbackStatus, fallbackMessage
+}
+
+// errorData is the error payload of a failed request. Conflicts also name the
+// field holding the taken value so clients can point at it.
+func errorData(err error) gin.H {
+	data := gin.H{"error": err.Error()}
+	for _, c := range conflictFields {
+		if errors.Is(err, c.err) {
+			data["field"] = c.field
+			break
+		}
+	}
+	return data
+}
+
+// publicErrorData is the error payload of endpoints that do not reveal
+// internal failures: service errors are included, anything answered with 500 is not
+func publicErrorData(err error, status int) gin.H {
+	if status == http.StatusInternalServerError {
+		return gin.H{}
+	}
+	return gin.H{"error": err.Error()}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"user-service/internal/app/models"
+)
+
+// collectionETag builds a weak ETag for a listing from the collection version
+// and the query that selected the page
+func collectionETag(userID uint, version models.ContactsVersion, rawQuery string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d|%d|%d|%d|%s", userID, version.Count, version.Versions, version.LastID, rawQuery))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// contactETag builds a strong ETag for a single contact from its stored version
+func contactETag(contact *models.Contact) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d|%d", contact.ID, contact.Version))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ifMatchMatches reports whether an If-Match header matches etag. If-Match uses
+// strong comparison, so weak tags never match.
+func ifMatchMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using the
+// weak comparison RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
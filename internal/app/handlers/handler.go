@@ -1,45 +1,127 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 	"user-service/internal/app/models"
+	"user-service/internal/app/qrcode"
 	"user-service/internal/app/service"
+	"user-service/internal/app/storage"
+	"user-service/internal/app/vcard"
 	"user-service/internal/logger"
+	"user-service/internal/middleware"
 	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+// maxImportFileSize caps the size of an uploaded contact import file
+const maxImportFileSize = 10 << 20
+
+// defaultMaxAvatarSize caps the size of an uploaded avatar unless WithAvatars says otherwise
+const defaultMaxAvatarSize = 2 << 20
+
+// avatarExtensions maps the accepted avatar image types to their file extension
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// currentUserKey is the context key RequireUser stores the loaded user under
+const currentUserKey = "user"
+
 // Handler contains methods for handling HTTP requests
 type Handler struct {
-	service   service.Service
-	jwtSecret string
+	service         service.Service
+	maxResultWindow int
+	maxListLimit    int
+	qrSize          int
+	qrLevel         qrcode.Level
+	avatars         storage.Store
+	maxAvatarSize   int64
+	migrations      MigrationReporter
+}
+
+// Option configures optional handler behaviour
+type Option func(*Handler)
+
+// WithMaxResultWindow rejects contact list pages reaching past window results
+// (page * limit); 0 allows any depth
+func WithMaxResultWindow(window int) Option {
+	return func(h *Handler) {
+		h.maxResultWindow = window
+	}
+}
+
+// WithMaxListLimit caps the limit of list pages; 0 keeps models.MaxListLimit
+func WithMaxListLimit(limit int) Option {
+	return func(h *Handler) {
+		h.maxListLimit = limit
+	}
+}
+
+// WithQRCode sets the approximate pixel width and error correction level of
+// contact QR codes
+func WithQRCode(size int, level qrcode.Level) Option {
+	return func(h *Handler) {
+		h.qrSize = size
+		h.qrLevel = level
+	}
+}
+
+// WithAvatars enables avatar uploads to store, accepting images of up to
+// maxBytes; a non-positive maxBytes keeps the 2 MiB default
+func WithAvatars(store storage.Store, maxBytes int64) Option {
+	return func(h *Handler) {
+		h.avatars = store
+		if maxBytes > 0 {
+			h.maxAvatarSize = maxBytes
+		}
+	}
+}
+
+func NewHandler(service service.Service, opts ...Option) *Handler {
+	h := &Handler{
+		service:       service,
+		qrSize:        256,
+		qrLevel:       qrcode.Medium,
+		maxAvatarSize: defaultMaxAvatarSize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-func NewHandler(service service.Service, jwtSecret string) *Handler {
-	return &Handler{
-		service:   service,
-		jwtSecret: jwtSecret,
+// contactErrorData builds the error payload for contact writes. Phone conflicts
+// include the existing contact so clients can offer to open or merge it.
+func contactErrorData(err error) gin.H {
+	data := errorData(err)
+
+	var conflict *service.PhoneConflictError
+	if errors.As(err, &conflict) && conflict.Contact != nil {
+		data["conflicting_contact"] = gin.H{
+			"id":        conflict.Contact.ID,
+			"full_name": conflict.Contact.FullName,
+		}
 	}
+
+	return data
 }
 
 // Register handles user registration
 func (h *Handler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.LogValidationError(c, "Register", map[string]string{
-			"request_body": "Invalid JSON format",
-		}, map[string]interface{}{
-			"validation_error": err.Error(),
-		})
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid request format",
-			Data:       gin.H{"error": err.Error()},
-		})
+		bindFailed(c, "Register", err, &req)
 		return
 	}
 
@@ -53,36 +135,17 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Register(c.Request.Context(), req)
+	user, tokenString, err := h.service.Register(c.Request.Context(), req)
 	if err != nil {
-		logger.LogEndpointError(c, "Register", err, http.StatusBadRequest, map[string]interface{}{
+		status, message := httpError(err, http.StatusBadRequest, "Registration failed")
+		logger.LogEndpointError(c, "Register", err, status, map[string]interface{}{
 			"email": req.Email,
 		})
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Registration failed",
-			Data:       gin.H{"error": err.Error()},
-		})
-		return
-	}
-
-	// Generate JWT token for the newly registered user
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = user.ID
-
-	tokenString, err := token.SignedString([]byte(h.jwtSecret)) // Using the JWT secret from handler
-	if err != nil {
-		logger.Error(err, map[string]interface{}{
-			"handler": "Register",
-			"email":   req.Email,
-		})
-		c.JSON(http.StatusInternalServerError, models.Response{
+		c.JSON(status, models.Response{
 			Status:     0,
-			StatusCode: http.StatusInternalServerError,
-			Message:    "Token generation failed",
-			Data:       gin.H{"error": "Failed to generate access token"},
+			StatusCode: status,
+			Message:    message,
+			Data:       errorData(err),
 		})
 		return
 	}
@@ -111,17 +174,7 @@ func (h *Handler) Register(c *gin.Context) {
 func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.LogValidationError(c, "Login", map[string]string{
-			"request_body": "Invalid JSON format",
-		}, map[string]interface{}{
-			"validation_error": err.Error(),
-		})
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid request format",
-			Data:       gin.H{},
-		})
+		bindFailed(c, "Login", err, &req)
 		return
 	}
 
@@ -140,6 +193,11 @@ func (h *Handler) Login(c *gin.Context) {
 		logger.LogAuthError(c, "Login", err, map[string]interface{}{
 			"email": req.Email,
 		})
+		var locked *service.AccountLockedError
+		if errors.As(err, &locked) {
+			middleware.RespondRateLimited(c, time.Until(locked.Until))
+			return
+		}
 		c.JSON(http.StatusUnauthorized, models.Response{
 			Status:     0,
 			StatusCode: http.StatusUnauthorized,
@@ -157,10 +215,78 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
-// GetProfile handles getting the logged-in user's profile
+// ForgotPasswordMessage is returned for every forgot-password request, whether
+// or not the email belongs to an account
+const ForgotPasswordMessage = "If the email is registered, a password reset link has been sent"
+
+// ForgotPassword handles requesting a password reset token
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "ForgotPassword", err, &req)
+		return
+	}
+
+	// Failures are logged but not reported, so responses do not reveal accounts
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		logger.LogEndpointError(c, "ForgotPassword", err, http.StatusOK, map[string]interface{}{
+			"email": req.Email,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    ForgotPasswordMessage,
+		Data:       nil,
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "ResetPassword", err, &req)
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to reset password")
+		if status == http.StatusInternalServerError {
+			logger.LogEndpointError(c, "ResetPassword", err, status, nil)
+		}
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Password reset successfully",
+		Data:       nil,
+	})
+}
+
+// GetProfile handles getting the logged-in user's profile. With
+// ?include=stats it also returns member_since and total_contacts.
 func (h *Handler) GetProfile(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	user, err := h.service.GetUserProfile(c.Request.Context(), userID)
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var profile interface{}
+	var err error
+	if c.Query("include") == "stats" {
+		profile, err = h.service.GetUserProfileStats(c.Request.Context(), userID)
+	} else {
+		profile, err = h.currentUser(c)
+	}
 	if err != nil {
 		logger.LogEndpointError(c, "GetProfile", err, http.StatusNotFound, map[string]interface{}{
 			"user_id": userID,
@@ -178,31 +304,86 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		Status:     1,
 		StatusCode: http.StatusOK,
 		Message:    "Profile loaded successfully",
-		Data:       user,
+		Data:       profile,
 	})
 }
 
+// RequireUser rejects tokens whose user no longer exists, e.g. a deleted
+// account whose token has not expired yet, and stores the loaded user in the
+// context. Failing to load the user is answered with 500 rather than 401. It
+// must run after the auth middleware.
+func (h *Handler) RequireUser(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Read uncached: a cached profile can outlive an account deleted elsewhere
+	user, err := h.service.GetUser(c.Request.Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		logger.LogAuthError(c, "RequireUser", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		logger.LogEndpointError(c, "RequireUser", err, http.StatusInternalServerError, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		c.Abort()
+		return
+	}
+
+	c.Set(currentUserKey, user)
+	c.Next()
+}
+
+// requireUserID returns the authenticated user's ID. When the auth middleware
+// did not set one, e.g. on a misconfigured route, it aborts with 401 so the
+// handler never acts on user 0.
+func requireUserID(c *gin.Context) (uint, bool) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		c.Abort()
+		return 0, false
+	}
+	return userID, true
+}
+
+// currentUser returns the user loaded by RequireUser, or loads it when the
+// check is disabled
+func (h *Handler) currentUser(c *gin.Context) (*models.User, error) {
+	if user, ok := c.Get(currentUserKey); ok {
+		return user.(*models.User), nil
+	}
+	return h.service.GetUserProfile(c.Request.Context(), c.GetUint("user_id"))
+}
+
 // UpdateProfile handles updating the logged-in user's profile
 func (h *Handler) UpdateProfile(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
 	var req models.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid request format",
-			Data:       gin.H{"error": err.Error()},
-		})
+		bindFailed(c, "UpdateProfile", err, &req)
 		return
 	}
 
-	userID := c.GetUint("user_id")
 	user, err := h.service.UpdateProfile(c.Request.Context(), userID, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
+		status, message := httpError(err, http.StatusBadRequest, "Update failed")
+		c.JSON(status, models.Response{
 			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Update failed",
-			Data:       gin.H{"error": err.Error()},
+			StatusCode: status,
+			Message:    message,
+			Data:       errorData(err),
 		})
 		return
 	}
@@ -215,31 +396,27 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	})
 }
 
-// ListContacts handles getting the contact list with search and pagination
-func (h *Handler) ListContacts(c *gin.Context) {
-	userID := c.GetUint("user_id")
-
-	var req models.ListContactsRequest
-	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid query parameters",
-			Data:       gin.H{"error": err.Error()},
-		})
+// PatchProfile handles partially updating the user's profile
+func (h *Handler) PatchProfile(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
-	// Calculate offset for pagination
-	req.Offset = (req.Page - 1) * req.Limit
+	var req models.PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "PatchProfile", err, &req)
+		return
+	}
 
-	contacts, count, err := h.service.ListContacts(c.Request.Context(), userID, &req)
+	user, err := h.service.PatchProfile(c.Request.Context(), userID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.Response{
+		status, message := httpError(err, http.StatusBadRequest, "Update failed")
+		c.JSON(status, models.Response{
 			Status:     0,
-			StatusCode: http.StatusInternalServerError,
-			Message:    "Failed to load contacts",
-			Data:       gin.H{},
+			StatusCode: status,
+			Message:    message,
+			Data:       errorData(err),
 		})
 		return
 	}
@@ -247,75 +424,75 @@ func (h *Handler) ListContacts(c *gin.Context) {
 	c.JSON(http.StatusOK, models.Response{
 		Status:     1,
 		StatusCode: http.StatusOK,
-		Message:    "Contacts loaded successfully",
-		Data: gin.H{
-			"count":    count,
-			"page":     req.Page,
-			"limit":    req.Limit,
-			"contacts": contacts,
-		},
+		Message:    "Profile updated successfully",
+		Data:       user,
 	})
 }
 
-// CreateContact handles creating a new contact
-func (h *Handler) CreateContact(c *gin.Context) {
-	var req models.CreateContactRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid request format",
-			Data:       gin.H{"error": err.Error()},
-		})
+// VerifyPassword re-confirms the logged-in user's password before a sensitive action
+func (h *Handler) VerifyPassword(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
-	// Validate optional email format
-	if !utils.ValidateContactEmail(c, req.Email) {
+	var req models.VerifyPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "VerifyPassword", err, &req)
 		return
 	}
 
-	userID := c.GetUint("user_id")
-	contact, err := h.service.CreateContact(c.Request.Context(), userID, &req)
+	err := h.service.VerifyPassword(c.Request.Context(), userID, req.Password)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to verify password")
+		if status == http.StatusInternalServerError {
+			logger.LogEndpointError(c, "VerifyPassword", err, status, map[string]interface{}{
+				"user_id": userID,
+			})
+		}
+		c.JSON(status, models.Response{
 			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Failed to create contact",
+			StatusCode: status,
+			Message:    message,
 			Data:       gin.H{"error": err.Error()},
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.Response{
+	c.JSON(http.StatusOK, models.Response{
 		Status:     1,
-		StatusCode: http.StatusCreated,
-		Message:    "Contact created successfully",
-		Data:       contact,
+		StatusCode: http.StatusOK,
+		Message:    "Password verified",
+		Data:       gin.H{"verified": true},
 	})
 }
 
-// GetContact handles getting a contact's details
-func (h *Handler) GetContact(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	contactID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Status:     0,
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid contact ID",
-			Data:       gin.H{},
-		})
+// ChangePassword handles changing the logged-in user's password
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "ChangePassword", err, &req)
 		return
 	}
 
-	contact, err := h.service.GetContact(c.Request.Context(), userID, uint(contactID))
+	err := h.service.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.Response{
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to change password")
+		if status == http.StatusInternalServerError {
+			logger.LogEndpointError(c, "ChangePassword", err, status, map[string]interface{}{
+				"user_id": userID,
+			})
+		}
+		c.JSON(status, models.Response{
 			Status:     0,
-			StatusCode: http.StatusNotFound,
-			Message:    "Contact not found",
-			Data:       gin.H{},
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
 		})
 		return
 	}
@@ -323,81 +500,1002 @@ func (h *Handler) GetContact(c *gin.Context) {
 	c.JSON(http.StatusOK, models.Response{
 		Status:     1,
 		StatusCode: http.StatusOK,
-		Message:    "Contact detail loaded",
-		Data:       contact,
+		Message:    "Password changed successfully",
+		Data:       nil,
 	})
 }
 
-// UpdateContact handles updating a contact
-func (h *Handler) UpdateContact(c *gin.Context) {
-	var req models.UpdateContactRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
+// UploadAvatar stores an uploaded JPEG or PNG image and makes it the user's avatar
+func (h *Handler) UploadAvatar(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if h.avatars == nil {
+		c.JSON(http.StatusServiceUnavailable, models.Response{
 			Status:     0,
-			StatusCode: http.StatusBadRequest,
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "Avatar uploads are not configured",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(utils.ValidationStatus(), models.Response{
+			Status:     0,
+			StatusCode: utils.ValidationStatus(),
 			Message:    "Invalid request format",
-			Data:       gin.H{"error": err.Error()},
+			Data:       gin.H{"error": "avatar is required"},
 		})
 		return
 	}
 
-	// Validate optional email format
-	if !utils.ValidateContactEmail(c, req.Email) {
+	if fileHeader.Size > h.maxAvatarSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.Response{
+			Status:     0,
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Message:    "Avatar too large",
+			Data:       gin.H{"max_bytes": h.maxAvatarSize},
+		})
 		return
 	}
 
-	userID := c.GetUint("user_id")
-	contactID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	file, err := fileHeader.Open()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.Response{
 			Status:     0,
 			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid contact ID",
-			Data:       gin.H{},
+			Message:    "Invalid request format",
+			Data:       gin.H{"error": err.Error()},
 		})
 		return
 	}
+	defer file.Close()
 
-	contact, err := h.service.UpdateContact(c.Request.Context(), userID, uint(contactID), &req)
+	data, err := io.ReadAll(io.LimitReader(file, h.maxAvatarSize))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.Response{
 			Status:     0,
 			StatusCode: http.StatusBadRequest,
-			Message:    "Failed to update contact",
+			Message:    "Invalid request format",
 			Data:       gin.H{"error": err.Error()},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.Response{
-		Status:     1,
-		StatusCode: http.StatusOK,
-		Message:    "Contact updated successfully",
-		Data:       contact,
-	})
-}
-
-// DeleteContact handles deleting a contact
-func (h *Handler) DeleteContact(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	contactID, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
+	// The type is sniffed from the content; the client's Content-Type is not trusted
+	contentType := http.DetectContentType(data)
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
 		c.JSON(http.StatusBadRequest, models.Response{
 			Status:     0,
 			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid contact ID",
-			Data:       gin.H{},
+			Message:    "Unsupported avatar type",
+			Data:       gin.H{"error": "avatar must be a JPEG or PNG image", "content_type": contentType},
+		})
+		return
+	}
+
+	// Every upload gets a new name so cached copies of the old avatar go stale
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to store avatar",
+			Data:       gin.H{"error": err.Error()},
 		})
 		return
 	}
+	key := fmt.Sprintf("%d/%s%s", userID, hex.EncodeToString(name), ext)
 
-	err = h.service.DeleteContact(c.Request.Context(), userID, uint(contactID))
+	url, err := h.avatars.Put(c.Request.Context(), key, contentType, data)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.Response{
+		logger.LogEndpointError(c, "UploadAvatar", err, http.StatusInternalServerError, map[string]interface{}{
+			"key": key,
+		})
+		c.JSON(http.StatusInternalServerError, models.Response{
 			Status:     0,
-			StatusCode: http.StatusNotFound,
-			Message:    "Contact not found",
-			Data:       gin.H{},
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to store avatar",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	user, err := h.service.UpdateAvatar(c.Request.Context(), userID, url)
+	if err != nil {
+		logger.LogEndpointError(c, "UploadAvatar", err, http.StatusInternalServerError, map[string]interface{}{
+			"avatar_url": url,
+		})
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to update avatar",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Avatar updated successfully",
+		Data:       user,
+	})
+}
+
+// Logout handles revoking the caller's access token
+func (h *Handler) Logout(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	expiresAt, _ := c.Get("token_expires_at")
+	exp, _ := expiresAt.(time.Time)
+	err := h.service.Logout(c.Request.Context(), userID, c.GetString("token_id"), exp)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to log out")
+		if status == http.StatusInternalServerError {
+			logger.LogEndpointError(c, "Logout", err, status, map[string]interface{}{
+				"user_id": userID,
+			})
+		}
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Logged out successfully",
+		Data:       nil,
+	})
+}
+
+// IsTokenRevoked reports whether a token was logged out. It backs the auth
+// middleware's revocation check.
+func (h *Handler) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return h.service.IsTokenRevoked(ctx, jti)
+}
+
+// LookupDirectory handles looking up the public directory info of several phone numbers
+func (h *Handler) LookupDirectory(c *gin.Context) {
+	var req models.DirectoryLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "LookupDirectory", err, &req)
+		return
+	}
+
+	entries, err := h.service.LookupDirectory(c.Request.Context(), req.Phones)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to look up phone numbers")
+		logger.LogEndpointError(c, "LookupDirectory", err, status, map[string]interface{}{
+			"lookup_size": len(req.Phones),
+		})
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Lookup completed",
+		Data:       gin.H{"results": entries},
+	})
+}
+
+// CreateInviteCode handles minting a new invite code (admin only)
+func (h *Handler) CreateInviteCode(c *gin.Context) {
+	adminID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateInviteCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "CreateInviteCode", err, &req)
+		return
+	}
+
+	invite, err := h.service.CreateInviteCode(c.Request.Context(), adminID, req)
+	if err != nil {
+		logger.LogEndpointError(c, "CreateInviteCode", err, http.StatusBadRequest, map[string]interface{}{
+			"admin_id": adminID,
+		})
+		c.JSON(http.StatusBadRequest, models.Response{
+			Status:     0,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Failed to create invite code",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:     1,
+		StatusCode: http.StatusCreated,
+		Message:    "Invite code created successfully",
+		Data:       invite,
+	})
+}
+
+// ListInviteCodes handles listing all invite codes (admin only)
+func (h *Handler) ListInviteCodes(c *gin.Context) {
+	invites, err := h.service.ListInviteCodes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to load invite codes",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Invite codes loaded successfully",
+		Data:       invites,
+	})
+}
+
+// AdminListContacts handles listing contacts across users (admin only).
+// Soft-deleted contacts are only returned with include_deleted=true.
+func (h *Handler) AdminListContacts(c *gin.Context) {
+	var req models.AdminListContactsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(utils.ValidationStatus(), models.Response{
+			Status:     0,
+			StatusCode: utils.ValidationStatus(),
+			Message:    "Invalid query parameters",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+	var err error
+	if req.Page, req.Limit, err = models.ClampPagination(req.Page, req.Limit, h.maxListLimit); err != nil {
+		invalidListParams(c, err)
+		return
+	}
+
+	// Deleted rows are never exposed outside the admin role, even if routing changes
+	if req.IncludeDeleted && c.GetString("role") != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, models.Response{
+			Status:     0,
+			StatusCode: http.StatusForbidden,
+			Message:    "Insufficient permissions",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	contacts, count, err := h.service.AdminListContacts(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to load contacts",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contacts loaded successfully",
+		Data: gin.H{
+			"count":           count,
+			"page":            req.Page,
+			"limit":           req.Limit,
+			"include_deleted": req.IncludeDeleted,
+			"contacts":        contacts,
+		},
+	})
+}
+
+// invalidListParams answers a list request whose query parameters were
+// rejected, naming the offending parameter when it is known
+func invalidListParams(c *gin.Context, err error) {
+	data := gin.H{"error": err.Error()}
+	var paramsErr *models.ListParamsError
+	if errors.As(err, &paramsErr) && paramsErr.Field != "" {
+		data["field"] = paramsErr.Field
+	}
+	c.JSON(utils.ValidationStatus(), models.Response{
+		Status:     0,
+		StatusCode: utils.ValidationStatus(),
+		Message:    "Invalid query parameters",
+		Data:       data,
+	})
+}
+
+// ListContacts handles getting the contact list with search and pagination
+func (h *Handler) ListContacts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	req, err := models.ParseListContactsRequest(c, h.maxResultWindow, h.maxListLimit)
+	if err != nil {
+		invalidListParams(c, err)
+		return
+	}
+
+	if strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		h.streamContacts(c, userID, req)
+		return
+	}
+
+	// Polling clients get 304 while nothing in the list has changed
+	var etag string
+	if version, err := h.service.ContactsVersion(c.Request.Context(), userID); err == nil {
+		etag = collectionETag(userID, version, c.Request.URL.RawQuery)
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	contacts, count, err := h.service.ListContacts(c.Request.Context(), userID, req)
+	for i := range contacts {
+		formatContactPhone(&contacts[i], req.PhoneFormat)
+	}
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to load contacts")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	data := gin.H{
+		"count":       count,
+		"count_exact": !req.CountCapped,
+		"page":        req.Page,
+		"limit":       req.Limit,
+		"contacts":    contacts,
+	}
+	if req.WithTotal {
+		data["total_all"] = req.TotalAll
+	}
+
+	setPaginationLinks(c, req.Page, req.Limit, count)
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contacts loaded successfully",
+		Data:       data,
+	})
+}
+
+// CheckContactPhone reports whether the user already has a contact with the
+// phone number in the query, without returning the contact
+func (h *Handler) CheckContactPhone(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	exists, err := h.service.ContactPhoneExists(c.Request.Context(), userID, c.Query("phone"))
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to check phone number")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Phone number checked successfully",
+		Data:       gin.H{"exists": exists},
+	})
+}
+
+// ContactFieldsMeta describes the contact fields list requests can sort and filter by
+func (h *Handler) ContactFieldsMeta(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact fields retrieved",
+		Data:       gin.H{"fields": models.ContactFields},
+	})
+}
+
+// ListDuplicates handles getting paginated groups of duplicate contacts
+func (h *Handler) ListDuplicates(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListDuplicatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(utils.ValidationStatus(), models.Response{
+			Status:     0,
+			StatusCode: utils.ValidationStatus(),
+			Message:    "Invalid query parameters",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+	var err error
+	if req.Page, req.Limit, err = models.ClampPagination(req.Page, req.Limit, h.maxListLimit); err != nil {
+		invalidListParams(c, err)
+		return
+	}
+
+	groups, count, err := h.service.ListDuplicates(c.Request.Context(), userID, &req)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to load duplicates")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Duplicates loaded successfully",
+		Data: gin.H{
+			"count":  count,
+			"page":   req.Page,
+			"limit":  req.Limit,
+			"groups": groups,
+		},
+	})
+}
+
+// ndjsonContentType is the Accept value that selects a streamed contact list
+const ndjsonContentType = "application/x-ndjson"
+
+// streamContacts writes the requested page as newline-delimited JSON, one
+// contact per line, encoding each contact as it is read
+func (h *Handler) streamContacts(c *gin.Context, userID uint, req *models.ListContactsRequest) {
+	encoder := json.NewEncoder(c.Writer)
+	started := false
+	err := h.service.StreamContacts(c.Request.Context(), userID, req, func(contact *models.Contact) error {
+		if !started {
+			c.Header("Content-Type", ndjsonContentType)
+			c.Status(http.StatusOK)
+			started = true
+		}
+		formatContactPhone(contact, req.PhoneFormat)
+		return encoder.Encode(contact)
+	})
+	if started {
+		// The status is already sent; a truncated stream is all that can signal the error
+		return
+	}
+
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to load contacts")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	// No contacts: an empty stream
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+}
+
+// CreateContact handles creating a new contact
+func (h *Handler) CreateContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "CreateContact", err, &req)
+		return
+	}
+
+	// Validate optional email format
+	if !utils.ValidateContactEmail(c, req.Email) {
+		return
+	}
+
+	contact, err := h.service.CreateContact(c.Request.Context(), userID, &req)
+	if err != nil {
+		status, message := httpError(err, http.StatusBadRequest, "Failed to create contact")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       contactErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:     1,
+		StatusCode: http.StatusCreated,
+		Message:    "Contact created successfully",
+		Data:       contact,
+	})
+}
+
+// CreateContactsBatch handles creating several contacts from a JSON array
+func (h *Handler) CreateContactsBatch(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Decoded without binding validation so invalid items are reported per item
+	var reqs []models.CreateContactRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&reqs); err != nil {
+		bindFailed(c, "CreateContactsBatch", err, &reqs)
+		return
+	}
+
+	results, err := h.service.CreateContactsBatch(c.Request.Context(), userID, reqs)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to create contacts")
+		logger.LogEndpointError(c, "CreateContactsBatch", err, status, map[string]interface{}{
+			"batch_size": len(reqs),
+		})
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	created := 0
+	for _, result := range results {
+		if result.Error == "" {
+			created++
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Batch processed",
+		Data: gin.H{
+			"created": created,
+			"failed":  len(results) - created,
+			"results": results,
+		},
+	})
+}
+
+// ValidateContact handles a dry-run of contact creation without persisting it
+func (h *Handler) ValidateContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "ValidateContact", err, &req)
+		return
+	}
+
+	// Validate optional email format
+	if !utils.ValidateContactEmail(c, req.Email) {
+		return
+	}
+
+	contact, err := h.service.ValidateContact(c.Request.Context(), userID, &req)
+	if err != nil {
+		status, message := httpError(err, http.StatusBadRequest, "Contact validation failed")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       contactErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact is valid",
+		Data:       contact,
+	})
+}
+
+// TagContactsByQuery handles tagging every contact that matches a filter
+func (h *Handler) TagContactsByQuery(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TagByQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "TagContactsByQuery", err, &req)
+		return
+	}
+
+	tagged, err := h.service.TagContactsByQuery(c.Request.Context(), userID, &req)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to tag contacts")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contacts tagged successfully",
+		Data:       gin.H{"tagged": tagged},
+	})
+}
+
+// MergeContacts handles merging duplicate contacts into a primary contact
+func (h *Handler) MergeContacts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MergeContactsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "MergeContacts", err, &req)
+		return
+	}
+
+	contact, err := h.service.MergeContacts(c.Request.Context(), userID, req.PrimaryID, req.DuplicateIDs)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to merge contacts")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.Header("ETag", contactETag(contact))
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contacts merged successfully",
+		Data:       contact,
+	})
+}
+
+// GetContact handles getting a contact's details
+func (h *Handler) GetContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	format, ok := phoneFormatParam(c)
+	if !ok {
+		return
+	}
+
+	contact, err := h.service.GetContact(c.Request.Context(), userID, contactID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Contact not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+	formatContactPhone(contact, format)
+
+	// Clients send the ETag back in If-Match to delete only this version
+	c.Header("ETag", contactETag(contact))
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact detail loaded",
+		Data:       contact,
+	})
+}
+
+// ExportContactVCard handles downloading a contact as a vCard
+func (h *Handler) ExportContactVCard(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contact, err := h.service.GetContact(c.Request.Context(), userID, contactID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Contact not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="contact-%d.vcf"`, contact.ID))
+	c.Data(http.StatusOK, vcard.ContentType, vcard.Marshal(*contact))
+}
+
+// GetContactQRCode serves a PNG QR code encoding the contact's vCard
+func (h *Handler) GetContactQRCode(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contact, err := h.service.GetContact(c.Request.Context(), userID, contactID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Contact not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	image, err := qrcode.PNG(vcard.Marshal(*contact), h.qrLevel, h.qrSize)
+	if err != nil {
+		logger.LogEndpointError(c, "GetContactQRCode", err, http.StatusUnprocessableEntity, map[string]interface{}{
+			"contact_id": contactID,
+		})
+		c.JSON(http.StatusUnprocessableEntity, models.Response{
+			Status:     0,
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Contact is too large for a QR code",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// UpdateContact handles updating a contact
+func (h *Handler) UpdateContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "UpdateContact", err, &req)
+		return
+	}
+
+	// A sent email must be valid; null or an empty string clears it
+	if !utils.ValidateContactEmail(c, req.Email.Value) {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contact, err := h.service.UpdateContact(c.Request.Context(), userID, contactID, &req)
+	if err != nil {
+		status, message := httpError(err, http.StatusBadRequest, "Failed to update contact")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       contactErrorData(err),
+		})
+		return
+	}
+
+	c.Header("ETag", contactETag(contact))
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact updated successfully",
+		Data:       contact,
+	})
+}
+
+// PatchContact handles partially updating a contact
+func (h *Handler) PatchContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PatchContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "PatchContact", err, &req)
+		return
+	}
+
+	// A sent email must be valid; null or an empty string clears it
+	if !utils.ValidateContactEmail(c, req.Email.Value) {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contact, err := h.service.PatchContact(c.Request.Context(), userID, contactID, req.Updates())
+	if err != nil {
+		status, message := httpError(err, http.StatusBadRequest, "Failed to update contact")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       contactErrorData(err),
+		})
+		return
+	}
+
+	c.Header("ETag", contactETag(contact))
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact updated successfully",
+		Data:       contact,
+	})
+}
+
+// DeleteContact handles deleting a contact
+func (h *Handler) DeleteContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		h.deleteContactIfMatch(c, userID, contactID, ifMatch)
+		return
+	}
+
+	err := h.service.DeleteContact(c.Request.Context(), userID, contactID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Contact not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact deleted successfully",
+		Data:       gin.H{},
+	})
+}
+
+// RestoreContact handles bringing back a deleted contact
+func (h *Handler) RestoreContact(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	contactID, ok := utils.ParseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.service.RestoreContact(c.Request.Context(), userID, contactID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Deleted contact not found")
+		if errors.Is(err, service.ErrContactNotFound) {
+			// Only deleted contacts can be restored
+			message = "Deleted contact not found"
+		}
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Contact restored successfully",
+		Data:       gin.H{},
+	})
+}
+
+// deleteContactIfMatch deletes a contact only while its ETag still matches the
+// client's If-Match header, answering 412 when the contact changed
+func (h *Handler) deleteContactIfMatch(c *gin.Context, userID, contactID uint, ifMatch string) {
+	contact, err := h.service.GetContact(c.Request.Context(), userID, contactID)
+	if err == nil {
+		if !ifMatchMatches(ifMatch, contactETag(contact)) {
+			err = service.ErrContactChanged
+		} else {
+			err = h.service.DeleteContactIfUnchanged(c.Request.Context(), userID, contactID, contact.Version)
+		}
+	}
+
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Contact not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
 		})
 		return
 	}
@@ -409,3 +1507,314 @@ func (h *Handler) DeleteContact(c *gin.Context) {
 		Data:       gin.H{},
 	})
 }
+
+// ImportContacts handles uploading a CSV of contacts to be imported in the background
+func (h *Handler) ImportContacts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(utils.ValidationStatus(), models.Response{
+			Status:     0,
+			StatusCode: utils.ValidationStatus(),
+			Message:    "Invalid request format",
+			Data:       gin.H{"error": "file is required"},
+		})
+		return
+	}
+
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.Response{
+			Status:     0,
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Message:    "Import file too large",
+			Data:       gin.H{"max_bytes": maxImportFileSize},
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Status:     0,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid request format",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Status:     0,
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid request format",
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	job, err := h.service.StartContactImport(c.Request.Context(), userID, data, c.PostForm("dedup"))
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to start import")
+		logger.LogEndpointError(c, "ImportContacts", err, status, map[string]interface{}{
+			"file_name": fileHeader.Filename,
+		})
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.Response{
+		Status:     1,
+		StatusCode: http.StatusAccepted,
+		Message:    "Import started",
+		Data:       job,
+	})
+}
+
+// GetImportJob handles polling the status of a contact import
+func (h *Handler) GetImportJob(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	jobID, ok := utils.ParseIDParamWithMessage(c, "jobId", "Invalid import job ID")
+	if !ok {
+		return
+	}
+
+	job, err := h.service.GetImportJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Import job not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Import job loaded",
+		Data:       job,
+	})
+}
+
+// ListGroups handles listing the user's contact groups
+func (h *Handler) ListGroups(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	groups, err := h.service.ListGroups(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to load groups",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Groups loaded successfully",
+		Data:       groups,
+	})
+}
+
+// CreateGroup handles creating a contact group
+func (h *Handler) CreateGroup(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ContactGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "CreateGroup", err, &req)
+		return
+	}
+
+	group, err := h.service.CreateGroup(c.Request.Context(), userID, req)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to create group")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       errorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:     1,
+		StatusCode: http.StatusCreated,
+		Message:    "Group created successfully",
+		Data:       group,
+	})
+}
+
+// GetGroup handles getting a contact group
+func (h *Handler) GetGroup(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, ok := utils.ParseIDParamWithMessage(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	group, err := h.service.GetGroup(c.Request.Context(), userID, groupID)
+	if err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Group not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Group loaded",
+		Data:       group,
+	})
+}
+
+// RenameGroup handles renaming a contact group
+func (h *Handler) RenameGroup(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, ok := utils.ParseIDParamWithMessage(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.ContactGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindFailed(c, "RenameGroup", err, &req)
+		return
+	}
+
+	group, err := h.service.RenameGroup(c.Request.Context(), userID, groupID, req)
+	if err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to rename group")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       errorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Group renamed successfully",
+		Data:       group,
+	})
+}
+
+// DeleteGroup handles deleting a contact group; its contacts are kept
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, ok := utils.ParseIDParamWithMessage(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteGroup(c.Request.Context(), userID, groupID); err != nil {
+		status, message := httpError(err, http.StatusNotFound, "Group not found")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Group deleted successfully",
+		Data:       gin.H{},
+	})
+}
+
+// AddContactToGroup handles putting a contact in a group
+func (h *Handler) AddContactToGroup(c *gin.Context) {
+	h.changeGroupMember(c, h.service.AddContactToGroup, "Contact added to group")
+}
+
+// RemoveContactFromGroup handles taking a contact out of a group
+func (h *Handler) RemoveContactFromGroup(c *gin.Context) {
+	h.changeGroupMember(c, h.service.RemoveContactFromGroup, "Contact removed from group")
+}
+
+// changeGroupMember applies a membership change to the group and contact in the path
+func (h *Handler) changeGroupMember(c *gin.Context, change func(ctx context.Context, userID, groupID, contactID uint) error, message string) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, ok := utils.ParseIDParamWithMessage(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+	contactID, ok := utils.ParseIDParamWithMessage(c, "contactId", "Invalid contact ID")
+	if !ok {
+		return
+	}
+
+	if err := change(c.Request.Context(), userID, groupID, contactID); err != nil {
+		status, message := httpError(err, http.StatusInternalServerError, "Failed to update group")
+		c.JSON(status, models.Response{
+			Status:     0,
+			StatusCode: status,
+			Message:    message,
+			Data:       publicErrorData(err, status),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Data:       gin.H{"group_id": groupID, "contact_id": contactID},
+	})
+}
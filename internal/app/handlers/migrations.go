@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationReporter reports which database migrations are applied
+type MigrationReporter interface {
+	Report() (*migrations.StatusReport, error)
+}
+
+// WithMigrations enables the admin migration status endpoint
+func WithMigrations(reporter MigrationReporter) Option {
+	return func(h *Handler) {
+		h.migrations = reporter
+	}
+}
+
+// MigrationStatus lists the applied and pending database migrations (admin
+// only), so a deploy can be checked against the schema it expects
+func (h *Handler) MigrationStatus(c *gin.Context) {
+	if h.migrations == nil {
+		c.JSON(http.StatusServiceUnavailable, models.Response{
+			Status:     0,
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "Migration status is not configured",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	report, err := h.migrations.Report()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Status:     0,
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Failed to load migration status",
+			Data:       gin.H{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:     1,
+		StatusCode: http.StatusOK,
+		Message:    "Migration status loaded successfully",
+		Data:       report,
+	})
+}
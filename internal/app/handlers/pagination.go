@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPaginationLinks adds an RFC 5988 Link header with first, prev, next and last
+// page URLs built from the current request path and query
+func setPaginationLinks(c *gin.Context, page, limit int, count int64) {
+	if limit < 1 {
+		return
+	}
+
+	lastPage := int((count + int64(limit) - 1) / int64(limit))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageURL := func(p int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		query.Set("limit", strconv.Itoa(limit))
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		return u.String()
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+	if page > 1 {
+		prev := page - 1
+		if prev > lastPage {
+			prev = lastPage
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(prev)))
+	}
+	if page < lastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
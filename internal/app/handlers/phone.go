@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"user-service/internal/app/models"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// phoneFormatParam reads the optional phone_format query parameter, answering
+// with a validation error when the format is unknown
+func phoneFormatParam(c *gin.Context) (string, bool) {
+	format := c.Query("phone_format")
+	if format != "" && !utils.ValidPhoneFormat(format) {
+		c.JSON(utils.ValidationStatus(), models.Response{
+			Status:     0,
+			StatusCode: utils.ValidationStatus(),
+			Message:    "Invalid query parameters",
+			Data: gin.H{
+				"error": "phone_format must be e164, national or international",
+				"field": "phone_format",
+			},
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// formatContactPhone renders the contact's phone in the requested format; an
+// empty format keeps the number as stored
+func formatContactPhone(contact *models.Contact, format string) {
+	if format != "" {
+		contact.Phone = utils.FormatPhone(contact.Phone, format)
+	}
+}
@@ -0,0 +1,336 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner queues background tasks so tests decide when they run
+type fakeRunner struct {
+	tasks []func()
+}
+
+func (r *fakeRunner) Go(task func()) {
+	r.tasks = append(r.tasks, task)
+}
+
+func (r *fakeRunner) RunAll() {
+	tasks := r.tasks
+	r.tasks = nil
+	for _, task := range tasks {
+		task()
+	}
+}
+
+// progressRepository reads the import job back after every update, recording
+// what a client polling the job would have seen
+type progressRepository struct {
+	repository.Repository
+	userID uint
+	seen   []models.ImportJob
+}
+
+func (r *progressRepository) UpdateImportJob(ctx context.Context, jobID uint, updates map[string]interface{}) error {
+	if err := r.Repository.UpdateImportJob(ctx, jobID, updates); err != nil {
+		return err
+	}
+	job, err := r.Repository.GetImportJob(ctx, r.userID, jobID)
+	if err != nil {
+		return err
+	}
+	r.seen = append(r.seen, *job)
+	return nil
+}
+
+func TestService_ContactImport(t *testing.T) {
+	setup := func(t *testing.T) (service.Service, *fakeRunner, *models.User, func()) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		user, err := CreateTestUser(context.Background(), repo)
+		require.NoError(t, err)
+
+		runner := &fakeRunner{}
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithRunner(runner))
+		return svc, runner, user, cleanup
+	}
+
+	t.Run("job moves from pending to done with counts", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		csvData := []byte("full_name,phone,email\n" +
+			"Alice,1111111111,alice@example.com\n" +
+			"Bob,2222222222,\n" +
+			"Alice Again,1111111111,\n" +
+			",3333333333,\n")
+
+		job, err := svc.StartContactImport(ctx, user.ID, csvData, "")
+		require.NoError(t, err)
+		assert.Equal(t, models.ImportStatusPending, job.Status)
+		assert.Equal(t, 4, job.Total)
+		require.Len(t, runner.tasks, 1)
+
+		pending, err := svc.GetImportJob(ctx, user.ID, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ImportStatusPending, pending.Status)
+
+		runner.RunAll()
+
+		done, err := svc.GetImportJob(ctx, user.ID, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ImportStatusDone, done.Status)
+		assert.Equal(t, 2, done.Imported)
+		assert.Equal(t, 1, done.Skipped)
+		assert.Equal(t, 1, done.Failed)
+
+		contacts, total, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, contacts, 2)
+	})
+
+	t.Run("rows are validated like batch creates", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		csvData := []byte("full_name,phone,email\n" +
+			"Alice,1111111111,alice@example.com\n" +
+			"Bob,2222222222,not-an-email\n" +
+			"Carol,12,\n")
+
+		job, err := svc.StartContactImport(ctx, user.ID, csvData, "")
+		require.NoError(t, err)
+		runner.RunAll()
+
+		done, err := svc.GetImportJob(ctx, user.ID, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, done.Imported)
+		assert.Equal(t, 2, done.Failed)
+
+		_, total, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("counts are written while the import runs", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		ctx := context.Background()
+		user, err := CreateTestUser(ctx, repo)
+		require.NoError(t, err)
+
+		progress := &progressRepository{Repository: repo, userID: user.ID}
+		runner := &fakeRunner{}
+		svc := service.NewService(progress, token.NewService(GetTestJWTSecret()), service.WithRunner(runner))
+
+		var csvData bytes.Buffer
+		csvData.WriteString("full_name,phone\n")
+		for i := 0; i < 250; i++ {
+			fmt.Fprintf(&csvData, "Contact %d,1555%07d\n", i, i)
+		}
+
+		job, err := svc.StartContactImport(ctx, user.ID, csvData.Bytes(), "")
+		require.NoError(t, err)
+		runner.RunAll()
+
+		// Running, then after each 100 rows, then done
+		require.Len(t, progress.seen, 4)
+		for i, want := range []int{0, 100, 200, 250} {
+			assert.Equal(t, job.ID, progress.seen[i].ID)
+			assert.Equal(t, want, progress.seen[i].Imported)
+		}
+		assert.Equal(t, models.ImportStatusRunning, progress.seen[2].Status)
+		assert.Equal(t, models.ImportStatusDone, progress.seen[3].Status)
+	})
+
+	t.Run("invalid file is rejected without creating a job", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := svc.StartContactImport(context.Background(), user.ID, []byte("name,number\nAlice,1\n"), "")
+
+		assert.Equal(t, service.ErrInvalidImportFile, err)
+		assert.Empty(t, runner.tasks)
+	})
+
+	t.Run("fuzzy dedup flags near-duplicate rows for review", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		existing, err := svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{FullName: "John Smith", Phone: "1111111111"})
+		require.NoError(t, err)
+
+		csvData := []byte("full_name,phone\n" +
+			"Jon Smith,1111111112\n" +
+			"Alice Brown,2222222222\n" +
+			"Alice  Browne,2222222223\n" +
+			"Bob Stone,1111111113\n" +
+			"John Smith,1111111111\n")
+
+		job, err := svc.StartContactImport(ctx, user.ID, csvData, models.ImportDedupFuzzy)
+		require.NoError(t, err)
+		runner.RunAll()
+
+		done, err := svc.GetImportJob(ctx, user.ID, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ImportStatusDone, done.Status)
+		assert.Equal(t, 2, done.Imported)
+		assert.Equal(t, 1, done.Skipped)
+		assert.Equal(t, 2, done.Flagged)
+		require.Len(t, done.Duplicates, 2)
+
+		assert.Equal(t, 1, done.Duplicates[0].Row)
+		assert.Equal(t, "Jon Smith", done.Duplicates[0].FullName)
+		assert.Equal(t, existing.ID, done.Duplicates[0].MatchID)
+		assert.Zero(t, done.Duplicates[0].MatchRow)
+		assert.Equal(t, "John Smith", done.Duplicates[0].MatchName)
+
+		assert.Equal(t, 3, done.Duplicates[1].Row)
+		assert.Equal(t, "Alice Browne", done.Duplicates[1].FullName)
+		assert.Equal(t, 2, done.Duplicates[1].MatchRow)
+		assert.Equal(t, "Alice Brown", done.Duplicates[1].MatchName)
+
+		_, total, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+	})
+
+	t.Run("exact dedup creates near-duplicate rows", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		_, err := svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{FullName: "John Smith", Phone: "1111111111"})
+		require.NoError(t, err)
+
+		job, err := svc.StartContactImport(ctx, user.ID, []byte("full_name,phone\nJon Smith,1111111112\n"), models.ImportDedupExact)
+		require.NoError(t, err)
+		runner.RunAll()
+
+		done, err := svc.GetImportJob(ctx, user.ID, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, done.Imported)
+		assert.Zero(t, done.Flagged)
+		assert.Empty(t, done.Duplicates)
+	})
+
+	t.Run("unknown dedup mode is rejected", func(t *testing.T) {
+		svc, runner, user, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := svc.StartContactImport(context.Background(), user.ID, []byte("full_name,phone\nAlice,1111111111\n"), "phonetic")
+
+		assert.Equal(t, service.ErrInvalidDedupMode, err)
+		assert.Empty(t, runner.tasks)
+	})
+
+	t.Run("jobs are scoped to their owner", func(t *testing.T) {
+		svc, _, user, cleanup := setup(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		job, err := svc.StartContactImport(ctx, user.ID, []byte("full_name,phone\nAlice,1111111111\n"), "")
+		require.NoError(t, err)
+
+		_, err = svc.GetImportJob(ctx, user.ID+1, job.ID)
+		assert.Equal(t, service.ErrImportJobNotFound, err)
+	})
+}
+
+func TestHandler_ImportContacts(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	t.Run("accepts a file and returns the job", func(t *testing.T) {
+		csvData := []byte("full_name,phone\nAlice,1111111111\n")
+		mockService.On("StartContactImport", mock.Anything, uint(1), csvData, "").
+			Return(&models.ImportJob{ID: 9, Status: models.ImportStatusPending, Total: 1}, nil).Once()
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "contacts.csv")
+		_, _ = part.Write(csvData)
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/import", body)
+		httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, float64(9), data["id"])
+		assert.Equal(t, models.ImportStatusPending, data["status"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("dedup mode is passed from the form", func(t *testing.T) {
+		csvData := []byte("full_name,phone\nAlice,1111111111\n")
+		mockService.On("StartContactImport", mock.Anything, uint(1), csvData, models.ImportDedupFuzzy).
+			Return(&models.ImportJob{ID: 10, Status: models.ImportStatusPending, Total: 1}, nil).Once()
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "contacts.csv")
+		_, _ = part.Write(csvData)
+		_ = writer.WriteField("dedup", models.ImportDedupFuzzy)
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/import", body)
+		httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/contacts/import", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("poll job status", func(t *testing.T) {
+		mockService.On("GetImportJob", mock.Anything, uint(1), uint(9)).
+			Return(&models.ImportJob{ID: 9, Status: models.ImportStatusDone, Total: 1, Imported: 1}, nil).Once()
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/contacts/import/9", nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, models.ImportStatusDone, data["status"])
+		assert.Equal(t, float64(1), data["imported"])
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+	"user-service/internal/app/jobs"
+	"user-service/internal/app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recordingNotifier records the users it was asked to warn
+type recordingNotifier struct {
+	warned []uint
+}
+
+func (n *recordingNotifier) NotifyInactivity(ctx context.Context, user models.User, actionAt time.Time) error {
+	n.warned = append(n.warned, user.ID)
+	return nil
+}
+
+// seedInactivityUser creates a user with the given activity timestamps
+func seedInactivityUser(t *testing.T, db *gorm.DB, email, role string, createdAt time.Time, lastLogin, warnedAt *time.Time) *models.User {
+	t.Helper()
+	user := &models.User{
+		FullName:           email,
+		Email:              email,
+		Password:           "hashedpassword",
+		Role:               role,
+		CreatedAt:          createdAt,
+		LastLoginAt:        lastLogin,
+		InactivityWarnedAt: warnedAt,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestInactivityPurger_RunOnce(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	cfg := jobs.InactivityConfig{
+		Threshold:     90 * day,
+		WarningPeriod: 7 * day,
+		Action:        jobs.ActionFlag,
+		Interval:      day,
+	}
+
+	t.Run("warns then flags inactive non-admin accounts", func(t *testing.T) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		longAgo := now.Add(-400 * day)
+		recent := seedInactivityUser(t, testDB.DB, "recent@example.com", models.RoleUser, longAgo, timePtr(now.Add(-5*day)), nil)
+		newSignup := seedInactivityUser(t, testDB.DB, "new@example.com", models.RoleUser, now.Add(-10*day), nil, nil)
+		stale := seedInactivityUser(t, testDB.DB, "stale@example.com", models.RoleUser, longAgo, timePtr(now.Add(-120*day)), nil)
+		neverLoggedIn := seedInactivityUser(t, testDB.DB, "never@example.com", models.RoleUser, longAgo, nil, nil)
+		warnedExpired := seedInactivityUser(t, testDB.DB, "expired@example.com", models.RoleUser, longAgo, timePtr(now.Add(-200*day)), timePtr(now.Add(-10*day)))
+		warnedPending := seedInactivityUser(t, testDB.DB, "pending@example.com", models.RoleUser, longAgo, timePtr(now.Add(-200*day)), timePtr(now.Add(-2*day)))
+		admin := seedInactivityUser(t, testDB.DB, "admin@example.com", models.RoleAdmin, longAgo, timePtr(now.Add(-300*day)), timePtr(now.Add(-30*day)))
+
+		notifier := &recordingNotifier{}
+		purger, err := jobs.NewInactivityPurger(repo, cfg, notifier)
+		require.NoError(t, err)
+
+		result, err := purger.RunOnce(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.InactivityResult{Warned: 2, Flagged: 1}, result)
+		assert.ElementsMatch(t, []uint{stale.ID, neverLoggedIn.ID}, notifier.warned)
+
+		reload := func(id uint) *models.User {
+			user, err := repo.GetUserByID(ctx, id)
+			require.NoError(t, err)
+			return user
+		}
+
+		assert.Nil(t, reload(recent.ID).InactivityWarnedAt)
+		assert.Nil(t, reload(recent.ID).FlaggedInactiveAt)
+		assert.Nil(t, reload(newSignup.ID).InactivityWarnedAt)
+		assert.NotNil(t, reload(stale.ID).InactivityWarnedAt)
+		assert.Nil(t, reload(stale.ID).FlaggedInactiveAt)
+		assert.NotNil(t, reload(warnedExpired.ID).FlaggedInactiveAt)
+		assert.Nil(t, reload(warnedPending.ID).FlaggedInactiveAt)
+		assert.Nil(t, reload(admin.ID).FlaggedInactiveAt)
+	})
+
+	t.Run("deletes inactive accounts when configured", func(t *testing.T) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		longAgo := now.Add(-400 * day)
+		expired := seedInactivityUser(t, testDB.DB, "expired@example.com", models.RoleUser, longAgo, timePtr(now.Add(-200*day)), timePtr(now.Add(-10*day)))
+		active := seedInactivityUser(t, testDB.DB, "active@example.com", models.RoleUser, longAgo, timePtr(now.Add(-1*day)), timePtr(now.Add(-10*day)))
+		admin := seedInactivityUser(t, testDB.DB, "admin@example.com", models.RoleAdmin, longAgo, nil, timePtr(now.Add(-30*day)))
+		_, err := CreateTestContact(ctx, repo, expired.ID)
+		require.NoError(t, err)
+
+		deleteCfg := cfg
+		deleteCfg.Action = jobs.ActionDelete
+		purger, err := jobs.NewInactivityPurger(repo, deleteCfg, &recordingNotifier{})
+		require.NoError(t, err)
+
+		result, err := purger.RunOnce(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.InactivityResult{Deleted: 1}, result)
+
+		_, err = repo.GetUserByID(ctx, expired.ID)
+		assert.Error(t, err)
+		_, err = repo.GetUserByID(ctx, active.ID)
+		assert.NoError(t, err)
+		_, err = repo.GetUserByID(ctx, admin.ID)
+		assert.NoError(t, err)
+
+		var contacts int64
+		require.NoError(t, testDB.DB.Model(&models.Contact{}).Where("user_id = ?", expired.ID).Count(&contacts).Error)
+		assert.Zero(t, contacts)
+	})
+
+	t.Run("login clears a pending warning", func(t *testing.T) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+		ctx := context.Background()
+
+		user := seedInactivityUser(t, testDB.DB, "back@example.com", models.RoleUser, now.Add(-400*day), timePtr(now.Add(-200*day)), timePtr(now.Add(-10*day)))
+
+		require.NoError(t, repo.UpdateLastLogin(ctx, user.ID, now))
+
+		purger, err := jobs.NewInactivityPurger(repo, cfg, &recordingNotifier{})
+		require.NoError(t, err)
+		result, err := purger.RunOnce(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.InactivityResult{}, result)
+
+		reloaded, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, reloaded.InactivityWarnedAt)
+	})
+}
+
+func TestNewInactivityPurger_RejectsUnsafeSettings(t *testing.T) {
+	day := 24 * time.Hour
+	valid := jobs.InactivityConfig{Threshold: 90 * day, WarningPeriod: 7 * day, Action: jobs.ActionDelete, Interval: day}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*jobs.InactivityConfig)
+		want   string
+	}{
+		{"unknown action", func(c *jobs.InactivityConfig) { c.Action = "purge" }, "unknown inactivity action: purge"},
+		{"zero threshold", func(c *jobs.InactivityConfig) { c.Threshold = 0 }, "threshold must be positive"},
+		{"negative threshold", func(c *jobs.InactivityConfig) { c.Threshold = -day }, "threshold must be positive"},
+		{"zero interval", func(c *jobs.InactivityConfig) { c.Interval = 0 }, "interval must be positive"},
+		{"negative interval", func(c *jobs.InactivityConfig) { c.Interval = -time.Minute }, "interval must be positive"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := valid
+			tc.modify(&cfg)
+
+			purger, err := jobs.NewInactivityPurger(nil, cfg, nil)
+
+			assert.ErrorContains(t, err, tc.want)
+			assert.Nil(t, purger)
+		})
+	}
+
+	purger, err := jobs.NewInactivityPurger(nil, valid, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, purger)
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerConcurrently fires n registrations with the same invite code and returns their errors
+func registerConcurrently(svc service.Service, code string, n int) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = svc.Register(context.Background(), models.RegisterRequest{
+				FullName:   fmt.Sprintf("Invitee %d", i),
+				Email:      fmt.Sprintf("invitee%d@example.com", i),
+				Password:   "password123",
+				InviteCode: code,
+			})
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestInviteCodes_Register(t *testing.T) {
+	setup := func(t *testing.T) (*TestDB, service.Service, *models.User, func()) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		// SQLite in-memory databases serialise writers; one connection avoids lock errors
+		testDB.SqlDB.SetMaxOpenConns(1)
+
+		admin, err := CreateTestUser(context.Background(), repo)
+		require.NoError(t, err)
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()),
+			service.WithRegistration(false, nil),
+		)
+		return testDB, svc, admin, cleanup
+	}
+
+	t.Run("valid code is consumed exactly as many times as it has uses", func(t *testing.T) {
+		testDB, svc, admin, cleanup := setup(t)
+		defer cleanup()
+
+		invite, err := svc.CreateInviteCode(context.Background(), admin.ID, models.CreateInviteCodeRequest{
+			Code: "BETA-VALID",
+			Uses: 3,
+		})
+		require.NoError(t, err)
+
+		errs := registerConcurrently(svc, invite.Code, 8)
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.Equal(t, service.ErrInviteCodeUsedUp, err)
+		}
+		assert.Equal(t, 3, succeeded)
+
+		var stored models.InviteCode
+		require.NoError(t, testDB.DB.Where("code = ?", invite.Code).First(&stored).Error)
+		assert.Equal(t, 0, stored.UsesRemaining)
+
+		var users int64
+		require.NoError(t, testDB.DB.Model(&models.User{}).Where("email LIKE ?", "invitee%").Count(&users).Error)
+		assert.Equal(t, int64(3), users)
+	})
+
+	t.Run("exhausted code is rejected", func(t *testing.T) {
+		testDB, svc, admin, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, testDB.DB.Create(&models.InviteCode{
+			Code:          "BETA-EXHAUSTED",
+			CreatedBy:     admin.ID,
+			UsesRemaining: 0,
+		}).Error)
+
+		for _, err := range registerConcurrently(svc, "BETA-EXHAUSTED", 4) {
+			assert.Equal(t, service.ErrInviteCodeUsedUp, err)
+		}
+	})
+
+	t.Run("expired code is rejected", func(t *testing.T) {
+		testDB, svc, admin, cleanup := setup(t)
+		defer cleanup()
+
+		expiredAt := time.Now().Add(-time.Hour)
+		require.NoError(t, testDB.DB.Create(&models.InviteCode{
+			Code:          "BETA-EXPIRED",
+			CreatedBy:     admin.ID,
+			UsesRemaining: 5,
+			ExpiresAt:     &expiredAt,
+		}).Error)
+
+		for _, err := range registerConcurrently(svc, "BETA-EXPIRED", 4) {
+			assert.Equal(t, service.ErrInviteCodeExpired, err)
+		}
+
+		var stored models.InviteCode
+		require.NoError(t, testDB.DB.Where("code = ?", "BETA-EXPIRED").First(&stored).Error)
+		assert.Equal(t, 5, stored.UsesRemaining)
+	})
+
+	t.Run("unknown code is rejected", func(t *testing.T) {
+		_, svc, _, cleanup := setup(t)
+		defer cleanup()
+
+		_, _, err := svc.Register(context.Background(), models.RegisterRequest{
+			FullName:   "Nobody",
+			Email:      "nobody@example.com",
+			Password:   "password123",
+			InviteCode: "DOES-NOT-EXIST",
+		})
+		assert.Equal(t, service.ErrInviteCodeInvalid, err)
+	})
+
+	t.Run("failed registration gives the use back", func(t *testing.T) {
+		testDB, svc, admin, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := testDB.SqlDB.Exec("CREATE UNIQUE INDEX " + migrations.UniqueUserPhoneIndex + " ON users (phone)")
+		require.NoError(t, err)
+		taken := "5551234567"
+		require.NoError(t, testDB.DB.Model(admin).Update("phone", taken).Error)
+
+		invite, err := svc.CreateInviteCode(context.Background(), admin.ID, models.CreateInviteCodeRequest{Code: "BETA-ONCE", Uses: 1})
+		require.NoError(t, err)
+
+		// Only the database constraint catches the phone, after the code was redeemed
+		_, _, err = svc.Register(context.Background(), models.RegisterRequest{
+			FullName:   "Clash",
+			Email:      "clash@example.com",
+			Phone:      stringPtr(taken),
+			Password:   "password123",
+			InviteCode: invite.Code,
+		})
+		assert.Equal(t, service.ErrPhoneTaken, err)
+
+		var stored models.InviteCode
+		require.NoError(t, testDB.DB.Where("code = ?", invite.Code).First(&stored).Error)
+		assert.Equal(t, 1, stored.UsesRemaining)
+
+		_, _, err = svc.Register(context.Background(), models.RegisterRequest{
+			FullName:   "Invitee",
+			Email:      "invitee@example.com",
+			Password:   "password123",
+			InviteCode: invite.Code,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("generated codes are unique", func(t *testing.T) {
+		_, svc, admin, cleanup := setup(t)
+		defer cleanup()
+
+		first, err := svc.CreateInviteCode(context.Background(), admin.ID, models.CreateInviteCodeRequest{Uses: 1, ExpiresInHours: 24})
+		require.NoError(t, err)
+		second, err := svc.CreateInviteCode(context.Background(), admin.ID, models.CreateInviteCodeRequest{Uses: 1})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, first.Code)
+		assert.NotEqual(t, first.Code, second.Code)
+		require.NotNil(t, first.ExpiresAt)
+		assert.True(t, first.ExpiresAt.After(time.Now()))
+		assert.Nil(t, second.ExpiresAt)
+	})
+}
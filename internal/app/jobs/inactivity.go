@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/logger"
+)
+
+// Inactivity actions
+const (
+	ActionFlag   = "flag"
+	ActionDelete = "delete"
+)
+
+// InactivityConfig holds the settings for the inactivity purge job
+type InactivityConfig struct {
+	Threshold     time.Duration
+	WarningPeriod time.Duration
+	Action        string
+	Interval      time.Duration
+}
+
+// InactivityResult summarizes a single run of the inactivity purge job
+type InactivityResult struct {
+	Warned  int `json:"warned"`
+	Flagged int `json:"flagged"`
+	Deleted int `json:"deleted"`
+}
+
+// Notifier sends the warning that precedes an inactivity action
+type Notifier interface {
+	NotifyInactivity(ctx context.Context, user models.User, actionAt time.Time) error
+}
+
+// LogNotifier writes inactivity warnings to the application log
+type LogNotifier struct{}
+
+// NotifyInactivity logs the inactivity warning
+func (LogNotifier) NotifyInactivity(ctx context.Context, user models.User, actionAt time.Time) error {
+	logger.Warn("Account inactivity warning", map[string]interface{}{
+		"job":       "inactivity_purge",
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"action_at": actionAt.Format(time.RFC3339),
+	})
+	return nil
+}
+
+// WebhookNotifier posts inactivity warnings to an HTTP endpoint
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier for the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NotifyInactivity posts the inactivity warning as JSON
+func (n *WebhookNotifier) NotifyInactivity(ctx context.Context, user models.User, actionAt time.Time) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":     "account_inactivity_warning",
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"action_at": actionAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("inactivity webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// InactivityPurger warns, then flags or deletes, accounts with no recent login
+type InactivityPurger struct {
+	repo     repository.Repository
+	cfg      InactivityConfig
+	notifier Notifier
+}
+
+// NewInactivityPurger creates a new inactivity purge job. It rejects settings
+// that would stop the job or act on every account: an unknown action, and a
+// threshold or interval that is not positive.
+func NewInactivityPurger(repo repository.Repository, cfg InactivityConfig, notifier Notifier) (*InactivityPurger, error) {
+	if cfg.Action != ActionFlag && cfg.Action != ActionDelete {
+		return nil, fmt.Errorf("unknown inactivity action: %s", cfg.Action)
+	}
+	if cfg.Threshold <= 0 {
+		return nil, fmt.Errorf("inactivity threshold must be positive, got %s", cfg.Threshold)
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("inactivity check interval must be positive, got %s", cfg.Interval)
+	}
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &InactivityPurger{
+		repo:     repo,
+		cfg:      cfg,
+		notifier: notifier,
+	}, nil
+}
+
+// RunOnce processes all inactive accounts as of now. Accounts that have not been
+// warned since their last activity are warned first; the action is only applied
+// once the warning period has elapsed.
+func (p *InactivityPurger) RunOnce(ctx context.Context, now time.Time) (InactivityResult, error) {
+	var result InactivityResult
+
+	users, err := p.repo.ListInactiveUsers(ctx, now.Add(-p.cfg.Threshold))
+	if err != nil {
+		return result, err
+	}
+
+	for _, user := range users {
+		// Never touch admin accounts, even if the query is changed
+		if user.Role == models.RoleAdmin {
+			continue
+		}
+
+		lastActive := user.CreatedAt
+		if user.LastLoginAt != nil {
+			lastActive = *user.LastLoginAt
+		}
+
+		if user.InactivityWarnedAt == nil || user.InactivityWarnedAt.Before(lastActive) {
+			if err := p.notifier.NotifyInactivity(ctx, user, now.Add(p.cfg.WarningPeriod)); err != nil {
+				logger.Error(err, map[string]interface{}{
+					"job":     "inactivity_purge",
+					"user_id": user.ID,
+				})
+				continue
+			}
+			if err := p.repo.MarkInactivityWarned(ctx, user.ID, now); err != nil {
+				return result, err
+			}
+			result.Warned++
+			continue
+		}
+
+		if now.Sub(*user.InactivityWarnedAt) < p.cfg.WarningPeriod {
+			continue
+		}
+
+		switch p.cfg.Action {
+		case ActionDelete:
+			if err := p.repo.DeleteUser(ctx, user.ID); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		default:
+			if err := p.repo.FlagUserInactive(ctx, user.ID, now); err != nil {
+				return result, err
+			}
+			result.Flagged++
+		}
+	}
+
+	return result, nil
+}
+
+// Start runs the job on the configured interval until the context is cancelled
+func (p *InactivityPurger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				result, err := p.RunOnce(ctx, now)
+				if err != nil {
+					logger.Error(err, map[string]interface{}{
+						"job": "inactivity_purge",
+					})
+					continue
+				}
+				logger.Info("Inactivity purge completed", map[string]interface{}{
+					"job":     "inactivity_purge",
+					"warned":  result.Warned,
+					"flagged": result.Flagged,
+					"deleted": result.Deleted,
+				})
+			}
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestService_LoginLockout(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := TestUser()
+	user.Password = string(hashed)
+	user, err = repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithLoginLockout(3, time.Minute))
+	login := func(password string) error {
+		_, err := svc.Login(ctx, models.LoginRequest{Email: user.Email, Password: password})
+		return err
+	}
+	stored := func() models.User {
+		var u models.User
+		require.NoError(t, tdb.DB.First(&u, user.ID).Error)
+		return u
+	}
+
+	t.Run("successful login resets the count", func(t *testing.T) {
+		require.Error(t, login("wrong"))
+		require.Error(t, login("wrong"))
+		assert.Equal(t, 2, stored().FailedLoginAttempts)
+
+		require.NoError(t, login("password123"))
+		assert.Equal(t, 0, stored().FailedLoginAttempts)
+	})
+
+	t.Run("locks after repeated failures", func(t *testing.T) {
+		require.Error(t, login("wrong"))
+		err := login("wrong")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, service.ErrAccountLocked)
+
+		assert.ErrorIs(t, login("wrong"), service.ErrAccountLocked)
+		locked := stored()
+		require.NotNil(t, locked.LockedUntil)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *locked.LockedUntil, 5*time.Second)
+	})
+
+	t.Run("locked account rejects the right password", func(t *testing.T) {
+		assert.ErrorIs(t, login("password123"), service.ErrAccountLocked)
+	})
+
+	t.Run("unlocks once the lock expires", func(t *testing.T) {
+		require.NoError(t, tdb.DB.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("locked_until", time.Now().Add(-time.Second)).Error)
+
+		require.NoError(t, login("password123"))
+		assert.Nil(t, stored().LockedUntil)
+	})
+
+	t.Run("disabled without attempts", func(t *testing.T) {
+		unlimited := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+		for i := 0; i < 5; i++ {
+			_, err := unlimited.Login(ctx, models.LoginRequest{Email: user.Email, Password: "wrong"})
+			assert.NotErrorIs(t, err, service.ErrAccountLocked)
+		}
+		assert.Equal(t, 0, stored().FailedLoginAttempts)
+	})
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_MergeContacts(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	other, err := repo.CreateUser(ctx, &models.User{FullName: "Other User", Email: "other@example.com", Password: "hashedpassword"})
+	require.NoError(t, err)
+
+	str := func(s string) *string { return &s }
+	newContact := func(contact models.Contact) *models.Contact {
+		created, err := repo.CreateContact(ctx, &contact)
+		require.NoError(t, err)
+		return created
+	}
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+	t.Run("fills empty fields from the duplicates in order", func(t *testing.T) {
+		primary := newContact(models.Contact{UserID: user.ID, FullName: "John Doe", Phone: "14155550001", Company: str("Acme")})
+		first := newContact(models.Contact{UserID: user.ID, FullName: "Johnny", Phone: "14155550002",
+			Email: str("john@example.com"), Company: str("Other Corp"), Favorite: true})
+		second := newContact(models.Contact{UserID: user.ID, FullName: "J. Doe", Phone: "14155550003",
+			Email: str("jd@example.com"), JobTitle: str("Engineer"), AvatarURL: str("")})
+
+		merged, err := svc.MergeContacts(ctx, user.ID, primary.ID, []uint{first.ID, second.ID})
+		require.NoError(t, err)
+
+		// The primary keeps its own values and gains the ones it lacked
+		assert.Equal(t, "John Doe", merged.FullName)
+		assert.Equal(t, "14155550001", merged.Phone)
+		assert.Equal(t, "Acme", *merged.Company)
+		assert.Equal(t, "john@example.com", *merged.Email)
+		assert.Equal(t, "Engineer", *merged.JobTitle)
+		assert.Nil(t, merged.AvatarURL)
+		assert.True(t, merged.Favorite)
+		assert.False(t, merged.Blocked)
+
+		stored, err := repo.GetContact(ctx, user.ID, primary.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", *stored.Email)
+		assert.Equal(t, "Engineer", *stored.JobTitle)
+		assert.True(t, stored.Favorite)
+
+		for _, id := range []uint{first.ID, second.ID} {
+			_, err := svc.GetContact(ctx, user.ID, id)
+			assert.ErrorIs(t, err, service.ErrContactNotFound)
+		}
+	})
+
+	t.Run("moves tags and groups to the primary", func(t *testing.T) {
+		primary := newContact(models.Contact{UserID: user.ID, FullName: "Ann", Phone: "14155550011"})
+		duplicate := newContact(models.Contact{UserID: user.ID, FullName: "Ann B", Phone: "14155550012", Blocked: true})
+		require.NoError(t, tdb.DB.Create(&[]models.ContactTag{
+			{ContactID: primary.ID, Tag: "work"},
+			{ContactID: duplicate.ID, Tag: "work"},
+			{ContactID: duplicate.ID, Tag: "vip"},
+		}).Error)
+		group, err := svc.CreateGroup(ctx, user.ID, models.ContactGroupRequest{Name: "Friends"})
+		require.NoError(t, err)
+		require.NoError(t, svc.AddContactToGroup(ctx, user.ID, group.ID, duplicate.ID))
+
+		merged, err := svc.MergeContacts(ctx, user.ID, primary.ID, []uint{duplicate.ID, duplicate.ID})
+		require.NoError(t, err)
+		assert.True(t, merged.Blocked)
+
+		var tags []string
+		require.NoError(t, tdb.DB.Model(&models.ContactTag{}).Where("contact_id = ?", primary.ID).Order("tag").Pluck("tag", &tags).Error)
+		assert.Equal(t, []string{"vip", "work"}, tags)
+
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{GroupID: group.ID, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, primary.ID, contacts[0].ID)
+	})
+
+	t.Run("every contact must belong to the user", func(t *testing.T) {
+		primary := newContact(models.Contact{UserID: user.ID, FullName: "Mine", Phone: "14155550021"})
+		duplicate := newContact(models.Contact{UserID: user.ID, FullName: "Mine Too", Phone: "14155550022", Email: str("mine@example.com")})
+		stranger := newContact(models.Contact{UserID: other.ID, FullName: "Theirs", Phone: "14155550023", Email: str("theirs@example.com")})
+
+		_, err := svc.MergeContacts(ctx, user.ID, primary.ID, []uint{duplicate.ID, stranger.ID})
+		assert.ErrorIs(t, err, service.ErrContactNotFound)
+		_, err = svc.MergeContacts(ctx, user.ID, stranger.ID, []uint{duplicate.ID})
+		assert.ErrorIs(t, err, service.ErrContactNotFound)
+		_, err = svc.MergeContacts(ctx, user.ID, primary.ID, []uint{9999})
+		assert.ErrorIs(t, err, service.ErrContactNotFound)
+
+		// Nothing was merged or deleted
+		stored, err := repo.GetContact(ctx, user.ID, primary.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.Email)
+		_, err = repo.GetContact(ctx, user.ID, duplicate.ID)
+		assert.NoError(t, err)
+		_, err = repo.GetContact(ctx, other.ID, stranger.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		primary := newContact(models.Contact{UserID: user.ID, FullName: "Solo", Phone: "14155550031"})
+
+		_, err := svc.MergeContacts(ctx, user.ID, primary.ID, nil)
+		assert.ErrorIs(t, err, service.ErrMergeDuplicatesRequired)
+		_, err = svc.MergeContacts(ctx, user.ID, primary.ID, []uint{primary.ID})
+		assert.ErrorIs(t, err, service.ErrMergeIntoSelf)
+		_, err = svc.MergeContacts(ctx, user.ID, primary.ID, make([]uint, service.MaxBatchContacts+1))
+		assert.ErrorIs(t, err, service.ErrMergeTooLarge)
+	})
+}
+
+func TestHandler_MergeContacts(t *testing.T) {
+	merge := func(svc *MockService, body string) *httptest.ResponseRecorder {
+		router := setupTestRouter(svc)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/contacts/merge", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("MergeContacts", mock.Anything, uint(1), uint(5), []uint{6, 7}).
+			Return(&models.Contact{ID: 5, FullName: "John Doe"}, nil)
+
+		w := merge(mockService, `{"primary_id": 5, "duplicate_ids": [6, 7]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(5), response.Data.(map[string]interface{})["id"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("contact of another user", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("MergeContacts", mock.Anything, uint(1), uint(5), []uint{8}).Return(nil, service.ErrContactNotFound)
+
+		w := merge(mockService, `{"primary_id": 5, "duplicate_ids": [8]}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("merging into itself", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("MergeContacts", mock.Anything, uint(1), uint(5), []uint{5}).Return(nil, service.ErrMergeIntoSelf)
+
+		w := merge(mockService, `{"primary_id": 5, "duplicate_ids": [5]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing primary", func(t *testing.T) {
+		w := merge(new(MockService), `{"duplicate_ids": [6]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/models"
+	"user-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_Report(t *testing.T) {
+	all := migrations.GetMigrations()
+
+	t.Run("missing tracking table means everything is pending", func(t *testing.T) {
+		testDB, _, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		report, err := migrations.NewRunner(testDB.SqlDB).Report()
+
+		require.NoError(t, err)
+		assert.Len(t, report.Migrations, len(all))
+		assert.Equal(t, len(all), report.Pending)
+		assert.False(t, report.UpToDate)
+	})
+
+	t.Run("applied and pending migrations serialize to JSON", func(t *testing.T) {
+		testDB, _, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		_, err := testDB.SqlDB.Exec(`CREATE TABLE schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+		require.NoError(t, err)
+		_, err = testDB.SqlDB.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			all[0].ID, all[0].ID, "2025-06-01 12:00:00")
+		require.NoError(t, err)
+
+		report, err := migrations.NewRunner(testDB.SqlDB).Report()
+		require.NoError(t, err)
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		var decoded struct {
+			Migrations []struct {
+				Version   string  `json:"version"`
+				Name      string  `json:"name"`
+				Applied   bool    `json:"applied"`
+				AppliedAt *string `json:"applied_at"`
+			} `json:"migrations"`
+			Applied        int    `json:"applied"`
+			Pending        int    `json:"pending"`
+			UpToDate       bool   `json:"up_to_date"`
+			CurrentVersion string `json:"current_version"`
+		}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		require.Len(t, decoded.Migrations, len(all))
+		first := decoded.Migrations[0]
+		assert.Equal(t, "001", first.Version)
+		assert.Equal(t, "create_users_table", first.Name)
+		assert.True(t, first.Applied)
+		require.NotNil(t, first.AppliedAt)
+		assert.Equal(t, "2025-06-01T12:00:00Z", *first.AppliedAt)
+
+		second := decoded.Migrations[1]
+		assert.False(t, second.Applied)
+		assert.Nil(t, second.AppliedAt)
+
+		assert.Equal(t, 1, decoded.Applied)
+		assert.Equal(t, len(all)-1, decoded.Pending)
+		assert.False(t, decoded.UpToDate)
+		assert.Equal(t, "001", decoded.CurrentVersion)
+	})
+
+	t.Run("fully migrated database is up to date", func(t *testing.T) {
+		testDB, _, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		_, err := testDB.SqlDB.Exec(`CREATE TABLE schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+		require.NoError(t, err)
+		for _, m := range all {
+			_, err := testDB.SqlDB.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.ID, m.ID)
+			require.NoError(t, err)
+		}
+
+		report, err := migrations.NewRunner(testDB.SqlDB).Report()
+
+		require.NoError(t, err)
+		assert.Zero(t, report.Pending)
+		assert.True(t, report.UpToDate)
+		latest, _, _ := strings.Cut(all[len(all)-1].ID, "_")
+		assert.Equal(t, latest, report.CurrentVersion)
+	})
+}
+
+func TestHandler_MigrationStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, _, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	request := func(t *testing.T, role string, opts ...handlers.Option) (int, models.Response) {
+		t.Helper()
+		router := gin.New()
+		admin := router.Group("/api/v1/admin")
+		admin.Use(func(c *gin.Context) {
+			c.Set("user_id", uint(1))
+			c.Set("role", role)
+			c.Next()
+		}, middleware.RequireRole(models.RoleAdmin))
+		admin.GET("/migrations", handlers.NewHandler(new(MockService), opts...).MigrationStatus)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/migrations", nil)
+		router.ServeHTTP(w, req)
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	t.Run("admin gets the report", func(t *testing.T) {
+		code, response := request(t, models.RoleAdmin, handlers.WithMigrations(migrations.NewRunner(testDB.SqlDB)))
+
+		require.Equal(t, http.StatusOK, code)
+		data := response.Data.(map[string]interface{})
+		assert.Len(t, data["migrations"], len(migrations.GetMigrations()))
+		assert.Equal(t, float64(len(migrations.GetMigrations())), data["pending"])
+		assert.Equal(t, false, data["up_to_date"])
+		assert.Equal(t, "", data["current_version"])
+	})
+
+	t.Run("other users are refused", func(t *testing.T) {
+		code, _ := request(t, models.RoleUser, handlers.WithMigrations(migrations.NewRunner(testDB.SqlDB)))
+
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		code, _ := request(t, models.RoleAdmin)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+	})
+}
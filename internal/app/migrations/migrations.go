@@ -2,6 +2,7 @@ package migrations
 
 import (
 	"database/sql"
+	"fmt"
 )
 
 // Migration represents a database migration
@@ -199,11 +200,547 @@ func GetMigrations() []Migration {
 				return err
 			},
 		},
+		{
+			ID: "005_add_user_role",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user',
+					ADD INDEX idx_users_role (role)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					DROP INDEX idx_users_role,
+					DROP COLUMN role
+				`)
+				return err
+			},
+		},
+		{
+			ID: "006_add_user_inactivity_tracking",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					ADD COLUMN last_login_at TIMESTAMP NULL DEFAULT NULL,
+					ADD COLUMN inactivity_warned_at TIMESTAMP NULL DEFAULT NULL,
+					ADD COLUMN flagged_inactive_at TIMESTAMP NULL DEFAULT NULL,
+					ADD INDEX idx_users_last_login_at (last_login_at)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					DROP INDEX idx_users_last_login_at,
+					DROP COLUMN last_login_at,
+					DROP COLUMN inactivity_warned_at,
+					DROP COLUMN flagged_inactive_at
+				`)
+				return err
+			},
+		},
+		{
+			ID: "007_create_invite_codes_table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS invite_codes (
+						id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+						code VARCHAR(64) NOT NULL,
+						created_by INT UNSIGNED NOT NULL,
+						uses_remaining INT NOT NULL DEFAULT 1,
+						expires_at TIMESTAMP NULL DEFAULT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+						-- Foreign key constraint
+						CONSTRAINT fk_invite_codes_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+
+						-- Indexes
+						UNIQUE INDEX idx_invite_codes_code (code),
+						INDEX idx_invite_codes_created_by (created_by),
+						INDEX idx_invite_codes_expires_at (expires_at)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS invite_codes`)
+				return err
+			},
+		},
+		{
+			ID: "008_create_import_jobs_table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS import_jobs (
+						id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+						user_id INT UNSIGNED NOT NULL,
+						status VARCHAR(20) NOT NULL,
+						total INT NOT NULL DEFAULT 0,
+						imported INT NOT NULL DEFAULT 0,
+						skipped INT NOT NULL DEFAULT 0,
+						failed INT NOT NULL DEFAULT 0,
+						error VARCHAR(255) NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+						-- Foreign key constraint
+						CONSTRAINT fk_import_jobs_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+						-- Indexes
+						INDEX idx_import_jobs_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS import_jobs`)
+				return err
+			},
+		},
+		{
+			ID: "009_add_contact_soft_delete",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+					ADD INDEX idx_contacts_deleted_at (deleted_at)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP INDEX idx_contacts_deleted_at,
+					DROP COLUMN deleted_at
+				`)
+				return err
+			},
+		},
+		{
+			ID: "010_widen_contact_columns_for_encryption",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					MODIFY COLUMN phone VARCHAR(255) NOT NULL,
+					MODIFY COLUMN email VARCHAR(512) NULL
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					MODIFY COLUMN phone VARCHAR(20) NOT NULL,
+					MODIFY COLUMN email VARCHAR(255) NULL
+				`)
+				return err
+			},
+		},
+		{
+			// Rows are backfilled at startup by BackfillPhoneHashes, since the HMAC key is not available to SQL
+			ID: "011_add_contact_phone_hash",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN phone_hash CHAR(64) NULL DEFAULT NULL,
+					ADD INDEX idx_contacts_user_phone_hash (user_id, phone_hash)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP INDEX idx_contacts_user_phone_hash,
+					DROP COLUMN phone_hash
+				`)
+				return err
+			},
+		},
+		{
+			ID: "012_add_contact_avatar_url",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN avatar_url VARCHAR(255) NULL DEFAULT NULL
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP COLUMN avatar_url
+				`)
+				return err
+			},
+		},
+		{
+			ID: "013_add_contact_blocked",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN blocked BOOLEAN NOT NULL DEFAULT FALSE,
+					ADD INDEX idx_contacts_user_blocked (user_id, blocked)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP INDEX idx_contacts_user_blocked,
+					DROP COLUMN blocked
+				`)
+				return err
+			},
+		},
+		{
+			ID: "014_create_contact_tags_table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS contact_tags (
+						contact_id INT UNSIGNED NOT NULL,
+						tag VARCHAR(64) NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+						PRIMARY KEY (contact_id, tag),
+
+						-- Foreign key constraint
+						CONSTRAINT fk_contact_tags_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id) ON DELETE CASCADE,
+
+						-- Indexes
+						INDEX idx_contact_tags_tag (tag)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS contact_tags`)
+				return err
+			},
+		},
+		{
+			ID: "015_add_contact_source",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'manual',
+					ADD INDEX idx_contacts_user_source (user_id, source)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP INDEX idx_contacts_user_source,
+					DROP COLUMN source
+				`)
+				return err
+			},
+		},
+		{
+			ID: "016_add_import_job_duplicates",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE import_jobs
+					ADD COLUMN flagged INT NOT NULL DEFAULT 0 AFTER failed,
+					ADD COLUMN duplicates TEXT NULL AFTER error
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE import_jobs
+					DROP COLUMN duplicates,
+					DROP COLUMN flagged
+				`)
+				return err
+			},
+		},
+		{
+			ID: "017_add_contact_company_job_title",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN company VARCHAR(255) NULL AFTER avatar_url,
+					ADD COLUMN job_title VARCHAR(255) NULL AFTER company
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP COLUMN job_title,
+					DROP COLUMN company
+				`)
+				return err
+			},
+		},
+		{
+			ID: "018_add_user_directory_opt_in",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					ADD COLUMN directory_opt_in BOOLEAN NOT NULL DEFAULT FALSE AFTER role
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE users DROP COLUMN directory_opt_in`)
+				return err
+			},
+		},
+		{
+			ID: "019_create_revoked_tokens_table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS revoked_tokens (
+						jti VARCHAR(64) NOT NULL PRIMARY KEY,
+						user_id INT UNSIGNED NOT NULL,
+						expires_at TIMESTAMP NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+						-- Foreign key constraint
+						CONSTRAINT fk_revoked_tokens_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+						-- Indexes
+						INDEX idx_revoked_tokens_user_id (user_id),
+						INDEX idx_revoked_tokens_expires_at (expires_at)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS revoked_tokens`)
+				return err
+			},
+		},
+		{
+			ID: "020_create_password_reset_tokens_table",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS password_reset_tokens (
+						id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+						user_id INT UNSIGNED NOT NULL,
+						token_hash VARCHAR(64) NOT NULL,
+						expires_at TIMESTAMP NOT NULL,
+						used_at TIMESTAMP NULL DEFAULT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+						-- Foreign key constraint
+						CONSTRAINT fk_password_reset_tokens_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+						-- Indexes
+						UNIQUE INDEX idx_password_reset_tokens_token_hash (token_hash),
+						INDEX idx_password_reset_tokens_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE IF EXISTS password_reset_tokens`)
+				return err
+			},
+		},
+		{
+			ID: "021_add_user_login_lockout",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0 AFTER flagged_inactive_at,
+					ADD COLUMN locked_until TIMESTAMP NULL DEFAULT NULL AFTER failed_login_attempts
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE users
+					DROP COLUMN locked_until,
+					DROP COLUMN failed_login_attempts
+				`)
+				return err
+			},
+		},
+		{
+			ID: "022_create_contact_groups_tables",
+			Up: func(tx *sql.Tx) error {
+				if _, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS contact_groups (
+						id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+						user_id INT UNSIGNED NOT NULL,
+						name VARCHAR(64) NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+						-- Foreign key constraint
+						CONSTRAINT fk_contact_groups_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+						-- Indexes
+						UNIQUE INDEX idx_contact_groups_user_name (user_id, name)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`); err != nil {
+					return err
+				}
+				_, err := tx.Exec(`
+					CREATE TABLE IF NOT EXISTS contact_group_members (
+						group_id INT UNSIGNED NOT NULL,
+						contact_id INT UNSIGNED NOT NULL,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+						PRIMARY KEY (group_id, contact_id),
+
+						-- Foreign key constraints
+						CONSTRAINT fk_contact_group_members_group_id FOREIGN KEY (group_id) REFERENCES contact_groups(id) ON DELETE CASCADE,
+						CONSTRAINT fk_contact_group_members_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id) ON DELETE CASCADE,
+
+						-- Indexes
+						INDEX idx_contact_group_members_contact_id (contact_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				if _, err := tx.Exec(`DROP TABLE IF EXISTS contact_group_members`); err != nil {
+					return err
+				}
+				_, err := tx.Exec(`DROP TABLE IF EXISTS contact_groups`)
+				return err
+			},
+		},
+		{
+			// Rows are backfilled at startup by BackfillSearchText, since SQL cannot strip accents portably
+			ID: "023_add_contact_search_text",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN search_text TEXT NULL
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP COLUMN search_text
+				`)
+				return err
+			},
+		},
+		{
+			// Refuses to run while a user has live contacts sharing a number; merge them first
+			ID: "024_add_contacts_user_phone_unique",
+			Up: func(tx *sql.Tx) error {
+				var duplicates int
+				if err := tx.QueryRow(`
+					SELECT COUNT(*) FROM (
+						SELECT user_id FROM contacts
+						WHERE deleted_at IS NULL
+						GROUP BY user_id, COALESCE(phone_hash, phone)
+						HAVING COUNT(*) > 1
+					) AS shared
+				`).Scan(&duplicates); err != nil {
+					return err
+				}
+				if duplicates > 0 {
+					return fmt.Errorf("cannot add unique contact phone constraint, %d phone numbers are shared by contacts of the same user; merge them first", duplicates)
+				}
+
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN phone_key VARCHAR(255) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN COALESCE(phone_hash, phone) END) STORED,
+					ADD UNIQUE INDEX idx_contacts_user_phone_key (user_id, phone_key)
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP INDEX idx_contacts_user_phone_key,
+					DROP COLUMN phone_key
+				`)
+				return err
+			},
+		},
+		{
+			// Tables that predate 001 or were created by AutoMigrate may lack the
+			// email constraint Register relies on when two sign-ups race
+			ID: "025_ensure_users_email_unique",
+			Up: func(tx *sql.Tx) error {
+				var unique int
+				if err := tx.QueryRow(`
+					SELECT COUNT(*) FROM information_schema.statistics s
+					WHERE s.table_schema = DATABASE() AND s.table_name = 'users'
+						AND s.non_unique = 0 AND s.column_name = 'email' AND s.seq_in_index = 1
+						AND NOT EXISTS (
+							SELECT 1 FROM information_schema.statistics o
+							WHERE o.table_schema = s.table_schema AND o.table_name = s.table_name
+								AND o.index_name = s.index_name AND o.seq_in_index = 2
+						)
+				`).Scan(&unique); err != nil {
+					return err
+				}
+				if unique > 0 {
+					return nil
+				}
+
+				var duplicates int
+				if err := tx.QueryRow(`
+					SELECT COUNT(*) FROM (
+						SELECT email FROM users GROUP BY email HAVING COUNT(*) > 1
+					) AS shared
+				`).Scan(&duplicates); err != nil {
+					return err
+				}
+				if duplicates > 0 {
+					return fmt.Errorf("cannot add unique email constraint, %d email addresses are shared by several users", duplicates)
+				}
+
+				_, err := tx.Exec(`ALTER TABLE users ADD UNIQUE INDEX ` + UniqueUserEmailIndex + ` (email)`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				// Only the index this migration added is dropped
+				var added int
+				if err := tx.QueryRow(`
+					SELECT COUNT(*) FROM information_schema.statistics
+					WHERE table_schema = DATABASE() AND table_name = 'users' AND index_name = ?
+				`, UniqueUserEmailIndex).Scan(&added); err != nil || added == 0 {
+					return err
+				}
+				_, err := tx.Exec(`ALTER TABLE users DROP INDEX ` + UniqueUserEmailIndex)
+				return err
+			},
+		},
+		{
+			// Bumped on every contact write, so ETags change even within a second
+			ID: "026_add_contacts_version",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1
+				`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`
+					ALTER TABLE contacts
+					DROP COLUMN version
+				`)
+				return err
+			},
+		},
 	}
 }
 
-// CreateMigrationsTable creates the migrations tracking table
+// UniqueUserEmailIndex is the unique index on users.email added by migration
+// 025 when the table had none
+const UniqueUserEmailIndex = "idx_users_email_unique"
+
+// CreateMigrationsTable creates the migrations tracking table unless it
+// already exists
 func CreateMigrationsTable(db *sql.DB) error {
+	if probe, err := db.Query("SELECT 1 FROM schema_migrations WHERE 1 = 0"); err == nil {
+		return probe.Close()
+	}
 	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version VARCHAR(255) PRIMARY KEY,
@@ -232,10 +769,10 @@ func IsMigrationApplied(db *sql.DB, migrationID string) (bool, error) {
 // MarkMigrationApplied marks a migration as applied
 func MarkMigrationApplied(tx *sql.Tx, migrationID string) error {
 	// Try with 'version' column first (new structure)
-	_, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, NOW())", migrationID, migrationID)
+	_, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, CURRENT_TIMESTAMP)", migrationID, migrationID)
 	if err != nil {
 		// If that fails, try with 'id' column (old structure)
-		_, err = tx.Exec("INSERT INTO schema_migrations (id, name, applied_at) VALUES (?, ?, NOW())", migrationID, migrationID)
+		_, err = tx.Exec("INSERT INTO schema_migrations (id, name, applied_at) VALUES (?, ?, CURRENT_TIMESTAMP)", migrationID, migrationID)
 	}
 	return err
 }
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// UniqueUserPhoneIndex is the name of the optional unique index on users.phone
+const UniqueUserPhoneIndex = "idx_users_phone_unique"
+
+// DuplicatePhone is a phone number shared by more than one user
+type DuplicatePhone struct {
+	Phone string
+	Count int
+}
+
+// DuplicateUserPhonesError is returned when the unique phone constraint cannot
+// be applied because existing users share phone numbers
+type DuplicateUserPhonesError struct {
+	Duplicates []DuplicatePhone
+}
+
+func (e *DuplicateUserPhonesError) Error() string {
+	phones := make([]string, len(e.Duplicates))
+	for i, d := range e.Duplicates {
+		phones[i] = fmt.Sprintf("%s (%d users)", d.Phone, d.Count)
+	}
+	return fmt.Sprintf("cannot add unique phone constraint, %d duplicate phone numbers: %s",
+		len(e.Duplicates), strings.Join(phones, ", "))
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// FindDuplicateUserPhones lists the phone numbers used by more than one user
+func FindDuplicateUserPhones(q queryer) ([]DuplicatePhone, error) {
+	rows, err := q.Query(`
+		SELECT phone, COUNT(*) FROM users
+		WHERE phone IS NOT NULL AND phone <> ''
+		GROUP BY phone
+		HAVING COUNT(*) > 1
+		ORDER BY phone
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var duplicates []DuplicatePhone
+	for rows.Next() {
+		var d DuplicatePhone
+		if err := rows.Scan(&d.Phone, &d.Count); err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, d)
+	}
+	return duplicates, rows.Err()
+}
+
+// UniqueUserPhoneMigration adds a unique index on users.phone. It is not part
+// of GetMigrations because existing data may contain duplicates; it refuses to
+// run until they are resolved.
+func UniqueUserPhoneMigration() Migration {
+	return Migration{
+		ID: "opt_001_add_users_phone_unique",
+		Up: func(tx *sql.Tx) error {
+			duplicates, err := FindDuplicateUserPhones(tx)
+			if err != nil {
+				return err
+			}
+			if len(duplicates) > 0 {
+				return &DuplicateUserPhonesError{Duplicates: duplicates}
+			}
+
+			// Empty strings would collide under the unique index; store them as NULL
+			if _, err := tx.Exec(`UPDATE users SET phone = NULL WHERE phone = ''`); err != nil {
+				return err
+			}
+			_, err = tx.Exec(`ALTER TABLE users ADD UNIQUE INDEX ` + UniqueUserPhoneIndex + ` (phone)`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE users DROP INDEX ` + UniqueUserPhoneIndex)
+			return err
+		},
+	}
+}
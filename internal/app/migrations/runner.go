@@ -4,16 +4,19 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 )
 
 // Runner handles running database migrations
 type Runner struct {
-	db *sql.DB
+	db         *sql.DB
+	migrations []Migration
 }
 
 // NewRunner creates a new migration runner
 func NewRunner(db *sql.DB) *Runner {
-	return &Runner{db: db}
+	return &Runner{db: db, migrations: GetMigrations()}
 }
 
 // MigrateUp runs all pending migrations
@@ -25,48 +28,96 @@ func (r *Runner) MigrateUp() error {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
-	migrations := GetMigrations()
-
-	for _, migration := range migrations {
-		applied, err := IsMigrationApplied(r.db, migration.ID)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status for %s: %w", migration.ID, err)
+	for _, migration := range r.migrations {
+		if err := r.apply(migration); err != nil {
+			return err
 		}
+	}
 
-		if applied {
-			log.Printf("Migration %s already applied, skipping", migration.ID)
-			continue
-		}
+	log.Println("Database migrations completed successfully")
+	return nil
+}
 
-		log.Printf("Applying migration: %s", migration.ID)
+// MigrateUpTo applies the pending migrations up to and including version,
+// leaving later ones pending
+func (r *Runner) MigrateUpTo(version string) error {
+	target, err := r.indexOf(version)
+	if err != nil {
+		return err
+	}
+	log.Printf("Migrating up to version %s...", version)
 
-		// Start transaction
-		tx, err := r.db.Begin()
-		if err != nil {
-			return fmt.Errorf("failed to start transaction for migration %s: %w", migration.ID, err)
-		}
+	if err := CreateMigrationsTable(r.db); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
 
-		// Run migration
-		if err := migration.Up(tx); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to run migration %s: %w", migration.ID, err)
+	for _, migration := range r.migrations[:target+1] {
+		if err := r.apply(migration); err != nil {
+			return err
 		}
+	}
 
-		// Mark as applied
-		if err := MarkMigrationApplied(tx, migration.ID); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to mark migration %s as applied: %w", migration.ID, err)
-		}
+	log.Printf("Database migrated up to version %s", version)
+	return nil
+}
 
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", migration.ID, err)
+// indexOf returns the position of the migration with the given version
+func (r *Runner) indexOf(version string) (int, error) {
+	for i, migration := range r.migrations {
+		if v, _, _ := strings.Cut(migration.ID, "_"); v == version {
+			return i, nil
 		}
+	}
+	return 0, fmt.Errorf("unknown migration version %q", version)
+}
+
+// ApplyOptional runs a single migration that is not part of the default set,
+// recording it in schema_migrations like any other migration
+func (r *Runner) ApplyOptional(migration Migration) error {
+	if err := CreateMigrationsTable(r.db); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return r.apply(migration)
+}
 
-		log.Printf("Successfully applied migration: %s", migration.ID)
+// apply runs a migration in its own transaction unless it is already applied
+func (r *Runner) apply(migration Migration) error {
+	applied, err := IsMigrationApplied(r.db, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check migration status for %s: %w", migration.ID, err)
 	}
 
-	log.Println("Database migrations completed successfully")
+	if applied {
+		log.Printf("Migration %s already applied, skipping", migration.ID)
+		return nil
+	}
+
+	log.Printf("Applying migration: %s", migration.ID)
+
+	// Start transaction
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction for migration %s: %w", migration.ID, err)
+	}
+
+	// Run migration
+	if err := migration.Up(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to run migration %s: %w", migration.ID, err)
+	}
+
+	// Mark as applied
+	if err := MarkMigrationApplied(tx, migration.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to mark migration %s as applied: %w", migration.ID, err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration.ID, err)
+	}
+
+	log.Printf("Successfully applied migration: %s", migration.ID)
 	return nil
 }
 
@@ -74,77 +125,177 @@ func (r *Runner) MigrateUp() error {
 func (r *Runner) MigrateDown() error {
 	log.Println("Rolling back last migration...")
 
-	migrations := GetMigrations()
-	if len(migrations) == 0 {
+	if len(r.migrations) == 0 {
 		log.Println("No migrations to roll back")
 		return nil
 	}
 
 	// Find the last applied migration
-	var lastMigration *Migration
-	for i := len(migrations) - 1; i >= 0; i-- {
-		applied, err := IsMigrationApplied(r.db, migrations[i].ID)
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		applied, err := IsMigrationApplied(r.db, r.migrations[i].ID)
 		if err != nil {
-			return fmt.Errorf("failed to check migration status for %s: %w", migrations[i].ID, err)
+			return fmt.Errorf("failed to check migration status for %s: %w", r.migrations[i].ID, err)
 		}
 		if applied {
-			lastMigration = &migrations[i]
-			break
+			return r.rollback(r.migrations[i])
 		}
 	}
 
-	if lastMigration == nil {
-		log.Println("No applied migrations to roll back")
-		return nil
+	log.Println("No applied migrations to roll back")
+	return nil
+}
+
+// MigrateDownTo rolls back, newest first, every applied migration after
+// version, leaving version itself applied
+func (r *Runner) MigrateDownTo(version string) error {
+	target, err := r.indexOf(version)
+	if err != nil {
+		return err
 	}
+	log.Printf("Rolling back to version %s...", version)
 
-	log.Printf("Rolling back migration: %s", lastMigration.ID)
+	for i := len(r.migrations) - 1; i > target; i-- {
+		applied, err := IsMigrationApplied(r.db, r.migrations[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to check migration status for %s: %w", r.migrations[i].ID, err)
+		}
+		if !applied {
+			continue
+		}
+		if err := r.rollback(r.migrations[i]); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Database rolled back to version %s", version)
+	return nil
+}
+
+// rollback reverts an applied migration in its own transaction
+func (r *Runner) rollback(migration Migration) error {
+	log.Printf("Rolling back migration: %s", migration.ID)
 
 	// Start transaction
 	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to start transaction for rollback %s: %w", lastMigration.ID, err)
+		return fmt.Errorf("failed to start transaction for rollback %s: %w", migration.ID, err)
 	}
 
 	// Run rollback
-	if err := lastMigration.Down(tx); err != nil {
+	if err := migration.Down(tx); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("failed to rollback migration %s: %w", lastMigration.ID, err)
+		return fmt.Errorf("failed to rollback migration %s: %w", migration.ID, err)
 	}
 
 	// Mark as unapplied
-	if err := MarkMigrationUnapplied(tx, lastMigration.ID); err != nil {
+	if err := MarkMigrationUnapplied(tx, migration.ID); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("failed to mark migration %s as unapplied: %w", lastMigration.ID, err)
+		return fmt.Errorf("failed to mark migration %s as unapplied: %w", migration.ID, err)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback %s: %w", lastMigration.ID, err)
+		return fmt.Errorf("failed to commit rollback %s: %w", migration.ID, err)
 	}
 
-	log.Printf("Successfully rolled back migration: %s", lastMigration.ID)
+	log.Printf("Successfully rolled back migration: %s", migration.ID)
 	return nil
 }
 
-// Status shows the current migration status
-func (r *Runner) Status() error {
-	log.Println("Migration Status:")
+// MigrationStatus describes one known migration and whether it is applied
+type MigrationStatus struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
 
-	migrations := GetMigrations()
+// StatusReport lists every known migration in order, for tooling that checks
+// whether a database is fully migrated
+type StatusReport struct {
+	Migrations []MigrationStatus `json:"migrations"`
+	Applied    int               `json:"applied"`
+	Pending    int               `json:"pending"`
+	UpToDate   bool              `json:"up_to_date"`
+	// CurrentVersion is the version of the last applied migration, empty
+	// when none is
+	CurrentVersion string `json:"current_version"`
+}
 
-	for _, migration := range migrations {
-		applied, err := IsMigrationApplied(r.db, migration.ID)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status for %s: %w", migration.ID, err)
+// Report returns the state of every known migration. A database without the
+// tracking table has every migration pending.
+func (r *Runner) Report() (*StatusReport, error) {
+	appliedAt, err := r.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatusReport{Migrations: []MigrationStatus{}}
+	for _, migration := range r.migrations {
+		version, name, _ := strings.Cut(migration.ID, "_")
+		status := MigrationStatus{Version: version, Name: name}
+		if at, ok := appliedAt[migration.ID]; ok {
+			status.Applied = true
+			if !at.IsZero() {
+				status.AppliedAt = &at
+			}
+			report.Applied++
+			report.CurrentVersion = version
+		} else {
+			report.Pending++
+		}
+		report.Migrations = append(report.Migrations, status)
+	}
+	report.UpToDate = report.Pending == 0
+	return report, nil
+}
+
+// appliedMigrations maps the ID of every applied migration to when it was applied
+func (r *Runner) appliedMigrations() (map[string]time.Time, error) {
+	applied := make(map[string]time.Time)
+
+	// Try with 'version' column first (new structure), then 'id' (old structure)
+	rows, err := r.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		rows, err = r.db.Query("SELECT id, applied_at FROM schema_migrations")
+	}
+	if err != nil {
+		// A zero-row query only fails when the tracking table is missing
+		probe, probeErr := r.db.Query("SELECT 1 FROM schema_migrations WHERE 1 = 0")
+		if probeErr != nil {
+			return applied, nil
 		}
+		probe.Close()
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var id string
+		var at sql.NullTime
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[id] = at.Time
+	}
+	return applied, rows.Err()
+}
+
+// Status shows the current migration status
+func (r *Runner) Status() error {
+	report, err := r.Report()
+	if err != nil {
+		return err
+	}
+
+	log.Println("Migration Status:")
+	for _, migration := range report.Migrations {
 		status := "Pending"
-		if applied {
+		if migration.Applied {
 			status = "Applied"
 		}
 
-		log.Printf("  %s: %s", migration.ID, status)
+		log.Printf("  %s_%s: %s", migration.Version, migration.Name, status)
 	}
 
 	return nil
@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// tableMigration creates and drops a table, in SQL sqlite understands
+func tableMigration(id, table string) Migration {
+	return Migration{
+		ID: id,
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)")
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec("DROP TABLE " + table)
+			return err
+		},
+	}
+}
+
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: is a separate database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	_, err = sqlDB.Exec(`CREATE TABLE schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	require.NoError(t, err)
+
+	return &Runner{db: sqlDB, migrations: []Migration{
+		tableMigration("001_create_a", "a"),
+		tableMigration("002_create_b", "b"),
+		tableMigration("003_create_c", "c"),
+		tableMigration("004_create_d", "d"),
+	}}
+}
+
+func TestRunner_MigrateTo(t *testing.T) {
+	runner := newTestRunner(t)
+
+	state := func() (string, []bool) {
+		t.Helper()
+		report, err := runner.Report()
+		require.NoError(t, err)
+		applied := []bool{}
+		for _, migration := range report.Migrations {
+			applied = append(applied, migration.Applied)
+		}
+		return report.CurrentVersion, applied
+	}
+	tableExists := func(table string) bool {
+		t.Helper()
+		var count int
+		require.NoError(t, runner.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count))
+		return count > 0
+	}
+
+	t.Run("up to an intermediate version", func(t *testing.T) {
+		require.NoError(t, runner.MigrateUpTo("002"))
+
+		version, applied := state()
+		assert.Equal(t, "002", version)
+		assert.Equal(t, []bool{true, true, false, false}, applied)
+		assert.True(t, tableExists("b"))
+		assert.False(t, tableExists("c"))
+	})
+
+	t.Run("further up applies only the missing migrations", func(t *testing.T) {
+		require.NoError(t, runner.MigrateUpTo("004"))
+
+		version, applied := state()
+		assert.Equal(t, "004", version)
+		assert.Equal(t, []bool{true, true, true, true}, applied)
+	})
+
+	t.Run("down to an intermediate version", func(t *testing.T) {
+		require.NoError(t, runner.MigrateDownTo("002"))
+
+		version, applied := state()
+		assert.Equal(t, "002", version)
+		assert.Equal(t, []bool{true, true, false, false}, applied)
+		assert.True(t, tableExists("b"))
+		assert.False(t, tableExists("c"))
+		assert.False(t, tableExists("d"))
+	})
+
+	t.Run("targets already reached are no-ops", func(t *testing.T) {
+		require.NoError(t, runner.MigrateUpTo("001"))
+		require.NoError(t, runner.MigrateDownTo("003"))
+
+		version, applied := state()
+		assert.Equal(t, "002", version)
+		assert.Equal(t, []bool{true, true, false, false}, applied)
+	})
+
+	t.Run("down to the first version", func(t *testing.T) {
+		require.NoError(t, runner.MigrateDownTo("001"))
+
+		version, applied := state()
+		assert.Equal(t, "001", version)
+		assert.Equal(t, []bool{true, false, false, false}, applied)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		assert.ErrorContains(t, runner.MigrateUpTo("009"), `unknown migration version "009"`)
+		assert.ErrorContains(t, runner.MigrateDownTo("create_a"), "unknown migration version")
+
+		version, applied := state()
+		assert.Equal(t, "001", version)
+		assert.Equal(t, []bool{true, false, false, false}, applied)
+	})
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"user-service/internal/app/fieldcrypt"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// encryptedSerializer stores string and *string fields through fieldcrypt, so they
+// are ciphertext in the database when field encryption is enabled
+type encryptedSerializer struct{}
+
+// Scan decrypts the database value into the field
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case []byte:
+			stored = string(v)
+		case string:
+			stored = v
+		default:
+			return fmt.Errorf("unsupported encrypted value type %T for %s", dbValue, field.Name)
+		}
+
+		plaintext, err := fieldcrypt.Decrypt(stored)
+		if err != nil {
+			return err
+		}
+
+		if field.FieldType.Kind() == reflect.Ptr {
+			fieldValue.Elem().Set(reflect.ValueOf(&plaintext))
+		} else {
+			fieldValue.Elem().SetString(plaintext)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value encrypts the field before it is written
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case string:
+		return fieldcrypt.Encrypt(v)
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		return fieldcrypt.Encrypt(*v)
+	default:
+		return nil, fmt.Errorf("unsupported encrypted field type %T for %s", fieldValue, field.Name)
+	}
+}
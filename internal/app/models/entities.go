@@ -1,33 +1,272 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 // User represents the user model
 type User struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	FullName  string    `gorm:"type:varchar(255);not null;index:idx_users_full_name" json:"full_name"`
-	Email     string    `gorm:"type:varchar(255);unique;not null;index:idx_users_email" json:"email"`
-	Phone     *string   `gorm:"type:varchar(20);index:idx_users_phone" json:"phone,omitempty"`
-	Password  string    `gorm:"type:varchar(255);not null" json:"-"`
-	AvatarURL *string   `gorm:"type:varchar(255)" json:"avatar_url"`
-	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_users_created_at" json:"-"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+	ID        uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	FullName  string  `gorm:"type:varchar(255);not null;index:idx_users_full_name" json:"full_name"`
+	Email     string  `gorm:"type:varchar(255);unique;not null;index:idx_users_email" json:"email"`
+	Phone     *string `gorm:"type:varchar(20);index:idx_users_phone" json:"phone,omitempty"`
+	Password  string  `gorm:"type:varchar(255);not null" json:"-"`
+	AvatarURL *string `gorm:"type:varchar(255)" json:"avatar_url"`
+	Role      string  `gorm:"type:varchar(20);not null;default:user;index:idx_users_role" json:"role"`
+	// DirectoryOptIn lists the user in phone number lookups by other users
+	DirectoryOptIn bool      `gorm:"not null;default:false" json:"directory_opt_in"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index:idx_users_created_at" json:"-"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"-"`
+
+	// Inactivity tracking
+	LastLoginAt        *time.Time `gorm:"index:idx_users_last_login_at" json:"-"`
+	InactivityWarnedAt *time.Time `json:"-"`
+	FlaggedInactiveAt  *time.Time `json:"-"`
+
+	// Login lockout
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
 
 	// Relationships
 	Contacts []Contact `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"contacts,omitempty"`
 }
 
+// DirectoryEntry is the public info of a user found by phone number lookup.
+// SpamReports counts the other users who blocked the number.
+type DirectoryEntry struct {
+	Phone       string  `json:"phone"`
+	FullName    string  `json:"full_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	SpamReports int64   `json:"spam_reports"`
+}
+
 // Contact represents the contact model
 type Contact struct {
+	ID        uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint    `gorm:"not null;index:idx_contacts_user_id;index:idx_contacts_user_phone_hash,priority:1;index:idx_contacts_user_blocked,priority:1;index:idx_contacts_user_source,priority:1;uniqueIndex:idx_contacts_user_phone_key,priority:1" json:"-"`
+	FullName  string  `gorm:"type:varchar(255);not null;index:idx_contacts_full_name" json:"full_name"`
+	Phone     string  `gorm:"type:varchar(255);not null;index:idx_contacts_phone;serializer:encrypted" json:"phone"`
+	Email     *string `gorm:"type:varchar(512);index:idx_contacts_email;serializer:encrypted" json:"email"`
+	PhoneHash *string `gorm:"type:char(64);index:idx_contacts_user_phone_hash,priority:2" json:"-"`
+	// PhoneKey is generated by the database as the phone hash, or the phone
+	// without encryption, of contacts not deleted. Its unique index keeps a
+	// user's live contacts from sharing a number however writes interleave.
+	PhoneKey  *string `gorm:"type:varchar(255) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN COALESCE(phone_hash, phone) END) STORED;->;uniqueIndex:idx_contacts_user_phone_key,priority:2" json:"-"`
+	AvatarURL *string `gorm:"type:varchar(255)" json:"avatar_url"`
+	Company   *string `gorm:"type:varchar(255)" json:"company"`
+	JobTitle  *string `gorm:"type:varchar(255)" json:"job_title"`
+	Favorite  bool    `gorm:"default:false;index:idx_contacts_favorite" json:"favorite"`
+	Blocked   bool    `gorm:"not null;default:false;index:idx_contacts_user_blocked,priority:2" json:"blocked"`
+	Source    string  `gorm:"type:varchar(20);not null;default:manual;index:idx_contacts_user_source,priority:2" json:"source"`
+	// SearchText holds the name, company and job title folded for
+	// case- and accent-insensitive search
+	SearchText *string `gorm:"type:text" json:"-"`
+	// Version counts the writes to the contact; ETags are built from it
+	// since updated_at only has second precision in MySQL
+	Version   uint           `gorm:"not null;default:1" json:"-"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index:idx_contacts_created_at" json:"-"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"-"`
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_contacts_deleted_at" json:"-"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Contact sources record how a contact was created
+const (
+	ContactSourceManual      = "manual"
+	ContactSourceCSVImport   = "csv_import"
+	ContactSourceVCardImport = "vcard_import"
+	ContactSourceAPI         = "api"
+	ContactSourceShared      = "shared"
+)
+
+// ContactTag attaches a tag to a contact
+type ContactTag struct {
+	ContactID uint      `gorm:"primaryKey" json:"contact_id"`
+	Tag       string    `gorm:"type:varchar(64);primaryKey;index:idx_contact_tags_tag" json:"tag"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
+
+	// Relationships
+	Contact Contact `gorm:"foreignKey:ContactID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ContactGroup is a named group of the user's contacts, such as "Family"
+type ContactGroup struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    uint      `gorm:"not null;index:idx_contacts_user_id" json:"-"`
-	FullName  string    `gorm:"type:varchar(255);not null;index:idx_contacts_full_name" json:"full_name"`
-	Phone     string    `gorm:"type:varchar(20);not null;index:idx_contacts_phone" json:"phone"`
-	Email     *string   `gorm:"type:varchar(255);index:idx_contacts_email" json:"email"`
-	Favorite  bool      `gorm:"default:false;index:idx_contacts_favorite" json:"favorite"`
-	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_contacts_created_at" json:"-"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_contact_groups_user_name,priority:1" json:"-"`
+	Name      string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_contact_groups_user_name,priority:2" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// ContactCount is the number of non-deleted contacts in the group, loaded with the group
+	ContactCount int64 `gorm:"->;-:migration" json:"contact_count"`
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
 }
+
+// ContactGroupMember puts a contact in a group; a contact can be in several groups
+type ContactGroupMember struct {
+	GroupID   uint      `gorm:"primaryKey" json:"group_id"`
+	ContactID uint      `gorm:"primaryKey;index:idx_contact_group_members_contact_id" json:"contact_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
+
+	// Relationships
+	Group   ContactGroup `gorm:"foreignKey:GroupID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Contact Contact      `gorm:"foreignKey:ContactID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// AdminContact is a contact as seen by admins, including its owner and deletion state
+type AdminContact struct {
+	Contact
+	UserID    uint       `json:"user_id"`
+	Deleted   bool       `json:"deleted"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UserProfile is a user's profile with statistics about the account
+type UserProfile struct {
+	User
+	MemberSince   time.Time `json:"member_since"`
+	TotalContacts int64     `json:"total_contacts"`
+}
+
+// InviteCode represents a code that allows registration while signups are closed
+type InviteCode struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code          string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_invite_codes_code" json:"code"`
+	CreatedBy     uint       `gorm:"not null;index:idx_invite_codes_created_by" json:"created_by"`
+	UsesRemaining int        `gorm:"not null" json:"uses_remaining"`
+	ExpiresAt     *time.Time `gorm:"index:idx_invite_codes_expires_at" json:"expires_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RevokedToken blacklists a logged out access token until it would have
+// expired anyway
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey" json:"jti"`
+	UserID    uint      `gorm:"not null;index:idx_revoked_tokens_user_id" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_revoked_tokens_expires_at" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PasswordResetToken is a single-use, time-limited password reset token. Only
+// its SHA-256 hash is stored.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_password_reset_tokens_user_id" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_password_reset_tokens_token_hash" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Import job statuses
+const (
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+)
+
+// Import deduplication modes. Exact skips rows whose phone is already a
+// contact; fuzzy also holds back rows resembling an existing contact.
+const (
+	ImportDedupExact = "exact"
+	ImportDedupFuzzy = "fuzzy"
+)
+
+// ImportJob tracks the progress of a background contact import
+type ImportJob struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_import_jobs_user_id" json:"-"`
+	Status    string    `gorm:"type:varchar(20);not null" json:"status"`
+	Total     int       `gorm:"not null;default:0" json:"total"`
+	Imported  int       `gorm:"not null;default:0" json:"imported"`
+	Skipped   int       `gorm:"not null;default:0" json:"skipped"`
+	Failed    int       `gorm:"not null;default:0" json:"failed"`
+	Flagged   int       `gorm:"not null;default:0" json:"flagged"`
+	Error     *string   `gorm:"type:varchar(255)" json:"error,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Duplicates lists the rows held back for review by fuzzy deduplication
+	Duplicates ImportDuplicates `gorm:"type:text" json:"duplicates,omitempty"`
+}
+
+// ImportDuplicate is an import row that was not created because it looks
+// like an existing contact or an earlier row of the same file
+type ImportDuplicate struct {
+	Row       int    `json:"row"`
+	FullName  string `json:"full_name"`
+	Phone     string `json:"phone"`
+	MatchID   uint   `json:"match_id,omitempty"`
+	MatchRow  int    `json:"match_row,omitempty"`
+	MatchName string `json:"match_name"`
+}
+
+// ImportDuplicates is stored as a JSON text column
+type ImportDuplicates []ImportDuplicate
+
+// Value implements driver.Valuer
+func (d ImportDuplicates) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (d *ImportDuplicates) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	default:
+		return fmt.Errorf("cannot scan %T into ImportDuplicates", src)
+	}
+}
+
+// BatchContactResult reports the outcome of one item of a batch create
+type BatchContactResult struct {
+	Index int    `json:"index"`
+	ID    uint   `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ContactsVersion identifies the state of a user's contact list. Every
+// contact write changes it: creates raise LastID, deletes lower Count and
+// other writes raise Versions, the sum of the versions of every contact kept,
+// deleted or not.
+type ContactsVersion struct {
+	Count    int64
+	Versions int64
+	LastID   uint
+}
+
+// DuplicateGroup represents a cluster of contacts sharing the same key
+type DuplicateGroup struct {
+	Key      string    `json:"key"`
+	Count    int       `json:"count"`
+	Contacts []Contact `json:"contacts"`
+}
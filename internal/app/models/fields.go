@@ -0,0 +1,90 @@
+package models
+
+import "strings"
+
+// Contact field types reported by the field metadata
+const (
+	FieldTypeString   = "string"
+	FieldTypeBoolean  = "boolean"
+	FieldTypeEnum     = "enum"
+	FieldTypeDatetime = "datetime"
+	FieldTypeArray    = "array"
+)
+
+// Filter operators describe how a list query parameter matches a field
+const (
+	OperatorContains = "contains" // case-insensitive substring; q searches every such field at once
+	OperatorEquals   = "eq"       // exact value
+	OperatorExists   = "exists"   // true when the field is set, false when it is empty
+	OperatorHas      = "has"      // the array holds the value
+)
+
+// ContactField describes how contacts can be sorted and filtered by a field.
+// Param is the list query parameter filtering the field, if any; Values lists
+// the accepted values of enum fields.
+type ContactField struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Sortable  bool     `json:"sortable"`
+	Param     string   `json:"param,omitempty"`
+	Operators []string `json:"operators,omitempty"`
+	Values    []string `json:"values,omitempty"`
+}
+
+// ContactFields is the allowlist of contact fields clients may sort or filter
+// by. List request validation and GET /contacts/meta are both derived from it.
+// Sortable fields must be named after their column.
+var ContactFields = []ContactField{
+	{Name: "full_name", Type: FieldTypeString, Sortable: true, Param: "q", Operators: []string{OperatorContains}},
+	{Name: "phone", Type: FieldTypeString, Param: "q", Operators: []string{OperatorContains}},
+	{Name: "email", Type: FieldTypeString, Param: "q", Operators: []string{OperatorContains}},
+	{Name: "company", Type: FieldTypeString, Sortable: true, Param: "q", Operators: []string{OperatorContains}},
+	{Name: "job_title", Type: FieldTypeString, Param: "q", Operators: []string{OperatorContains}},
+	{Name: "avatar_url", Type: FieldTypeString, Param: "has_avatar", Operators: []string{OperatorExists}},
+	{Name: "favorite", Type: FieldTypeBoolean, Sortable: true, Param: "favorite", Operators: []string{OperatorEquals}},
+	{Name: "blocked", Type: FieldTypeBoolean, Param: "blocked", Operators: []string{OperatorEquals}},
+	{Name: "tags", Type: FieldTypeArray, Param: "tag", Operators: []string{OperatorHas}},
+	{Name: "source", Type: FieldTypeEnum, Sortable: true, Param: "source", Operators: []string{OperatorEquals}, Values: []string{
+		ContactSourceManual, ContactSourceCSVImport, ContactSourceVCardImport, ContactSourceAPI, ContactSourceShared,
+	}},
+	{Name: "created_at", Type: FieldTypeDatetime, Sortable: true},
+	{Name: "updated_at", Type: FieldTypeDatetime, Sortable: true},
+}
+
+// DefaultContactSort orders contact lists that do not ask for a sort
+const DefaultContactSort = "full_name"
+
+// SortableContactFields returns the names of the fields contacts can be sorted by
+func SortableContactFields() []string {
+	var names []string
+	for _, f := range ContactFields {
+		if f.Sortable {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// ContactSortColumn parses a sort parameter such as "full_name" or
+// "-created_at" into its column and direction. ok is false for fields that
+// are not sortable.
+func ContactSortColumn(sort string) (column string, desc bool, ok bool) {
+	name, desc := strings.CutPrefix(sort, "-")
+	for _, f := range ContactFields {
+		if f.Sortable && f.Name == name {
+			return f.Name, desc, true
+		}
+	}
+	return "", false, false
+}
+
+// contactParamValues returns the accepted values of the enum field filtered
+// by the given query parameter
+func contactParamValues(param string) []string {
+	for _, f := range ContactFields {
+		if f.Param == param && f.Type == FieldTypeEnum {
+			return f.Values
+		}
+	}
+	return nil
+}
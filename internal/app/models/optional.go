@@ -0,0 +1,42 @@
+package models
+
+import "encoding/json"
+
+// OptionalString is a JSON string field that records whether it was sent, so
+// updates can tell an omitted field (Set is false) from an explicit null (Set
+// is true, Value is nil). Use it with the omitzero tag option.
+type OptionalString struct {
+	Set   bool
+	Value *string
+}
+
+// NewOptionalString returns a set OptionalString holding value
+func NewOptionalString(value string) OptionalString {
+	return OptionalString{Set: true, Value: &value}
+}
+
+// NullString returns a set OptionalString holding null
+func NullString() OptionalString {
+	return OptionalString{Set: true}
+}
+
+// UnmarshalJSON marks the field as sent and decodes a string or null
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
+
+// MarshalJSON encodes the value, or null when it is unset
+func (o OptionalString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Value)
+}
@@ -1,9 +1,224 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sort directions accepted by the order parameter
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Contact list parameter bounds
+const (
+	DefaultListLimit = 10
+	MaxListLimit     = 100
+	MaxQueryLength   = 255
+)
+
+// ListParamsError reports list query parameters that could not be accepted.
+// Field is empty when the query string itself could not be bound.
+type ListParamsError struct {
+	Field string
+	Err   error
+}
+
+func (e *ListParamsError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *ListParamsError) Unwrap() error {
+	return e.Err
+}
+
+// ClampPagination validates page and limit: a page of 0 becomes 1, a limit of
+// 0 becomes DefaultListLimit and larger limits are capped at maxLimit, or
+// MaxListLimit when maxLimit is not positive. Negative values are reported as
+// a *ListParamsError.
+func ClampPagination(page, limit, maxLimit int) (int, int, error) {
+	if page < 0 {
+		return 0, 0, &ListParamsError{Field: "page", Err: errors.New("must not be negative")}
+	}
+	if limit < 0 {
+		return 0, 0, &ListParamsError{Field: "limit", Err: errors.New("must not be negative")}
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxListLimit
+	}
+
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = min(DefaultListLimit, maxLimit)
+	}
+	return page, min(limit, maxLimit), nil
+}
+
+// ParseListContactsRequest binds the contact list query parameters, trims the
+// search query, clamps page and limit into range as ClampPagination does and
+// computes the offset. Pages reaching past maxWindow results are rejected
+// unless maxWindow is 0. Malformed values are reported as a *ListParamsError.
+func ParseListContactsRequest(c *gin.Context, maxWindow, maxLimit int) (*ListContactsRequest, error) {
+	var req ListContactsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, &ListParamsError{Err: err}
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(req.Query) > MaxQueryLength {
+		return nil, &ListParamsError{Field: "q", Err: fmt.Errorf("must be at most %d characters", MaxQueryLength)}
+	}
+	if values := contactParamValues("source"); req.Source != "" && !slices.Contains(values, req.Source) {
+		return nil, &ListParamsError{Field: "source", Err: fmt.Errorf("must be one of %s", strings.Join(values, ", "))}
+	}
+	if _, _, ok := ContactSortColumn(req.Sort); req.Sort != "" && !ok {
+		return nil, &ListParamsError{Field: "sort", Err: fmt.Errorf("must be one of %s, optionally prefixed with -", strings.Join(SortableContactFields(), ", "))}
+	}
+	switch req.Order {
+	case "":
+	case SortAsc, SortDesc:
+		// order overrides the direction given by a - prefix
+		field := strings.TrimPrefix(req.Sort, "-")
+		if field == "" {
+			field = DefaultContactSort
+		}
+		if req.Order == SortDesc {
+			field = "-" + field
+		}
+		req.Sort = field
+	default:
+		return nil, &ListParamsError{Field: "order", Err: fmt.Errorf("must be %s or %s", SortAsc, SortDesc)}
+	}
+
+	page, limit, err := ClampPagination(req.Page, req.Limit, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	req.Page, req.Limit = page, limit
+	// Keep the offset within what the database accepts instead of letting it overflow
+	if req.Page-1 > math.MaxInt32/req.Limit {
+		return nil, &ListParamsError{Field: "page", Err: errors.New("is too large")}
+	}
+	// Deep offsets make the database scan and discard every row before the page
+	if maxWindow > 0 && int64(req.Page)*int64(req.Limit) > int64(maxWindow) {
+		return nil, &ListParamsError{Field: "page", Err: fmt.Errorf("page * limit must not exceed %d; narrow the search or filters instead of paging deeper", maxWindow)}
+	}
+	req.Offset = (req.Page - 1) * req.Limit
+
+	return &req, nil
+}
+
 // ListContactsRequest represents the paginated list request parameters
 type ListContactsRequest struct {
-	Query  string `form:"q"`
+	Query     string `form:"q"`
+	HasAvatar *bool  `form:"has_avatar"`
+	Favorite  *bool  `form:"favorite"`
+	Blocked   *bool  `form:"blocked"`
+	Tag       string `form:"tag"`
+	GroupID   uint   `form:"group_id"`
+	Source    string `form:"source"`
+	// Sort is a sortable field name, prefixed with "-" for descending order.
+	// Order, when set, replaces that prefix.
+	Sort      string `form:"sort"`
+	Order     string `form:"order"`
+	WithTotal bool   `form:"with_total"`
+	// PhoneFormat is applied by the handler when rendering contacts
+	PhoneFormat string `form:"phone_format" binding:"omitempty,oneof=e164 national international"`
+	Page        int    `form:"page,default=1"`
+	Limit       int    `form:"limit,default=10"`
+	Offset      int    `form:"-"`
+
+	// CountCapped is set by the service when Count stopped at the configured cap
+	CountCapped bool `form:"-"`
+
+	// TotalAll is set by the service to the unfiltered contact count when WithTotal is requested
+	TotalAll int64 `form:"-"`
+}
+
+// ContactFilter holds the filters applied when listing contacts
+type ContactFilter struct {
+	Query     string
+	HasAvatar *bool
+	Favorite  *bool
+	Blocked   *bool
+	Tag       string
+	GroupID   uint
+	Source    string
+	// Sort orders listed contacts as in ListContactsRequest; empty sorts by
+	// DefaultContactSort
+	Sort string
+
+	// MaxCount caps the rows counted and listed; 0 means no cap. When the
+	// filtered set is larger, the returned total is MaxCount+1.
+	MaxCount int
+}
+
+// Filter returns the contact filters carried by the request
+func (r *ListContactsRequest) Filter() ContactFilter {
+	return ContactFilter{
+		Query:     r.Query,
+		HasAvatar: r.HasAvatar,
+		Favorite:  r.Favorite,
+		Blocked:   r.Blocked,
+		Tag:       r.Tag,
+		GroupID:   r.GroupID,
+		Source:    r.Source,
+		Sort:      r.Sort,
+	}
+}
+
+// IsEmpty reports whether the filter matches every contact
+func (f ContactFilter) IsEmpty() bool {
+	return f.Query == "" && f.HasAvatar == nil && f.Favorite == nil && f.Blocked == nil && f.Tag == "" && f.GroupID == 0 && f.Source == ""
+}
+
+// TagByQueryRequest applies a tag to every contact matching the same filters
+// as ListContactsRequest. An empty filter is only accepted with Confirm set.
+type TagByQueryRequest struct {
+	Query     string `json:"q"`
+	HasAvatar *bool  `json:"has_avatar"`
+	Favorite  *bool  `json:"favorite"`
+	Blocked   *bool  `json:"blocked"`
+	Tag       string `json:"tag" binding:"required,max=64"`
+	Confirm   bool   `json:"confirm"`
+}
+
+// Filter returns the contact filters carried by the request
+func (r *TagByQueryRequest) Filter() ContactFilter {
+	return ContactFilter{
+		Query:     r.Query,
+		HasAvatar: r.HasAvatar,
+		Favorite:  r.Favorite,
+		Blocked:   r.Blocked,
+	}
+}
+
+// ListDuplicatesRequest represents the paginated duplicate-detection request parameters
+type ListDuplicatesRequest struct {
+	By     string `form:"by,default=name"`
 	Page   int    `form:"page,default=1"`
 	Limit  int    `form:"limit,default=10"`
 	Offset int    `form:"-"`
 }
+
+// AdminListContactsRequest represents the admin contact listing parameters.
+// UserID narrows the listing to one owner; IncludeDeleted also returns soft-deleted rows.
+type AdminListContactsRequest struct {
+	UserID         uint `form:"user_id"`
+	IncludeDeleted bool `form:"include_deleted"`
+	Page           int  `form:"page,default=1"`
+	Limit          int  `form:"limit,default=10"`
+	Offset         int  `form:"-"`
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListContactsRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parse := func(rawQuery string) (*ListContactsRequest, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/contacts?"+rawQuery, nil)
+		return ParseListContactsRequest(c, 0, 0)
+	}
+	yes, no := true, false
+
+	valid := []struct {
+		name  string
+		query string
+		want  ListContactsRequest
+	}{
+		{"defaults", "", ListContactsRequest{Page: 1, Limit: 10}},
+		{"explicit page and limit", "page=3&limit=20", ListContactsRequest{Page: 3, Limit: 20, Offset: 40}},
+		{"zero page is clamped", "page=0&limit=5", ListContactsRequest{Page: 1, Limit: 5}},
+		{"zero limit falls back to the default", "limit=0", ListContactsRequest{Page: 1, Limit: DefaultListLimit}},
+		{"limit is capped", "page=2&limit=5000", ListContactsRequest{Page: 2, Limit: MaxListLimit, Offset: MaxListLimit}},
+		{"query is trimmed", "q=%20%20alice%20", ListContactsRequest{Query: "alice", Page: 1, Limit: 10}},
+		{"blank query", "q=%20%20", ListContactsRequest{Page: 1, Limit: 10}},
+		{"filters are bound", "q=bob&has_avatar=true&blocked=false&tag=work", ListContactsRequest{
+			Query: "bob", HasAvatar: &yes, Blocked: &no, Tag: "work", Page: 1, Limit: 10,
+		}},
+		{"favorite filter", "favorite=true", ListContactsRequest{Favorite: &yes, Page: 1, Limit: 10}},
+		{"source filter", "source=csv_import", ListContactsRequest{Source: ContactSourceCSVImport, Page: 1, Limit: 10}},
+		{"ascending sort", "sort=full_name", ListContactsRequest{Sort: "full_name", Page: 1, Limit: 10}},
+		{"descending sort", "sort=-created_at", ListContactsRequest{Sort: "-created_at", Page: 1, Limit: 10}},
+		{"descending order", "sort=favorite&order=desc", ListContactsRequest{Sort: "-favorite", Order: SortDesc, Page: 1, Limit: 10}},
+		{"order replaces the prefix", "sort=-company&order=asc", ListContactsRequest{Sort: "company", Order: SortAsc, Page: 1, Limit: 10}},
+		{"order sorts by the default field", "order=desc", ListContactsRequest{Sort: "-" + DefaultContactSort, Order: SortDesc, Page: 1, Limit: 10}},
+		{"offset is not taken from the query", "offset=50&page=2", ListContactsRequest{Page: 2, Limit: 10, Offset: 10}},
+	}
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := parse(tc.query)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, *req)
+		})
+	}
+
+	invalid := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{"non-numeric page", "page=abc", ""},
+		{"non-numeric limit", "limit=ten", ""},
+		{"non-boolean filter", "has_avatar=maybe", ""},
+		{"unknown source", "source=fax", "source"},
+		{"unsortable field", "sort=phone", "sort"},
+		{"unknown sort field", "sort=-password", "sort"},
+		{"unknown order", "sort=full_name&order=up", "order"},
+		{"query too long", "q=" + strings.Repeat("a", MaxQueryLength+1), "q"},
+		{"negative page", "page=-4", "page"},
+		{"negative limit", "page=2&limit=-1", "limit"},
+		{"page overflowing the offset", "page=9223372036854775807&limit=100", "page"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := parse(tc.query)
+
+			assert.Nil(t, req)
+			var paramsErr *ListParamsError
+			require.ErrorAs(t, err, &paramsErr)
+			assert.Equal(t, tc.field, paramsErr.Field)
+		})
+	}
+
+	t.Run("configured limit", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/contacts?page=3&limit=80", nil)
+		req, err := ParseListContactsRequest(c, 0, 50)
+
+		require.NoError(t, err)
+		assert.Equal(t, 50, req.Limit)
+		assert.Equal(t, 100, req.Offset)
+	})
+
+	t.Run("result window", func(t *testing.T) {
+		window := func(rawQuery string) (*ListContactsRequest, error) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/contacts?"+rawQuery, nil)
+			return ParseListContactsRequest(c, 10000, 0)
+		}
+
+		req, err := window("page=100&limit=100")
+		require.NoError(t, err)
+		assert.Equal(t, 9900, req.Offset)
+
+		req, err = window("page=101&limit=100")
+		assert.Nil(t, req)
+		var paramsErr *ListParamsError
+		require.ErrorAs(t, err, &paramsErr)
+		assert.Equal(t, "page", paramsErr.Field)
+		assert.Contains(t, err.Error(), "10000")
+
+		req, err = window("page=1001")
+		assert.Nil(t, req)
+		assert.Error(t, err)
+	})
+}
+
+func TestClampPagination(t *testing.T) {
+	cases := []struct {
+		name                  string
+		page, limit, maxLimit int
+		wantPage, wantLimit   int
+	}{
+		{"in range", 2, 20, 100, 2, 20},
+		{"zero page", 0, 20, 100, 1, 20},
+		{"zero limit", 1, 0, 100, 1, DefaultListLimit},
+		{"zero limit under a smaller max", 1, 0, 5, 1, 5},
+		{"limit over the max", 1, 10000, 100, 1, 100},
+		{"unset max", 1, 10000, 0, 1, MaxListLimit},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, limit, err := ClampPagination(tc.page, tc.limit, tc.maxLimit)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPage, page)
+			assert.Equal(t, tc.wantLimit, limit)
+		})
+	}
+
+	for name, params := range map[string][2]int{"negative page": {-1, 10}, "negative limit": {1, -10}} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ClampPagination(params[0], params[1], 100)
+
+			var paramsErr *ListParamsError
+			require.ErrorAs(t, err, &paramsErr)
+		})
+	}
+}
@@ -11,6 +11,9 @@ type RegisterRequest struct {
 	Email    string  `json:"email" binding:"required,email"`
 	Phone    *string `json:"phone,omitempty"`
 	Password string  `json:"password" binding:"required,min=8"`
+
+	// InviteCode allows registration while open registration is disabled
+	InviteCode string `json:"invite_code,omitempty"`
 }
 
 // LoginRequest represents the login request structure
@@ -21,23 +24,141 @@ type LoginRequest struct {
 
 // UpdateProfileRequest represents the profile update request structure
 type UpdateProfileRequest struct {
-	FullName string  `json:"full_name" binding:"required"`
-	Phone    *string `json:"phone,omitempty"`
+	FullName       string  `json:"full_name" binding:"required"`
+	Phone          *string `json:"phone,omitempty"`
+	DirectoryOptIn *bool   `json:"directory_opt_in,omitempty"`
+}
+
+// PatchProfileRequest represents the partial profile update request
+// structure; only the fields sent are changed
+type PatchProfileRequest struct {
+	FullName       *string `json:"full_name,omitempty"`
+	Phone          *string `json:"phone,omitempty"`
+	DirectoryOptIn *bool   `json:"directory_opt_in,omitempty"`
+}
+
+// DirectoryLookupRequest represents a batch phone number lookup
+type DirectoryLookupRequest struct {
+	Phones []string `json:"phones" binding:"required"`
+}
+
+// VerifyPasswordRequest represents the password re-confirmation request structure
+type VerifyPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordRequest represents the password change request structure
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// ForgotPasswordRequest represents the password reset request structure
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the password reset confirmation structure
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
 
 // CreateContactRequest represents the create contact request structure
 type CreateContactRequest struct {
-	FullName string  `json:"full_name" binding:"required"`
-	Phone    string  `json:"phone" binding:"required"`
-	Email    *string `json:"email"`
+	FullName  string  `json:"full_name" binding:"required"`
+	Phone     string  `json:"phone" binding:"required"`
+	Email     *string `json:"email"`
+	AvatarURL *string `json:"avatar_url" binding:"omitempty,url"`
+	Company   *string `json:"company" binding:"omitempty,max=255"`
+	JobTitle  *string `json:"job_title" binding:"omitempty,max=255"`
+	Blocked   bool    `json:"blocked"`
 }
 
-// UpdateContactRequest represents the update contact request structure
+// UpdateContactRequest represents the update contact request structure.
+// Email is left unchanged when omitted and cleared when null or empty.
 type UpdateContactRequest struct {
-	FullName string  `json:"full_name" binding:"required"`
-	Phone    string  `json:"phone" binding:"required"`
-	Email    *string `json:"email"`
-	Favorite bool    `json:"favorite"`
+	FullName  string         `json:"full_name" binding:"required"`
+	Phone     string         `json:"phone" binding:"required"`
+	Email     OptionalString `json:"email,omitzero" example:"user@example.com"`
+	AvatarURL *string        `json:"avatar_url" binding:"omitempty,url"`
+	Company   *string        `json:"company" binding:"omitempty,max=255"`
+	JobTitle  *string        `json:"job_title" binding:"omitempty,max=255"`
+	Favorite  *bool          `json:"favorite"`
+	Blocked   *bool          `json:"blocked"`
+}
+
+// Patch returns the update as a patch setting every required field and the
+// optional fields that were sent
+func (r *UpdateContactRequest) Patch() *PatchContactRequest {
+	return &PatchContactRequest{
+		FullName:  &r.FullName,
+		Phone:     &r.Phone,
+		Email:     r.Email,
+		AvatarURL: r.AvatarURL,
+		Company:   r.Company,
+		JobTitle:  r.JobTitle,
+		Favorite:  r.Favorite,
+		Blocked:   r.Blocked,
+	}
+}
+
+// PatchContactRequest represents the partial contact update request
+// structure. Only the fields sent are changed; a null or empty email clears
+// it.
+type PatchContactRequest struct {
+	FullName  *string        `json:"full_name" binding:"omitempty,max=255"`
+	Phone     *string        `json:"phone"`
+	Email     OptionalString `json:"email,omitzero" example:"user@example.com"`
+	AvatarURL *string        `json:"avatar_url" binding:"omitempty,url"`
+	Company   *string        `json:"company" binding:"omitempty,max=255"`
+	JobTitle  *string        `json:"job_title" binding:"omitempty,max=255"`
+	Favorite  *bool          `json:"favorite"`
+	Blocked   *bool          `json:"blocked"`
+}
+
+// Updates returns the sent fields keyed by column, for PatchContact
+func (r *PatchContactRequest) Updates() map[string]interface{} {
+	updates := map[string]interface{}{}
+	if r.FullName != nil {
+		updates["full_name"] = *r.FullName
+	}
+	if r.Phone != nil {
+		updates["phone"] = *r.Phone
+	}
+	if r.Email.Set {
+		updates["email"] = r.Email.Value
+	}
+	for column, value := range map[string]*string{"avatar_url": r.AvatarURL, "company": r.Company, "job_title": r.JobTitle} {
+		if value != nil {
+			updates[column] = value
+		}
+	}
+	if r.Favorite != nil {
+		updates["favorite"] = *r.Favorite
+	}
+	if r.Blocked != nil {
+		updates["blocked"] = *r.Blocked
+	}
+	return updates
+}
+
+// MergeContactsRequest represents the merge duplicate contacts request structure
+type MergeContactsRequest struct {
+	PrimaryID    uint   `json:"primary_id" binding:"required"`
+	DuplicateIDs []uint `json:"duplicate_ids" binding:"required"`
+}
+
+// ContactGroupRequest represents the create and rename group request structure
+type ContactGroupRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// CreateInviteCodeRequest represents the invite code minting request structure
+type CreateInviteCodeRequest struct {
+	Code           string `json:"code"`
+	Uses           int    `json:"uses" binding:"required,min=1"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"`
 }
 
 // Response represents the standard API response structure
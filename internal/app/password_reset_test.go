@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureSender records the reset tokens it is asked to send
+type captureSender struct {
+	tokens []string
+}
+
+func (s *captureSender) SendPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error {
+	s.tokens = append(s.tokens, token)
+	return nil
+}
+
+// failingUserUpdates makes UpdateUser fail while failing is set, also inside transactions
+type failingUserUpdates struct {
+	repository.Repository
+	failing *bool
+}
+
+func (r failingUserUpdates) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	return r.Repository.WithTx(ctx, func(tx repository.Repository) error {
+		return fn(failingUserUpdates{Repository: tx, failing: r.failing})
+	})
+}
+
+func (r failingUserUpdates) UpdateUser(ctx context.Context, id uint, updates map[string]interface{}) (*models.User, error) {
+	if *r.failing {
+		return nil, errors.New("write failed")
+	}
+	return r.Repository.UpdateUser(ctx, id, updates)
+}
+
+func TestService_PasswordReset(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	sender := &captureSender{}
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithPasswordReset(time.Hour, sender))
+
+	requestToken := func(t *testing.T) string {
+		t.Helper()
+		require.NoError(t, svc.RequestPasswordReset(ctx, user.Email))
+		require.NotEmpty(t, sender.tokens)
+		return sender.tokens[len(sender.tokens)-1]
+	}
+
+	t.Run("unknown emails are ignored", func(t *testing.T) {
+		assert.NoError(t, svc.RequestPasswordReset(ctx, "nobody@example.com"))
+		assert.Empty(t, sender.tokens)
+	})
+
+	t.Run("token is stored hashed", func(t *testing.T) {
+		resetToken := requestToken(t)
+
+		var count int64
+		require.NoError(t, tdb.DB.Model(&models.PasswordResetToken{}).Where("token_hash = ?", resetToken).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("resets the password once", func(t *testing.T) {
+		resetToken := requestToken(t)
+
+		require.NoError(t, svc.ResetPassword(ctx, resetToken, "brandnewpass"))
+		_, err := svc.Login(ctx, models.LoginRequest{Email: user.Email, Password: "brandnewpass"})
+		assert.NoError(t, err)
+
+		err = svc.ResetPassword(ctx, resetToken, "anotherpass1")
+		assert.Equal(t, service.ErrResetTokenUsed, err)
+		_, err = svc.Login(ctx, models.LoginRequest{Email: user.Email, Password: "anotherpass1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("failed password write leaves the token usable", func(t *testing.T) {
+		failing := true
+		failingSvc := service.NewService(failingUserUpdates{Repository: repo, failing: &failing}, token.NewService(GetTestJWTSecret()),
+			service.WithPasswordReset(time.Hour, sender))
+		resetToken := requestToken(t)
+
+		assert.Error(t, failingSvc.ResetPassword(ctx, resetToken, "lostpass123"))
+
+		failing = false
+		require.NoError(t, failingSvc.ResetPassword(ctx, resetToken, "retriedpass1"))
+		_, err := svc.Login(ctx, models.LoginRequest{Email: user.Email, Password: "retriedpass1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		resetToken := requestToken(t)
+		require.NoError(t, tdb.DB.Model(&models.PasswordResetToken{}).Where("used_at IS NULL").
+			Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+		err := svc.ResetPassword(ctx, resetToken, "expiredpass1")
+
+		assert.Equal(t, service.ErrResetTokenExpired, err)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		err := svc.ResetPassword(ctx, "not-a-token", "whatever123")
+
+		assert.Equal(t, service.ErrResetTokenInvalid, err)
+	})
+}
+
+func TestLogResetSender(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		sender service.LogResetSender
+		logged bool
+	}{
+		{"token is left out by default", service.LogResetSender{}, false},
+		{"token is logged when included", service.LogResetSender{IncludeToken: true}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			previous := logger.SetDefault(logger.New(&buf))
+			defer logger.SetDefault(previous)
+
+			user := models.User{ID: 7, Email: "reset@example.com"}
+			require.NoError(t, tc.sender.SendPasswordReset(context.Background(), user, "secret-reset-token", time.Now().Add(time.Hour)))
+
+			assert.Contains(t, buf.String(), "reset@example.com")
+			assert.Equal(t, tc.logged, strings.Contains(buf.String(), "secret-reset-token"))
+		})
+	}
+}
+
+func TestHandler_PasswordReset(t *testing.T) {
+	post := func(mockService *MockService, path, body string) *httptest.ResponseRecorder {
+		router := setupTestRouter(mockService)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("forgot password answers 200 even when sending fails", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("RequestPasswordReset", mock.Anything, "john@example.com").Return(assert.AnError).Once()
+
+		w := post(mockService, "/api/v1/auth/forgot-password", `{"email":"john@example.com"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "If the email is registered")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("expired reset token", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("ResetPassword", mock.Anything, "abc", "newpassword1").Return(service.ErrResetTokenExpired).Once()
+
+		w := post(mockService, "/api/v1/auth/reset-password", `{"token":"abc","new_password":"newpassword1"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("new password too short", func(t *testing.T) {
+		mockService := new(MockService)
+
+		w := post(mockService, "/api/v1/auth/reset-password", `{"token":"abc","new_password":"short"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
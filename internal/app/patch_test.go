@@ -0,0 +1,174 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PatchContact(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	str := func(s string) *string { return &s }
+	contact, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Jane Doe", Phone: "14155550001",
+		Email: str("jane@example.com"), Company: str("Acme")})
+	require.NoError(t, err)
+	other, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "John Roe", Phone: "14155550002"})
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+	patch := func(updates map[string]interface{}) (*models.Contact, error) {
+		return svc.PatchContact(ctx, user.ID, contact.ID, updates)
+	}
+
+	t.Run("email only", func(t *testing.T) {
+		updated, err := patch(map[string]interface{}{"email": str("jane.doe@example.com")})
+		require.NoError(t, err)
+		assert.Equal(t, "jane.doe@example.com", *updated.Email)
+		assert.Equal(t, "Jane Doe", updated.FullName)
+		assert.Equal(t, "14155550001", updated.Phone)
+		assert.Equal(t, "Acme", *updated.Company)
+	})
+
+	t.Run("full name only", func(t *testing.T) {
+		updated, err := patch(map[string]interface{}{"full_name": "  Jane   Smith "})
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Smith", updated.FullName)
+		assert.Equal(t, "jane.doe@example.com", *updated.Email)
+
+		_, err = patch(map[string]interface{}{"full_name": " "})
+		assert.ErrorIs(t, err, service.ErrFullNameRequired)
+	})
+
+	t.Run("phone only", func(t *testing.T) {
+		updated, err := patch(map[string]interface{}{"phone": "+1 (415) 555-0003"})
+		require.NoError(t, err)
+		assert.Equal(t, "14155550003", updated.Phone)
+
+		// Keeping its own number is no conflict, taking another contact's is
+		_, err = patch(map[string]interface{}{"phone": "+14155550003"})
+		assert.NoError(t, err)
+		_, err = patch(map[string]interface{}{"phone": other.Phone})
+		assert.ErrorIs(t, err, service.ErrPhoneExists)
+		_, err = patch(map[string]interface{}{"phone": "not a phone"})
+		assert.ErrorIs(t, err, service.ErrInvalidPhone)
+	})
+
+	t.Run("favorite only", func(t *testing.T) {
+		updated, err := patch(map[string]interface{}{"favorite": true})
+		require.NoError(t, err)
+		assert.True(t, updated.Favorite)
+		assert.Equal(t, "Jane Smith", updated.FullName)
+	})
+
+	t.Run("company is cleared with an empty string", func(t *testing.T) {
+		updated, err := patch(map[string]interface{}{"company": str("  ")})
+		require.NoError(t, err)
+		assert.Nil(t, updated.Company)
+		assert.True(t, updated.Favorite)
+	})
+
+	t.Run("email is cleared with null or an empty string", func(t *testing.T) {
+		for _, email := range []*string{nil, str(""), str("  ")} {
+			_, err := patch(map[string]interface{}{"email": str("jane@example.com")})
+			require.NoError(t, err)
+
+			updated, err := patch(map[string]interface{}{"email": email})
+			require.NoError(t, err)
+			assert.Nil(t, updated.Email)
+
+			stored, err := repo.GetContact(ctx, user.ID, contact.ID)
+			require.NoError(t, err)
+			assert.Nil(t, stored.Email)
+		}
+
+		_, err := patch(map[string]interface{}{"email": str("not an email")})
+		assert.ErrorIs(t, err, service.ErrInvalidEmail)
+	})
+
+	t.Run("update leaves an omitted email and clears an empty one", func(t *testing.T) {
+		_, err := patch(map[string]interface{}{"email": str("jane@example.com")})
+		require.NoError(t, err)
+		update := func(email models.OptionalString) *models.Contact {
+			t.Helper()
+			updated, err := svc.UpdateContact(ctx, user.ID, contact.ID, &models.UpdateContactRequest{
+				FullName: "Jane Smith", Phone: "14155550003", Email: email})
+			require.NoError(t, err)
+			return updated
+		}
+
+		updated := update(models.OptionalString{})
+		require.NotNil(t, updated.Email)
+		assert.Equal(t, "jane@example.com", *updated.Email)
+
+		assert.Nil(t, update(models.NewOptionalString("")).Email)
+		stored, err := repo.GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.Email)
+	})
+
+	t.Run("nothing to change", func(t *testing.T) {
+		unchanged, err := patch(map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Smith", unchanged.FullName)
+	})
+
+	t.Run("unknown fields and contacts", func(t *testing.T) {
+		_, err := patch(map[string]interface{}{"user_id": uint(2)})
+		assert.ErrorIs(t, err, service.ErrInvalidPatchField)
+		_, err = svc.PatchContact(ctx, user.ID, 9999, map[string]interface{}{"favorite": false})
+		assert.ErrorIs(t, err, service.ErrContactNotFound)
+	})
+}
+
+func TestHandler_PatchContact(t *testing.T) {
+	patch := func(svc *MockService, body string) *httptest.ResponseRecorder {
+		router := setupTestRouter(svc)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/api/v1/contacts/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("only sent fields are passed on", func(t *testing.T) {
+		email := "jane@example.com"
+		mockService := new(MockService)
+		mockService.On("PatchContact", mock.Anything, uint(1), uint(1), map[string]interface{}{"email": &email}).
+			Return(&models.Contact{ID: 1, Email: &email}, nil).Once()
+		mockService.On("PatchContact", mock.Anything, uint(1), uint(1), map[string]interface{}{"email": (*string)(nil), "blocked": true}).
+			Return(&models.Contact{ID: 1, Blocked: true}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, patch(mockService, `{"email": "jane@example.com"}`).Code)
+		assert.Equal(t, http.StatusOK, patch(mockService, `{"email": null, "blocked": true}`).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		mockService := new(MockService)
+		assert.Equal(t, http.StatusBadRequest, patch(mockService, `{"email": "not-an-email"}`).Code)
+		mockService.AssertNotCalled(t, "PatchContact", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("phone taken", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("PatchContact", mock.Anything, uint(1), uint(1), map[string]interface{}{"phone": "+14155550002"}).
+			Return(nil, service.ErrPhoneExists)
+
+		assert.Equal(t, http.StatusConflict, patch(mockService, `{"phone": "+14155550002"}`).Code)
+	})
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"user-service/internal/app/migrations"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueUserPhone(t *testing.T) {
+	register := func(svc service.Service, email, phone string) error {
+		_, _, err := svc.Register(context.Background(), models.RegisterRequest{
+			FullName: email,
+			Email:    email,
+			Phone:    stringPtr(phone),
+			Password: "password123",
+		})
+		return err
+	}
+
+	t.Run("duplicate phone registration is rejected", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithUniqueUserPhone(true))
+
+		require.NoError(t, register(svc, "first@example.com", "5551234567"))
+		assert.Equal(t, service.ErrPhoneTaken, register(svc, "second@example.com", "5551234567"))
+		assert.NoError(t, register(svc, "third@example.com", "5559876543"))
+	})
+
+	t.Run("constraint violation is translated", func(t *testing.T) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		_, err := testDB.SqlDB.Exec("CREATE UNIQUE INDEX " + migrations.UniqueUserPhoneIndex + " ON users (phone)")
+		require.NoError(t, err)
+
+		// Without the service-level check only the database constraint can catch the clash
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+
+		require.NoError(t, register(svc, "first@example.com", "5551234567"))
+		assert.Equal(t, service.ErrPhoneTaken, register(svc, "second@example.com", "5551234567"))
+	})
+
+	t.Run("duplicates are reported before applying", func(t *testing.T) {
+		testDB, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+		for _, u := range []struct{ email, phone string }{
+			{"a@example.com", "5550000001"},
+			{"b@example.com", "5550000001"},
+			{"c@example.com", "5550000001"},
+			{"d@example.com", "5550000002"},
+			{"e@example.com", "5550000003"},
+			{"f@example.com", "5550000003"},
+		} {
+			require.NoError(t, register(svc, u.email, u.phone))
+		}
+
+		duplicates, err := migrations.FindDuplicateUserPhones(testDB.SqlDB)
+
+		require.NoError(t, err)
+		assert.Equal(t, []migrations.DuplicatePhone{
+			{Phone: "5550000001", Count: 3},
+			{Phone: "5550000003", Count: 2},
+		}, duplicates)
+	})
+}
+
+func TestRegistrationPhonePolicy(t *testing.T) {
+	register := func(svc service.Service, email string) error {
+		_, _, err := svc.Register(context.Background(), models.RegisterRequest{
+			FullName: email,
+			Email:    email,
+			Phone:    stringPtr("5551234567"),
+			Password: "password123",
+		})
+		return err
+	}
+
+	for _, tc := range []struct {
+		policy  string
+		wantErr error
+	}{
+		{service.PhonePolicyAllow, nil},
+		{service.PhonePolicyWarn, nil},
+		{service.PhonePolicyBlock, service.ErrPhoneTaken},
+		{"unknown", nil},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			_, repo, cleanup := SetupTestEnvironment(t)
+			defer cleanup()
+
+			svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithRegistrationPhonePolicy(tc.policy))
+
+			require.NoError(t, register(svc, "first@example.com"))
+			assert.Equal(t, tc.wantErr, register(svc, "second@example.com"))
+		})
+	}
+
+	t.Run("unique user phones always block", func(t *testing.T) {
+		_, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		svc := service.NewService(repo, token.NewService(GetTestJWTSecret()),
+			service.WithUniqueUserPhone(true),
+			service.WithRegistrationPhonePolicy(service.PhonePolicyAllow),
+		)
+
+		require.NoError(t, register(svc, "first@example.com"))
+		assert.Equal(t, service.ErrPhoneTaken, register(svc, "second@example.com"))
+	})
+}
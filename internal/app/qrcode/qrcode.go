@@ -0,0 +1,459 @@
+// Package qrcode encodes data as QR code symbols (ISO/IEC 18004, byte mode)
+// and renders them as PNG images.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// Level is the error correction level of a symbol
+type Level int
+
+const (
+	Low      Level = iota // recovers about 7% of codewords
+	Medium                // recovers about 15% of codewords
+	Quartile              // recovers about 25% of codewords
+	High                  // recovers about 30% of codewords
+)
+
+// quietZone is the light border around a symbol, in modules
+const quietZone = 4
+
+var (
+	ErrTooLong      = errors.New("data too long for a QR code")
+	ErrInvalidLevel = errors.New("invalid QR error correction level")
+)
+
+// ParseLevel parses an error correction level given as L, M, Q or H
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L":
+		return Low, nil
+	case "M":
+		return Medium, nil
+	case "Q":
+		return Quartile, nil
+	case "H":
+		return High, nil
+	}
+	return 0, ErrInvalidLevel
+}
+
+// Code is an encoded QR symbol
+type Code struct {
+	Version int
+	Level   Level
+	Size    int // width and height in modules
+
+	modules    []bool
+	isFunction []bool
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in the smallest symbol that holds it at the given level
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, ErrInvalidLevel
+	}
+
+	version := 1
+	for ; version <= 40; version++ {
+		if 4+charCountBits(version)+8*len(data) <= 8*numDataCodewords(version, level) {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, ErrTooLong
+	}
+
+	c := newCode(version, level)
+	c.drawCodewords(addErrorCorrection(encodeData(data, version, level), version, level))
+	c.applyBestMask()
+	return c, nil
+}
+
+// Image renders the symbol with its quiet zone, scaled to whole-pixel modules
+// as close to size pixels wide as possible without exceeding it
+func (c *Code) Image(size int) image.Image {
+	width := c.Size + 2*quietZone
+	scale := size / width
+	if scale < 1 {
+		scale = 1
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width*scale, width*scale))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetGray((x+quietZone)*scale+px, (y+quietZone)*scale+py, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG encodes data as a QR code and renders it as a PNG about size pixels wide
+func PNG(data []byte, level Level, size int) ([]byte, error) {
+	c, err := Encode(data, level)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newCode returns a symbol with its function patterns drawn and the format
+// bits reserved
+func newCode(version int, level Level) *Code {
+	size := 4*version + 17
+	c := &Code{
+		Version:    version,
+		Level:      level,
+		Size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+
+	for i := 0; i < size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(size-4, 3)
+	c.drawFinder(3, size-4)
+
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the three corners taken by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(x, y)
+		}
+	}
+
+	c.drawFormat(0)
+	c.drawVersion()
+	return c
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.isFunction[y*c.Size+x] = true
+}
+
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= c.Size || y < 0 || y >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for mask
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(c.Level, mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	// The dark module is always set
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion draws both copies of the version information from version 7 up
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places codewords in the two-module wide zigzag that runs up
+// and down from the bottom right corner, skipping function modules
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// The vertical timing pattern takes a whole column
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.isFunction[y*c.Size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y*c.Size+x] = codewords[i>>3]>>(7-i&7)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask pattern with the lowest penalty score
+func (c *Code) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		// Masks are XOR, so applying one again removes it
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.isFunction[y*c.Size+x] && masked(mask, x, y) {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// masked reports whether mask pattern mask inverts the module at x, y
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty scores the symbol by the four mask evaluation rules: long runs,
+// 2x2 blocks, finder-like patterns and dark/light imbalance
+func (c *Code) penalty() int {
+	penalty := 0
+	line := make([]bool, c.Size)
+	for _, horizontal := range []bool{true, false} {
+		for i := 0; i < c.Size; i++ {
+			for j := 0; j < c.Size; j++ {
+				if horizontal {
+					line[j] = c.Dark(j, i)
+				} else {
+					line[j] = c.Dark(i, j)
+				}
+			}
+			penalty += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				dark++
+			}
+			if x < c.Size-1 && y < c.Size-1 {
+				v := c.Dark(x, y)
+				if v == c.Dark(x+1, y) && v == c.Dark(x, y+1) && v == c.Dark(x+1, y+1) {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	percent := dark * 100 / (c.Size * c.Size)
+	penalty += abs(percent-50) / 5 * 10
+	return penalty
+}
+
+var finderLike = [...][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func linePenalty(line []bool) int {
+	penalty := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range finderLike {
+			match := true
+			for j, dark := range pattern {
+				if line[i+j] != dark {
+					match = false
+					break
+				}
+			}
+			if match {
+				penalty += 40
+			}
+		}
+	}
+	return penalty
+}
+
+// encodeData builds the data codewords: byte mode indicator, character count,
+// the data, a terminator and padding up to the symbol's data capacity
+func encodeData(data []byte, version int, level Level) []byte {
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	capacity := 8 * numDataCodewords(version, level)
+	bb.append(0, min(4, capacity-bb.len))
+	bb.append(0, (8-bb.len%8)%8)
+	for pad := 0xEC; bb.len < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	return bb.bytes
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon error
+// correction to each and interleaves the result
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	eccLen := eccCodewordsPerBlock[level][version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortDataLen := rawCodewords/numBlocks - eccLen
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	eccs := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortDataLen
+		if i >= numShortBlocks {
+			n++
+		}
+		blocks[i] = data[k : k+n]
+		eccs[i] = reedSolomonRemainder(blocks[i], divisor)
+		k += n
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i <= shortDataLen; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for _, ecc := range eccs {
+			result = append(result, ecc[i])
+		}
+	}
+	return result
+}
+
+type bitBuffer struct {
+	bytes []byte
+	len   int
+}
+
+// append writes the low n bits of v, most significant first
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if bb.len%8 == 0 {
+			bb.bytes = append(bb.bytes, 0)
+		}
+		if v>>i&1 == 1 {
+			bb.bytes[bb.len/8] |= 0x80 >> (bb.len % 8)
+		}
+		bb.len++
+	}
+}
+
+func bit(v, i int) bool {
+	return v>>i&1 == 1
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
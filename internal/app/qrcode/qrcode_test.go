@@ -0,0 +1,238 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, 0x77C4, formatBits(Low, 0))
+	assert.Equal(t, 0x5412, formatBits(Medium, 0))
+	assert.Equal(t, 0x07C94, versionBits(7))
+	assert.Equal(t, 0x28C69, versionBits(40))
+}
+
+func TestByteCapacity(t *testing.T) {
+	capacity := func(version int, level Level) int {
+		return (8*numDataCodewords(version, level) - 4 - charCountBits(version)) / 8
+	}
+
+	assert.Equal(t, []int{17, 14, 11, 7}, []int{capacity(1, Low), capacity(1, Medium), capacity(1, Quartile), capacity(1, High)})
+	assert.Equal(t, []int{271, 213, 151, 119}, []int{capacity(10, Low), capacity(10, Medium), capacity(10, Quartile), capacity(10, High)})
+	assert.Equal(t, 2953, capacity(40, Low))
+	assert.Equal(t, 1273, capacity(40, High))
+}
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as 1-M, the worked example from the specification's annex
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	assert.Nil(t, alignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPositions(32))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPositions(40))
+}
+
+func TestEncode_ScansBack(t *testing.T) {
+	vcard := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Jane Doe\r\nN:Doe;Jane;;;\r\nTEL;TYPE=CELL:14155552671\r\nEND:VCARD\r\n"
+
+	for _, tc := range []struct {
+		name  string
+		data  string
+		level Level
+	}{
+		{"short", "hello", Low},
+		{"vcard", vcard, Medium},
+		{"version info", strings.Repeat(vcard, 3), Quartile},
+		{"many blocks", strings.Repeat("0123456789abcdef", 40), High},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := PNG([]byte(tc.data), tc.level, 300)
+			require.NoError(t, err)
+
+			img, err := png.Decode(bytes.NewReader(out))
+			require.NoError(t, err)
+
+			decoded, level, err := scan(img)
+			require.NoError(t, err)
+			assert.Equal(t, tc.level, level)
+			assert.Equal(t, tc.data, string(decoded))
+		})
+	}
+}
+
+func TestEncode_PicksSmallestVersion(t *testing.T) {
+	c, err := Encode(bytes.Repeat([]byte("a"), 14), Medium)
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Version)
+	assert.Equal(t, 21, c.Size)
+
+	c, err = Encode(bytes.Repeat([]byte("a"), 15), Medium)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Version)
+}
+
+func TestEncode_TooLong(t *testing.T) {
+	_, err := Encode(make([]byte, 2954), Low)
+	assert.Equal(t, ErrTooLong, err)
+}
+
+func TestImage_Size(t *testing.T) {
+	c, err := Encode([]byte("hello"), Low)
+	require.NoError(t, err)
+
+	// 21 modules plus the quiet zone make 29; 300 px fits 10 px per module
+	assert.Equal(t, image.Rect(0, 0, 290, 290), c.Image(300).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 29, 29), c.Image(10).Bounds())
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("q")
+	require.NoError(t, err)
+	assert.Equal(t, Quartile, level)
+
+	_, err = ParseLevel("X")
+	assert.Equal(t, ErrInvalidLevel, err)
+}
+
+// scan reads back an undistorted symbol rendered by Image: it samples the
+// module grid, reads the format information, removes the mask, collects the
+// codewords, checks their error correction and parses the byte mode segment
+func scan(img image.Image) ([]byte, Level, error) {
+	b := img.Bounds()
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r < 0x8000
+	}
+
+	// The top-left finder starts right after the quiet zone on the diagonal
+	offset := 0
+	for offset < b.Dx() && !dark(offset, offset) {
+		offset++
+	}
+	scale := offset / quietZone
+	if scale == 0 {
+		return nil, 0, errors.New("no finder pattern")
+	}
+	size := b.Dx()/scale - 2*quietZone
+	version := (size - 17) / 4
+	if version < 1 || version > 40 || 4*version+17 != size {
+		return nil, 0, fmt.Errorf("unexpected symbol size %d", size)
+	}
+
+	grid := make([]bool, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			grid[y*size+x] = dark((x+quietZone)*scale+scale/2, (y+quietZone)*scale+scale/2)
+		}
+	}
+	module := func(x, y int) bool { return grid[y*size+x] }
+
+	format := 0
+	for i := 0; i <= 5; i++ {
+		format |= boolBit(module(8, i)) << i
+	}
+	format |= boolBit(module(8, 7))<<6 | boolBit(module(8, 8))<<7 | boolBit(module(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		format |= boolBit(module(14-i, 8)) << i
+	}
+	level, mask, ok := Level(0), 0, false
+	for l := Low; l <= High && !ok; l++ {
+		for m := 0; m < 8; m++ {
+			if formatBits(l, m) == format {
+				level, mask, ok = l, m, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, 0, errors.New("unreadable format information")
+	}
+
+	function := newCode(version, level).isFunction
+	var bb bitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = size - 1 - vert
+			}
+			for x := right; x >= right-1; x-- {
+				if !function[y*size+x] {
+					bb.append(boolBit(module(x, y) != masked(mask, x, y)), 1)
+				}
+			}
+		}
+	}
+	raw := bb.bytes[:numRawDataModules(version)/8]
+
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	eccLen := eccCodewordsPerBlock[level][version]
+	numShortBlocks := numBlocks - len(raw)%numBlocks
+	shortDataLen := len(raw)/numBlocks - eccLen
+
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortDataLen; i++ {
+		for j := range blocks {
+			if i < shortDataLen || j >= numShortBlocks {
+				blocks[j] = append(blocks[j], raw[k])
+				k++
+			}
+		}
+	}
+	divisor := reedSolomonDivisor(eccLen)
+	var data []byte
+	for j, block := range blocks {
+		ecc := make([]byte, eccLen)
+		for i := range ecc {
+			ecc[i] = raw[k+i*numBlocks+j]
+		}
+		if !bytes.Equal(reedSolomonRemainder(block, divisor), ecc) {
+			return nil, 0, fmt.Errorf("block %d fails error correction", j)
+		}
+		data = append(data, block...)
+	}
+
+	read := func(pos, n int) int {
+		v := 0
+		for i := pos; i < pos+n; i++ {
+			v = v<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 0x4 {
+		return nil, 0, fmt.Errorf("unexpected mode %#x", mode)
+	}
+	count := read(4, charCountBits(version))
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = byte(read(4+charCountBits(version)+8*i, 8))
+	}
+	return out, level, nil
+}
+
+func boolBit(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
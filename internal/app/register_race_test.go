@@ -0,0 +1,63 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister_ConcurrentSameEmail(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	router.POST("/auth/register", h.Register)
+
+	const attempts = 2
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		codes = make([]int, attempts)
+		body  = make([]string, attempts)
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPost, "/auth/register",
+				bytes.NewBufferString(`{"full_name":"Racer","email":"racer@example.com","password":"password123"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			<-start
+			router.ServeHTTP(w, req)
+			codes[i], body[i] = w.Code, w.Body.String()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	sorted := append([]int(nil), codes...)
+	sort.Ints(sorted)
+	require.Equal(t, []int{http.StatusCreated, http.StatusConflict}, sorted, "responses: %v", body)
+	for i, code := range codes {
+		if code == http.StatusConflict {
+			assert.Contains(t, body[i], service.ErrEmailTaken.Error())
+		}
+	}
+
+	user, err := repo.GetUserByEmail(t.Context(), "racer@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Racer", user.FullName)
+}
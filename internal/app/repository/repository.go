@@ -2,23 +2,81 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
+	"user-service/internal/app/fieldcrypt"
 	"user-service/internal/app/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	CheckUserPhoneExists(ctx context.Context, phone string, excludeUserID uint) (bool, error)
+	LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error)
 	UpdateUser(ctx context.Context, userID uint, updates map[string]interface{}) (*models.User, error)
+	UpdateLastLogin(ctx context.Context, userID uint, at time.Time) error
+	RecordFailedLogin(ctx context.Context, userID uint, maxAttempts int, lockUntil time.Time) (bool, error)
+	DeleteUser(ctx context.Context, userID uint) error
 
-	ListContacts(ctx context.Context, userID uint, query string, offset, limit int) ([]models.Contact, int64, error)
+	ListInactiveUsers(ctx context.Context, cutoff time.Time) ([]models.User, error)
+	MarkInactivityWarned(ctx context.Context, userID uint, at time.Time) error
+	FlagUserInactive(ctx context.Context, userID uint, at time.Time) error
+
+	CreateInviteCode(ctx context.Context, code *models.InviteCode) (*models.InviteCode, error)
+	GetInviteCode(ctx context.Context, code string) (*models.InviteCode, error)
+	ListInviteCodes(ctx context.Context) ([]models.InviteCode, error)
+	ConsumeInviteCode(ctx context.Context, code string, now time.Time) (bool, error)
+
+	RevokeToken(ctx context.Context, revoked *models.RevokedToken, now time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	CreatePasswordResetToken(ctx context.Context, reset *models.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (bool, error)
+
+	ListContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int) ([]models.Contact, int64, error)
+	StreamContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int, fn func(*models.Contact) error) error
 	CreateContact(ctx context.Context, contact *models.Contact) (*models.Contact, error)
+	CreateContacts(ctx context.Context, contacts []*models.Contact) error
+	CountContacts(ctx context.Context, userID uint) (int64, error)
+	ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error)
+	TagContacts(ctx context.Context, userID uint, filter models.ContactFilter, tag string) ([]uint, error)
 	GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error)
-	CheckContactExists(ctx context.Context, userID uint, phone string) (bool, error)
+	CheckContactExists(ctx context.Context, userID uint, phone string, excludeContactID uint) (bool, error)
+	GetContactByPhone(ctx context.Context, userID uint, phone string) (*models.Contact, error)
+	ListDuplicateGroups(ctx context.Context, userID uint, field string, offset, limit int) ([]models.DuplicateGroup, int64, error)
 	UpdateContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error)
 	DeleteContact(ctx context.Context, userID, contactID uint) error
+	RestoreContact(ctx context.Context, userID, contactID uint) error
+	DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error
+	MergeContacts(ctx context.Context, primary *models.Contact, duplicateIDs []uint) error
+	AdminListContacts(ctx context.Context, userID uint, includeDeleted bool, offset, limit int) ([]models.Contact, int64, error)
+	BackfillPhoneHashes(ctx context.Context) (int64, error)
+	BackfillSearchText(ctx context.Context) (int64, error)
+
+	CreateGroup(ctx context.Context, group *models.ContactGroup) (*models.ContactGroup, error)
+	ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error)
+	GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error)
+	RenameGroup(ctx context.Context, userID, groupID uint, name string) (*models.ContactGroup, error)
+	DeleteGroup(ctx context.Context, userID, groupID uint) ([]uint, error)
+	AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error
+	RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error
+
+	CreateImportJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error)
+	GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error)
+	UpdateImportJob(ctx context.Context, jobID uint, updates map[string]interface{}) error
+
+	// WithTx runs fn in a transaction, passing it a Repository whose calls
+	// belong to that transaction. It commits when fn returns nil and rolls
+	// back otherwise.
+	WithTx(ctx context.Context, fn func(Repository) error) error
+	// LockUser locks the user's row until the transaction ends, so other
+	// transactions writing the user's contacts wait for it
+	LockUser(ctx context.Context, userID uint) error
 }
 
 type repository struct {
@@ -29,6 +87,21 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+// WithTx runs fn in a transaction on a repository bound to it
+func (r *repository) WithTx(ctx context.Context, fn func(Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&repository{db: tx})
+	})
+}
+
+// LockUser takes a row lock on the user; outside a transaction it only checks
+// the user exists
+func (r *repository) LockUser(ctx context.Context, userID uint) error {
+	var user models.User
+	return r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&user, userID).Error
+}
+
 // CreateUser creates a new user
 func (r *repository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
 	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
@@ -55,6 +128,75 @@ func (r *repository) GetUserByID(ctx context.Context, id uint) (*models.User, er
 	return &user, nil
 }
 
+// CheckUserPhoneExists checks whether a user other than excludeUserID has the phone number
+func (r *repository) CheckUserPhoneExists(ctx context.Context, phone string, excludeUserID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("phone = ? AND id <> ?", phone, excludeUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// LookupDirectory returns the public info of opted-in users holding any of the
+// phones, with the number of other users who blocked each number. It runs one
+// query for the users and one for the block counts however many phones are given.
+func (r *repository) LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).
+		Select("id", "full_name", "phone", "avatar_url").
+		Where("phone IN ? AND directory_opt_in = ?", phones, true).
+		Order("id").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.DirectoryEntry, 0, len(users))
+	if len(users) == 0 {
+		return entries, nil
+	}
+
+	// Blocked contacts are matched like byPhone: by blind index when phones are encrypted
+	column := "phone"
+	keys := make(map[string]string, len(users))
+	for _, user := range users {
+		key := *user.Phone
+		if hash := phoneHash(key); hash != nil {
+			key, column = *hash, "phone_hash"
+		}
+		keys[*user.Phone] = key
+	}
+	matchKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		matchKeys = append(matchKeys, key)
+	}
+
+	var rows []struct {
+		MatchKey string
+		Reports  int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Contact{}).
+		Select(column+" AS match_key, COUNT(DISTINCT user_id) AS reports").
+		Where(column+" IN ? AND blocked = ?", matchKeys, true).
+		Group(column).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	reports := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		reports[row.MatchKey] = row.Reports
+	}
+
+	for _, user := range users {
+		entries = append(entries, models.DirectoryEntry{
+			Phone:       *user.Phone,
+			FullName:    user.FullName,
+			AvatarURL:   user.AvatarURL,
+			SpamReports: reports[keys[*user.Phone]],
+		})
+	}
+	return entries, nil
+}
+
 // UpdateUser updates user information
 func (r *repository) UpdateUser(ctx context.Context, userID uint, updates map[string]interface{}) (*models.User, error) {
 	var user models.User
@@ -69,37 +211,339 @@ func (r *repository) UpdateUser(ctx context.Context, userID uint, updates map[st
 	return &user, nil
 }
 
+// UpdateLastLogin records a successful login and clears any pending inactivity
+// state and failed login count
+func (r *repository) UpdateLastLogin(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"last_login_at":         at,
+			"inactivity_warned_at":  nil,
+			"flagged_inactive_at":   nil,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error
+}
+
+// RecordFailedLogin counts a failed login. The failure reaching maxAttempts
+// locks the account until lockUntil and starts the count over; the result
+// tells whether it did.
+func (r *repository) RecordFailedLogin(ctx context.Context, userID uint, maxAttempts int, lockUntil time.Time) (bool, error) {
+	locked := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Select("id", "failed_login_attempts").First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.FailedLoginAttempts < maxAttempts {
+			return nil
+		}
+
+		locked = true
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumns(map[string]interface{}{
+				"failed_login_attempts": 0,
+				"locked_until":          lockUntil,
+			}).Error
+	})
+	return locked, err
+}
+
+// DeleteUser deletes a user together with their contacts, including soft-deleted ones
+func (r *repository) DeleteUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Contact{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.User{}, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ListInactiveUsers retrieves non-admin users whose last activity is before the cutoff.
+// Users that never logged in are measured from their registration time.
+func (r *repository) ListInactiveUsers(ctx context.Context, cutoff time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("role <> ?", models.RoleAdmin).
+		Where("flagged_inactive_at IS NULL").
+		Where("COALESCE(last_login_at, created_at) < ?", cutoff).
+		Order("id").
+		Find(&users).Error
+	return users, err
+}
+
+// MarkInactivityWarned records when a user was warned about upcoming inactivity action
+func (r *repository) MarkInactivityWarned(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("inactivity_warned_at", at).Error
+}
+
+// FlagUserInactive marks a user as inactive without deleting them
+func (r *repository) FlagUserInactive(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("flagged_inactive_at", at).Error
+}
+
+// CreateInviteCode creates a new invite code
+func (r *repository) CreateInviteCode(ctx context.Context, code *models.InviteCode) (*models.InviteCode, error) {
+	if err := r.db.WithContext(ctx).Create(code).Error; err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// GetInviteCode retrieves an invite code by its value
+func (r *repository) GetInviteCode(ctx context.Context, code string) (*models.InviteCode, error) {
+	var invite models.InviteCode
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ListInviteCodes retrieves all invite codes, newest first
+func (r *repository) ListInviteCodes(ctx context.Context) ([]models.InviteCode, error) {
+	var invites []models.InviteCode
+	err := r.db.WithContext(ctx).Order("id DESC").Find(&invites).Error
+	return invites, err
+}
+
+// ConsumeInviteCode atomically uses up one use of a code that is neither exhausted nor expired.
+// It reports false when no use could be consumed.
+func (r *repository) ConsumeInviteCode(ctx context.Context, code string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.InviteCode{}).
+		Where("code = ? AND uses_remaining > 0", code).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		UpdateColumn("uses_remaining", gorm.Expr("uses_remaining - 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeToken blacklists a token, pruning entries of tokens that have expired
+// since. Revoking a token twice is not an error.
+func (r *repository) RevokeToken(ctx context.Context, revoked *models.RevokedToken, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(revoked).Error
+	})
+}
+
+// IsTokenRevoked reports whether the token with the given ID is blacklisted
+func (r *repository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (r *repository) CreatePasswordResetToken(ctx context.Context, reset *models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Create(reset).Error
+}
+
+// GetPasswordResetToken retrieves a password reset token by its hash
+func (r *repository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var reset models.PasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&reset).Error; err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// ConsumePasswordResetToken atomically marks a token that is neither used nor
+// expired as used. It reports false when the token could not be used.
+func (r *repository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		UpdateColumn("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 // ListContacts retrieves a paginated list of contacts
-func (r *repository) ListContacts(ctx context.Context, userID uint, query string, offset, limit int) ([]models.Contact, int64, error) {
+func (r *repository) ListContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int) ([]models.Contact, int64, error) {
 	var contacts []models.Contact
 	var total int64
 
-	db := r.db.WithContext(ctx).Model(&models.Contact{}).Where("user_id = ?", userID)
+	db := r.db.WithContext(ctx).Model(&models.Contact{}).Where("user_id = ?", userID).Scopes(contactFilter(filter))
 
-	if query != "" {
-		db = db.Where("full_name LIKE ? OR phone LIKE ? OR email LIKE ?",
-			"%"+query+"%", "%"+query+"%", "%"+query+"%")
-	}
+	if filter.MaxCount > 0 {
+		// Count at most one row past the cap so huge result sets stay cheap
+		capped := db.Session(&gorm.Session{}).Select("contacts.id").Limit(filter.MaxCount + 1)
+		if err := r.db.WithContext(ctx).Table("(?) AS capped", capped).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 
-	if err := db.Count(&total).Error; err != nil {
+		// Rows past the cap are never returned
+		if offset >= filter.MaxCount {
+			return []models.Contact{}, total, nil
+		}
+		if offset+limit > filter.MaxCount {
+			limit = filter.MaxCount - offset
+		}
+	} else if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := db.Offset(offset).Limit(limit).Find(&contacts).Error; err != nil {
+	if err := db.Scopes(contactOrder(filter.Sort)).Offset(offset).Limit(limit).Find(&contacts).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return contacts, total, nil
 }
 
+// contactOrder sorts by an allowlisted field, or by models.DefaultContactSort
+// when sort is empty, breaking ties by ID so pages are stable. Unknown fields
+// leave the query unordered.
+func contactOrder(sort string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if sort == "" {
+			sort = models.DefaultContactSort
+		}
+		column, desc, ok := models.ContactSortColumn(sort)
+		if !ok {
+			return db
+		}
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc}).Order("id")
+	}
+}
+
+// contactStreamBatchSize is how many contacts StreamContacts loads per query
+const contactStreamBatchSize = 50
+
+// StreamContacts calls fn for each contact of the page ListContacts would return,
+// in the same order, loading them in batches so the page is never held in
+// memory at once
+func (r *repository) StreamContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int, fn func(*models.Contact) error) error {
+	db := r.db.WithContext(ctx).Model(&models.Contact{}).
+		Where("user_id = ?", userID).
+		Scopes(contactFilter(filter), contactOrder(filter.Sort))
+
+	for loaded := 0; limit < 0 || loaded < limit; {
+		size := contactStreamBatchSize
+		if limit >= 0 && limit-loaded < size {
+			size = limit - loaded
+		}
+		var batch []models.Contact
+		if err := db.Session(&gorm.Session{}).Offset(offset + loaded).Limit(size).Find(&batch).Error; err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if len(batch) < size {
+			return nil
+		}
+		loaded += len(batch)
+	}
+	return nil
+}
+
 // CreateContact creates a new contact
 func (r *repository) CreateContact(ctx context.Context, contact *models.Contact) (*models.Contact, error) {
+	contact.PhoneHash = phoneHash(contact.Phone)
+	contact.SearchText = searchText(contact.FullName, contact.Company, contact.JobTitle)
+	contact.Version = 1
 	if err := r.db.WithContext(ctx).Create(contact).Error; err != nil {
 		return nil, err
 	}
 	return contact, nil
 }
 
+// CreateContacts creates several contacts in a single transaction
+func (r *repository) CreateContacts(ctx context.Context, contacts []*models.Contact) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, contact := range contacts {
+			contact.PhoneHash = phoneHash(contact.Phone)
+			contact.SearchText = searchText(contact.FullName, contact.Company, contact.JobTitle)
+			contact.Version = 1
+			if err := tx.Create(contact).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TagContacts tags every contact of the user matching filter with a single
+// INSERT ... SELECT and returns the IDs of the contacts newly tagged. They are
+// touched so ETags change with them.
+func (r *repository) TagContacts(ctx context.Context, userID uint, filter models.ContactFilter, tag string) ([]uint, error) {
+	var taggedIDs []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		untagged := func(db *gorm.DB) *gorm.DB {
+			alreadyTagged := tx.Session(&gorm.Session{NewDB: true}).Model(&models.ContactTag{}).Select("1").
+				Where("contact_tags.contact_id = contacts.id AND contact_tags.tag = ?", tag)
+			return db.Where("user_id = ?", userID).Scopes(contactFilter(filter)).Where("NOT EXISTS (?)", alreadyTagged)
+		}
+
+		matches := tx.Session(&gorm.Session{DryRun: true}).Model(&models.Contact{}).
+			Select("contacts.id, ?, ?", tag, now).
+			Scopes(untagged).
+			Find(&[]models.Contact{}).Statement
+		if err := tx.Model(&models.Contact{}).Scopes(untagged).Pluck("id", &taggedIDs).Error; err != nil || len(taggedIDs) == 0 {
+			return err
+		}
+		if err := tx.Model(&models.Contact{}).Where("id IN ?", taggedIDs).UpdateColumns(touched(now)).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("INSERT INTO contact_tags (contact_id, tag, created_at) "+matches.SQL.String(), matches.Vars...).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taggedIDs, nil
+}
+
+// CountContacts counts the user's contacts
+func (r *repository) CountContacts(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Contact{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ContactsVersion returns the version of the user's contact list, which
+// changes whenever the list does
+func (r *repository) ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Contact{}).Where("user_id = ?", userID).
+		Count(&count).Error; err != nil {
+		return models.ContactsVersion{}, err
+	}
+	// Deleted contacts keep their versions in the sum, so it never goes back
+	var version models.ContactsVersion
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.Contact{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(version), 0) AS versions, COALESCE(MAX(id), 0) AS last_id").
+		Scan(&version).Error; err != nil {
+		return models.ContactsVersion{}, err
+	}
+	version.Count = count
+	return version, nil
+}
+
 // GetContact retrieves a contact by ID and user ID
 func (r *repository) GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error) {
 	var contact models.Contact
@@ -109,15 +553,167 @@ func (r *repository) GetContact(ctx context.Context, userID, contactID uint) (*m
 	return &contact, nil
 }
 
-// CheckContactExists checks if a contact with the given phone number exists for the user
-func (r *repository) CheckContactExists(ctx context.Context, userID uint, phone string) (bool, error) {
+// CheckContactExists checks whether a contact of the user other than
+// excludeContactID has the phone number
+func (r *repository) CheckContactExists(ctx context.Context, userID uint, phone string, excludeContactID uint) (bool, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&models.Contact{}).
-		Where("user_id = ? AND phone = ?", userID, phone).
+		Where("user_id = ? AND id <> ?", userID, excludeContactID).
+		Scopes(byPhone(phone)).
 		Count(&count).Error
 	return count > 0, err
 }
 
+// GetContactByPhone retrieves the user's contact with the given phone number
+func (r *repository) GetContactByPhone(ctx context.Context, userID uint, phone string) (*models.Contact, error) {
+	var contact models.Contact
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Scopes(byPhone(phone)).First(&contact).Error; err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// contactFilter applies the list filters to a contacts query
+func contactFilter(filter models.ContactFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query := filter.Query; query != "" {
+			pattern := "%" + query + "%"
+			db = db.Where("search_text LIKE ? OR full_name LIKE ? OR phone LIKE ? OR email LIKE ? OR company LIKE ? OR job_title LIKE ?",
+				"%"+foldSearch(query)+"%", pattern, pattern, pattern, pattern, pattern)
+		}
+
+		if filter.HasAvatar != nil {
+			if *filter.HasAvatar {
+				db = db.Where("avatar_url IS NOT NULL")
+			} else {
+				db = db.Where("avatar_url IS NULL")
+			}
+		}
+
+		if filter.Favorite != nil {
+			db = db.Where("favorite = ?", *filter.Favorite)
+		}
+
+		if filter.Blocked != nil {
+			db = db.Where("blocked = ?", *filter.Blocked)
+		}
+
+		if filter.Source != "" {
+			db = db.Where("source = ?", filter.Source)
+		}
+
+		if filter.Tag != "" {
+			db = db.Where("EXISTS (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.ContactTag{}).Select("1").
+				Where("contact_tags.contact_id = contacts.id AND contact_tags.tag = ?", filter.Tag))
+		}
+
+		if filter.GroupID != 0 {
+			db = db.Where("EXISTS (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.ContactGroupMember{}).Select("1").
+				Where("contact_group_members.contact_id = contacts.id AND contact_group_members.group_id = ?", filter.GroupID))
+		}
+		return db
+	}
+}
+
+// byPhone matches contacts by exact phone number. With field encryption enabled the
+// phone column holds randomized ciphertext, so the blind index is matched instead.
+func byPhone(phone string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if hash := phoneHash(phone); hash != nil {
+			return db.Where("phone_hash = ?", *hash)
+		}
+		return db.Where("phone = ?", phone)
+	}
+}
+
+// phoneHash returns the blind index for phone, or nil when field encryption is disabled
+func phoneHash(phone string) *string {
+	hash := fieldcrypt.BlindIndex(phone)
+	if hash == "" {
+		return nil
+	}
+	return &hash
+}
+
+// duplicateKeys maps the supported duplicate fields to their grouping expression
+var duplicateKeys = map[string]string{
+	"name":  "LOWER(TRIM(full_name))",
+	"phone": "phone",
+}
+
+// ListDuplicateGroups retrieves a paginated list of contact groups sharing the same name or phone
+func (r *repository) ListDuplicateGroups(ctx context.Context, userID uint, field string, offset, limit int) ([]models.DuplicateGroup, int64, error) {
+	keyExpr, ok := duplicateKeys[field]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported duplicate field: %s", field)
+	}
+	// Encrypted phones differ per row, so they are grouped like byPhone matches them
+	hashedPhones := field == "phone" && fieldcrypt.Enabled()
+	if hashedPhones {
+		keyExpr = "phone_hash"
+	}
+
+	grouped := r.db.WithContext(ctx).Model(&models.Contact{}).
+		Select(keyExpr+" AS dup_key, COUNT(*) AS dup_count").
+		Where("user_id = ?", userID).
+		Group(keyExpr).
+		Having("COUNT(*) > 1")
+
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS dup_groups", grouped).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var keys []struct {
+		DupKey   string
+		DupCount int
+	}
+	if err := grouped.Order("dup_key").Offset(offset).Limit(limit).Scan(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+
+	groups := make([]models.DuplicateGroup, 0, len(keys))
+	if len(keys) == 0 {
+		return groups, total, nil
+	}
+
+	keyValues := make([]string, len(keys))
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		keyValues[i] = k.DupKey
+		index[k.DupKey] = i
+		groups = append(groups, models.DuplicateGroup{Key: k.DupKey, Count: k.DupCount})
+	}
+
+	var contacts []struct {
+		models.Contact
+		DupKey string
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Contact{}).
+		Select("*, "+keyExpr+" AS dup_key").
+		Where("user_id = ? AND "+keyExpr+" IN ?", userID, keyValues).
+		Order("id").
+		Scan(&contacts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for _, c := range contacts {
+		if i, ok := index[c.DupKey]; ok {
+			groups[i].Contacts = append(groups[i].Contacts, c.Contact)
+		}
+	}
+	if hashedPhones {
+		// Report the decrypted phone rather than its blind index
+		for i := range groups {
+			if len(groups[i].Contacts) > 0 {
+				groups[i].Key = groups[i].Contacts[0].Phone
+			}
+		}
+	}
+
+	return groups, total, nil
+}
+
 // UpdateContact updates contact information
 func (r *repository) UpdateContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error) {
 	var contact models.Contact
@@ -125,14 +721,95 @@ func (r *repository) UpdateContact(ctx context.Context, userID, contactID uint,
 		return nil, err
 	}
 
+	if text, ok := updatedSearchText(&contact, updates); ok {
+		updates["search_text"] = text
+	}
+
+	updates["version"] = gorm.Expr("version + 1")
+
+	if fieldcrypt.Enabled() {
+		// Map updates bypass the model serializer, so encrypt them here
+		encrypted, err := encryptContactUpdates(updates)
+		if err != nil {
+			return nil, err
+		}
+		updates = encrypted
+	} else if _, ok := updates["phone"]; ok {
+		// A changed phone invalidates the blind index; BackfillPhoneHashes recomputes it
+		updates["phone_hash"] = nil
+	}
 	if err := r.db.WithContext(ctx).Model(&contact).Updates(updates).Error; err != nil {
 		return nil, err
 	}
 
+	// Reload the stored row, decrypted and with the version the database wrote
+	if err := r.db.WithContext(ctx).First(&contact, contact.ID).Error; err != nil {
+		return nil, err
+	}
 	return &contact, nil
 }
 
-// DeleteContact deletes a contact
+// updatedSearchText recomputes the search text of a contact whose name,
+// company or job title is being updated
+func updatedSearchText(contact *models.Contact, updates map[string]interface{}) (*string, bool) {
+	fullName, company, jobTitle := contact.FullName, contact.Company, contact.JobTitle
+	changed := false
+	if v, ok := updates["full_name"].(string); ok {
+		fullName, changed = v, true
+	}
+	if v, ok := updates["company"]; ok {
+		company, _ = v.(*string)
+		changed = true
+	}
+	if v, ok := updates["job_title"]; ok {
+		jobTitle, _ = v.(*string)
+		changed = true
+	}
+	if !changed {
+		return nil, false
+	}
+	return searchText(fullName, company, jobTitle), true
+}
+
+// encryptedContactColumns lists the contact columns stored through fieldcrypt
+var encryptedContactColumns = []string{"phone", "email"}
+
+// encryptContactUpdates returns a copy of updates with the encrypted columns encrypted
+func encryptContactUpdates(updates map[string]interface{}) (map[string]interface{}, error) {
+	encrypted := make(map[string]interface{}, len(updates))
+	for k, v := range updates {
+		encrypted[k] = v
+	}
+
+	for _, column := range encryptedContactColumns {
+		var plaintext string
+		switch v := encrypted[column].(type) {
+		case string:
+			plaintext = v
+		case *string:
+			if v == nil {
+				continue
+			}
+			plaintext = *v
+		default:
+			continue
+		}
+
+		value, err := fieldcrypt.Encrypt(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[column] = value
+
+		if column == "phone" {
+			encrypted["phone_hash"] = phoneHash(plaintext)
+		}
+	}
+
+	return encrypted, nil
+}
+
+// DeleteContact soft-deletes a contact
 func (r *repository) DeleteContact(ctx context.Context, userID, contactID uint) error {
 	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", contactID, userID).Delete(&models.Contact{})
 	if result.Error != nil {
@@ -143,3 +820,301 @@ func (r *repository) DeleteContact(ctx context.Context, userID, contactID uint)
 	}
 	return nil
 }
+
+// RestoreContact undoes the soft delete of a contact, returning
+// gorm.ErrRecordNotFound when the user has no such deleted contact
+func (r *repository) RestoreContact(ctx context.Context, userID, contactID uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Contact{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", contactID, userID).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteContactIfUnchanged deletes a contact only while it is still at
+// version, returning gorm.ErrRecordNotFound when nothing was deleted
+func (r *repository) DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND version = ?", contactID, userID, version).
+		Delete(&models.Contact{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// mergedContactColumns lists the contact columns a merge may fill in
+var mergedContactColumns = []string{"email", "avatar_url", "company", "job_title", "favorite", "blocked", "search_text"}
+
+// MergeContacts saves the merged primary contact, moves the duplicates' tags
+// and group memberships to it and soft-deletes the duplicates, all in one
+// transaction
+func (r *repository) MergeContacts(ctx context.Context, primary *models.Contact, duplicateIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		primary.SearchText = searchText(primary.FullName, primary.Company, primary.JobTitle)
+		// Struct updates go through the model serializer, so encrypted columns stay encrypted
+		if err := tx.Model(primary).Select(mergedContactColumns).Updates(primary).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(primary).UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(primary).Select("version", "updated_at").Take(primary).Error; err != nil {
+			return err
+		}
+
+		var tags []models.ContactTag
+		if err := tx.Where("contact_id IN ?", duplicateIDs).Find(&tags).Error; err != nil {
+			return err
+		}
+		for i := range tags {
+			tags[i] = models.ContactTag{ContactID: primary.ID, Tag: tags[i].Tag}
+		}
+		if len(tags) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+				return err
+			}
+		}
+
+		var members []models.ContactGroupMember
+		if err := tx.Where("contact_id IN ?", duplicateIDs).Find(&members).Error; err != nil {
+			return err
+		}
+		for i := range members {
+			members[i] = models.ContactGroupMember{GroupID: members[i].GroupID, ContactID: primary.ID}
+		}
+		if len(members) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Where("user_id = ? AND id IN ?", primary.UserID, duplicateIDs).Delete(&models.Contact{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(duplicateIDs)) {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// AdminListContacts retrieves a paginated list of contacts across users for admins.
+// A zero userID lists every user's contacts; includeDeleted also returns soft-deleted rows.
+func (r *repository) AdminListContacts(ctx context.Context, userID uint, includeDeleted bool, offset, limit int) ([]models.Contact, int64, error) {
+	var contacts []models.Contact
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Contact{})
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	if userID != 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&contacts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return contacts, total, nil
+}
+
+// BackfillPhoneHashes computes the phone blind index for contacts that lack one,
+// e.g. rows written before field encryption was enabled. It is a no-op when disabled.
+func (r *repository) BackfillPhoneHashes(ctx context.Context) (int64, error) {
+	if !fieldcrypt.Enabled() {
+		return 0, nil
+	}
+
+	var updated int64
+	var contacts []models.Contact
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("phone_hash IS NULL").
+		FindInBatches(&contacts, 500, func(tx *gorm.DB, batch int) error {
+			for _, contact := range contacts {
+				if err := r.db.WithContext(ctx).Unscoped().Model(&models.Contact{}).
+					Where("id = ?", contact.ID).
+					UpdateColumn("phone_hash", phoneHash(contact.Phone)).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		})
+
+	return updated, result.Error
+}
+
+// BackfillSearchText computes the search text of contacts written before the
+// column existed and returns how many were updated
+func (r *repository) BackfillSearchText(ctx context.Context) (int64, error) {
+	var updated int64
+	var contacts []models.Contact
+	result := r.db.WithContext(ctx).Unscoped().
+		Select("id", "full_name", "company", "job_title").
+		Where("search_text IS NULL").
+		FindInBatches(&contacts, 500, func(tx *gorm.DB, batch int) error {
+			for _, contact := range contacts {
+				if err := r.db.WithContext(ctx).Unscoped().Model(&models.Contact{}).
+					Where("id = ?", contact.ID).
+					UpdateColumn("search_text", searchText(contact.FullName, contact.Company, contact.JobTitle)).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		})
+
+	return updated, result.Error
+}
+
+// withContactCount adds the number of non-deleted contacts in each group
+func withContactCount(db *gorm.DB) *gorm.DB {
+	return db.Select("contact_groups.*, (SELECT COUNT(*) FROM contact_group_members " +
+		"JOIN contacts ON contacts.id = contact_group_members.contact_id AND contacts.deleted_at IS NULL " +
+		"WHERE contact_group_members.group_id = contact_groups.id) AS contact_count")
+}
+
+// CreateGroup creates a new contact group
+func (r *repository) CreateGroup(ctx context.Context, group *models.ContactGroup) (*models.ContactGroup, error) {
+	if err := r.db.WithContext(ctx).Create(group).Error; err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroups retrieves the user's contact groups ordered by name
+func (r *repository) ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error) {
+	groups := []models.ContactGroup{}
+	err := r.db.WithContext(ctx).Model(&models.ContactGroup{}).Scopes(withContactCount).
+		Where("user_id = ?", userID).Order("name, id").Find(&groups).Error
+	return groups, err
+}
+
+// GetGroup retrieves a contact group by ID and user ID
+func (r *repository) GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error) {
+	var group models.ContactGroup
+	if err := r.db.WithContext(ctx).Model(&models.ContactGroup{}).Scopes(withContactCount).
+		Where("id = ? AND user_id = ?", groupID, userID).Take(&group).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// RenameGroup renames the user's contact group
+func (r *repository) RenameGroup(ctx context.Context, userID, groupID uint, name string) (*models.ContactGroup, error) {
+	result := r.db.WithContext(ctx).Model(&models.ContactGroup{}).
+		Where("id = ? AND user_id = ?", groupID, userID).Update("name", name)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetGroup(ctx, userID, groupID)
+}
+
+// DeleteGroup deletes the user's contact group and its memberships, keeping
+// the contacts, and returns the IDs of the former members. They are touched
+// so ETags change.
+func (r *repository) DeleteGroup(ctx context.Context, userID, groupID uint) ([]uint, error) {
+	var memberIDs []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.ContactGroup
+		if err := tx.Where("id = ? AND user_id = ?", groupID, userID).Take(&group).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.ContactGroupMember{}).Where("group_id = ?", groupID).
+			Pluck("contact_id", &memberIDs).Error; err != nil {
+			return err
+		}
+		if len(memberIDs) > 0 {
+			if err := tx.Model(&models.Contact{}).Where("user_id = ? AND id IN ?", userID, memberIDs).
+				UpdateColumns(touched(time.Now())).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.ContactGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memberIDs, nil
+}
+
+// AddContactToGroup puts the user's contact in the group; adding a member
+// again does nothing. The group and contact must belong to the user.
+func (r *repository) AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ContactGroupMember{GroupID: groupID, ContactID: contactID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return touchContact(tx, userID, contactID)
+	})
+}
+
+// RemoveContactFromGroup takes the user's contact out of the group; removing
+// a contact that is not a member does nothing
+func (r *repository) RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("group_id = ? AND contact_id = ?", groupID, contactID).Delete(&models.ContactGroupMember{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return touchContact(tx, userID, contactID)
+	})
+}
+
+// touchContact gives a contact a fresh updated_at and version, so ETags
+// change when its group memberships do
+func touchContact(tx *gorm.DB, userID, contactID uint) error {
+	return tx.Model(&models.Contact{}).Where("id = ? AND user_id = ?", contactID, userID).
+		UpdateColumns(touched(time.Now())).Error
+}
+
+// touched is the update marking contacts as changed at now
+func touched(now time.Time) map[string]interface{} {
+	return map[string]interface{}{"updated_at": now, "version": gorm.Expr("version + 1")}
+}
+
+// CreateImportJob creates a new import job
+func (r *repository) CreateImportJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error) {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetImportJob retrieves an import job by ID and user ID
+func (r *repository) GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateImportJob updates the status and counters of an import job
+func (r *repository) UpdateImportJob(ctx context.Context, jobID uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.ImportJob{}).Where("id = ?", jobID).Updates(updates).Error
+}
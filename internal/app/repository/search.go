@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldSearch returns the form contact text is searched in: lower-cased with
+// accents removed, so "José" and "jose" compare equal whatever the collation
+func foldSearch(value string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// searchText is the folded name, company and job title stored in the
+// search_text column. Fields are joined with a newline, which queries never
+// contain, so a match cannot span two fields.
+func searchText(fullName string, company, jobTitle *string) *string {
+	fields := []string{fullName}
+	for _, field := range []*string{company, jobTitle} {
+		if field != nil {
+			fields = append(fields, *field)
+		}
+	}
+	text := foldSearch(strings.Join(fields, "\n"))
+	return &text
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"user-service/internal/app/models"
 
@@ -21,7 +24,7 @@ func TestRepository_CreateUser(t *testing.T) {
 		user := &models.User{
 			FullName: "John Doe",
 			Email:    "john@example.com",
-			Phone:    "+1234567890",
+			Phone:    stringPtr("1234567890"),
 			Password: "hashedpassword",
 		}
 
@@ -116,7 +119,7 @@ func TestRepository_UpdateUser(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, createdUser.ID, updatedUser.ID)
 		assert.Equal(t, "Updated Name", updatedUser.FullName)
-		assert.Equal(t, "+0987654321", updatedUser.Phone)
+		assert.Equal(t, "+0987654321", *updatedUser.Phone)
 		assert.Equal(t, user.Email, updatedUser.Email) // Email should remain unchanged
 	})
 
@@ -159,7 +162,7 @@ func TestRepository_ListContacts(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("list all contacts", func(t *testing.T) {
-		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, "", 0, 10)
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{}, 0, 10)
 
 		require.NoError(t, err)
 		assert.Equal(t, int64(2), total)
@@ -167,7 +170,7 @@ func TestRepository_ListContacts(t *testing.T) {
 	})
 
 	t.Run("list contacts with search", func(t *testing.T) {
-		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, "Alice", 0, 10)
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Alice"}, 0, 10)
 
 		require.NoError(t, err)
 		assert.Equal(t, int64(1), total)
@@ -176,7 +179,7 @@ func TestRepository_ListContacts(t *testing.T) {
 	})
 
 	t.Run("list contacts with pagination", func(t *testing.T) {
-		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, "", 0, 1)
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{}, 0, 1)
 
 		require.NoError(t, err)
 		assert.Equal(t, int64(2), total)
@@ -184,7 +187,7 @@ func TestRepository_ListContacts(t *testing.T) {
 	})
 
 	t.Run("list contacts for non-existent user", func(t *testing.T) {
-		contacts, total, err := repo.ListContacts(ctx, 9999, "", 0, 10)
+		contacts, total, err := repo.ListContacts(ctx, 9999, models.ContactFilter{}, 0, 10)
 
 		require.NoError(t, err)
 		assert.Equal(t, int64(0), total)
@@ -192,6 +195,383 @@ func TestRepository_ListContacts(t *testing.T) {
 	})
 }
 
+func TestRepository_ListContacts_Sort(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	// Each column orders the three contacts differently
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []models.Contact{
+		{FullName: "Bob", Company: stringPtr("Acme"), Favorite: true, Source: models.ContactSourceManual,
+			CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(1 * time.Hour)},
+		{FullName: "Carol", Company: stringPtr("Zeta"), Favorite: false, Source: models.ContactSourceAPI,
+			CreatedAt: base.Add(1 * time.Hour), UpdatedAt: base.Add(3 * time.Hour)},
+		{FullName: "Alice", Company: stringPtr("Mono"), Favorite: false, Source: models.ContactSourceCSVImport,
+			CreatedAt: base.Add(3 * time.Hour), UpdatedAt: base.Add(2 * time.Hour)},
+	} {
+		c.UserID = createdUser.ID
+		c.Phone = "555" + c.FullName
+		_, err := repo.CreateContact(ctx, &c)
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Alice", "Bob", "Carol"}},
+		{"full_name", []string{"Alice", "Bob", "Carol"}},
+		{"-full_name", []string{"Carol", "Bob", "Alice"}},
+		{"company", []string{"Bob", "Alice", "Carol"}},
+		// Ties on favorite fall back to ID order
+		{"-favorite", []string{"Bob", "Carol", "Alice"}},
+		{"source", []string{"Carol", "Alice", "Bob"}},
+		{"created_at", []string{"Carol", "Bob", "Alice"}},
+		{"-updated_at", []string{"Carol", "Alice", "Bob"}},
+	} {
+		t.Run("sort="+tc.sort, func(t *testing.T) {
+			contacts, _, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Sort: tc.sort}, 0, 10)
+			require.NoError(t, err)
+
+			names := make([]string, len(contacts))
+			for i, c := range contacts {
+				names[i] = c.FullName
+			}
+			assert.Equal(t, tc.want, names)
+		})
+	}
+}
+
+func TestRepository_ListContacts_HasAvatar(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	seed := []struct {
+		name   string
+		phone  string
+		avatar *string
+	}{
+		{"Alice Photo", "1111111111", stringPtr("https://example.com/alice.png")},
+		{"Alice Plain", "2222222222", nil},
+		{"Bob Photo", "3333333333", stringPtr("https://example.com/bob.png")},
+	}
+	for _, s := range seed {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: createdUser.ID, FullName: s.name, Phone: s.phone, AvatarURL: s.avatar})
+		require.NoError(t, err)
+	}
+
+	hasAvatar, noAvatar := true, false
+
+	t.Run("contacts with avatars", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{HasAvatar: &hasAvatar}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		for _, contact := range contacts {
+			assert.NotNil(t, contact.AvatarURL)
+		}
+	})
+
+	t.Run("contacts missing avatars", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{HasAvatar: &noAvatar}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Alice Plain", contacts[0].FullName)
+	})
+
+	t.Run("combines with search", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Alice", HasAvatar: &hasAvatar}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Alice Photo", contacts[0].FullName)
+	})
+}
+
+func TestRepository_ListContacts_Favorite(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	seed := []struct {
+		name     string
+		phone    string
+		favorite bool
+	}{
+		{"Alice Best", "1111111111", true},
+		{"Alice Other", "2222222222", false},
+		{"Bob Best", "3333333333", true},
+	}
+	for _, s := range seed {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: createdUser.ID, FullName: s.name, Phone: s.phone, Favorite: s.favorite})
+		require.NoError(t, err)
+	}
+
+	favorite, notFavorite := true, false
+
+	t.Run("favorites only", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Favorite: &favorite}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		for _, contact := range contacts {
+			assert.True(t, contact.Favorite)
+		}
+	})
+
+	t.Run("non-favorites only", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Favorite: &notFavorite}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Alice Other", contacts[0].FullName)
+	})
+
+	t.Run("omitted lists every contact", func(t *testing.T) {
+		_, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+	})
+
+	t.Run("combines with search", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Alice", Favorite: &favorite}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Alice Best", contacts[0].FullName)
+	})
+}
+
+func TestRepository_ListContacts_Blocked(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	for _, c := range []models.Contact{
+		{UserID: createdUser.ID, FullName: "Spam Caller", Phone: "1111111111", Blocked: true},
+		{UserID: createdUser.ID, FullName: "Friend", Phone: "2222222222"},
+	} {
+		_, err := repo.CreateContact(ctx, &c)
+		require.NoError(t, err)
+	}
+
+	blocked, notBlocked := true, false
+
+	t.Run("blocked contacts", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Blocked: &blocked}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Spam Caller", contacts[0].FullName)
+		assert.True(t, contacts[0].Blocked)
+	})
+
+	t.Run("contacts that are not blocked", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Blocked: &notBlocked}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, "Friend", contacts[0].FullName)
+	})
+
+	t.Run("unblocking via update", func(t *testing.T) {
+		contacts, _, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Blocked: &blocked}, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+
+		updated, err := repo.UpdateContact(ctx, createdUser.ID, contacts[0].ID, map[string]interface{}{"blocked": false})
+
+		require.NoError(t, err)
+		assert.False(t, updated.Blocked)
+	})
+}
+
+func TestRepository_ListContacts_MaxCount(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: createdUser.ID, FullName: "Contact", Phone: fmt.Sprintf("555000000%d", i)})
+		require.NoError(t, err)
+	}
+
+	t.Run("count stops one past the cap", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{MaxCount: 3}, 0, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		assert.Len(t, contacts, 2)
+	})
+
+	t.Run("pages are clamped to the cap", func(t *testing.T) {
+		contacts, _, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{MaxCount: 3}, 2, 2)
+		require.NoError(t, err)
+		assert.Len(t, contacts, 1)
+
+		contacts, _, err = repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{MaxCount: 3}, 4, 2)
+		require.NoError(t, err)
+		assert.Empty(t, contacts)
+	})
+
+	t.Run("small sets are counted exactly", func(t *testing.T) {
+		_, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{MaxCount: 10}, 0, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+	})
+}
+
+func TestRepository_TagContacts(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+	otherUser := TestUser()
+	otherUser.Email = "other@example.com"
+	otherUser, err = repo.CreateUser(ctx, otherUser)
+	require.NoError(t, err)
+
+	for _, c := range []models.Contact{
+		{UserID: createdUser.ID, FullName: "Alice Work", Phone: "1111111111"},
+		{UserID: createdUser.ID, FullName: "Bob Work", Phone: "2222222222"},
+		{UserID: createdUser.ID, FullName: "Carol Home", Phone: "3333333333"},
+		{UserID: otherUser.ID, FullName: "Dave Work", Phone: "4444444444"},
+	} {
+		_, err := repo.CreateContact(ctx, &c)
+		require.NoError(t, err)
+	}
+
+	t.Run("tags only matching contacts of the user", func(t *testing.T) {
+		tagged, err := repo.TagContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Work"}, "colleagues")
+
+		require.NoError(t, err)
+		assert.Len(t, tagged, 2)
+
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Tag: "colleagues"}, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.ElementsMatch(t, []string{"Alice Work", "Bob Work"}, []string{contacts[0].FullName, contacts[1].FullName})
+
+		_, total, err = repo.ListContacts(ctx, otherUser.ID, models.ContactFilter{Tag: "colleagues"}, 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("already tagged contacts are not counted again", func(t *testing.T) {
+		tagged, err := repo.TagContacts(ctx, createdUser.ID, models.ContactFilter{}, "colleagues")
+
+		require.NoError(t, err)
+		assert.Len(t, tagged, 1)
+	})
+
+	t.Run("soft-deleted contacts are skipped", func(t *testing.T) {
+		contacts, _, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Carol"}, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		require.NoError(t, repo.DeleteContact(ctx, createdUser.ID, contacts[0].ID))
+
+		tagged, err := repo.TagContacts(ctx, createdUser.ID, models.ContactFilter{Query: "Carol"}, "family")
+
+		require.NoError(t, err)
+		assert.Empty(t, tagged)
+	})
+}
+
+func TestRepository_ListDuplicateGroups(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	user := TestUser()
+	createdUser, err := repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// Five duplicate name groups of two contacts each, plus one unique contact
+	names := []string{"Alice", "Bob", "Carol", "Dave", "Eve"}
+	phone := 1000000000
+	for _, name := range names {
+		for _, variant := range []string{name, strings.ToUpper(name)} {
+			contact := TestContact(createdUser.ID)
+			contact.FullName = variant
+			contact.Phone = fmt.Sprintf("%d", phone)
+			phone++
+			_, err := repo.CreateContact(ctx, contact)
+			require.NoError(t, err)
+		}
+	}
+	unique := TestContact(createdUser.ID)
+	unique.FullName = "Zed"
+	unique.Phone = "9999999999"
+	_, err = repo.CreateContact(ctx, unique)
+	require.NoError(t, err)
+
+	t.Run("first page of groups", func(t *testing.T) {
+		groups, total, err := repo.ListDuplicateGroups(ctx, createdUser.ID, "name", 0, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "alice", groups[0].Key)
+		assert.Equal(t, "bob", groups[1].Key)
+		for _, group := range groups {
+			assert.Equal(t, 2, group.Count)
+			assert.Len(t, group.Contacts, 2)
+		}
+	})
+
+	t.Run("last partial page of groups", func(t *testing.T) {
+		groups, total, err := repo.ListDuplicateGroups(ctx, createdUser.ID, "name", 4, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "eve", groups[0].Key)
+		assert.Len(t, groups[0].Contacts, 2)
+	})
+
+	t.Run("no phone duplicates", func(t *testing.T) {
+		groups, total, err := repo.ListDuplicateGroups(ctx, createdUser.ID, "phone", 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.Empty(t, groups)
+	})
+}
+
 func TestRepository_CreateContact(t *testing.T) {
 	_, repo, cleanup := SetupTestEnvironment(t)
 	defer cleanup()
@@ -274,25 +654,44 @@ func TestRepository_CheckContactExists(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("contact exists", func(t *testing.T) {
-		exists, err := repo.CheckContactExists(ctx, createdUser.ID, contact.Phone)
+		exists, err := repo.CheckContactExists(ctx, createdUser.ID, contact.Phone, 0)
 
 		require.NoError(t, err)
 		assert.True(t, exists)
 	})
 
 	t.Run("contact does not exist", func(t *testing.T) {
-		exists, err := repo.CheckContactExists(ctx, createdUser.ID, "+9999999999")
+		exists, err := repo.CheckContactExists(ctx, createdUser.ID, "+9999999999", 0)
 
 		require.NoError(t, err)
 		assert.False(t, exists)
 	})
 
 	t.Run("contact does not exist for different user", func(t *testing.T) {
-		exists, err := repo.CheckContactExists(ctx, 9999, contact.Phone)
+		exists, err := repo.CheckContactExists(ctx, 9999, contact.Phone, 0)
 
 		require.NoError(t, err)
 		assert.False(t, exists)
 	})
+
+	t.Run("contact keeping its own number is excluded", func(t *testing.T) {
+		exists, err := repo.CheckContactExists(ctx, createdUser.ID, contact.Phone, contact.ID)
+
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("another contact with the number is not excluded", func(t *testing.T) {
+		other := TestContact(createdUser.ID)
+		other.Phone = "5550001111"
+		_, err := repo.CreateContact(ctx, other)
+		require.NoError(t, err)
+
+		exists, err := repo.CheckContactExists(ctx, createdUser.ID, contact.Phone, other.ID)
+
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
 }
 
 func TestRepository_UpdateContact(t *testing.T) {
@@ -393,3 +792,97 @@ func TestRepository_DeleteContact(t *testing.T) {
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
 }
+
+func TestRepository_RestoreContact(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+	contact, err := repo.CreateContact(ctx, TestContact(createdUser.ID))
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteContact(ctx, createdUser.ID, contact.ID))
+
+	t.Run("deleted contacts are hidden", func(t *testing.T) {
+		_, err := repo.GetContact(ctx, createdUser.ID, contact.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		exists, err := repo.CheckContactExists(ctx, createdUser.ID, contact.Phone, 0)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{}, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, contacts)
+		assert.Zero(t, total)
+	})
+
+	t.Run("wrong user cannot restore", func(t *testing.T) {
+		err := repo.RestoreContact(ctx, 9999, contact.ID)
+
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("successful restore", func(t *testing.T) {
+		require.NoError(t, repo.RestoreContact(ctx, createdUser.ID, contact.ID))
+
+		restored, err := repo.GetContact(ctx, createdUser.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Equal(t, contact.FullName, restored.FullName)
+	})
+
+	t.Run("contact that is not deleted", func(t *testing.T) {
+		err := repo.RestoreContact(ctx, createdUser.ID, contact.ID)
+
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestRepository_AdminListContacts(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createdUser, err := repo.CreateUser(ctx, TestUser())
+	require.NoError(t, err)
+
+	live, err := repo.CreateContact(ctx, TestContact(createdUser.ID))
+	require.NoError(t, err)
+
+	deleted := TestContact(createdUser.ID)
+	deleted.Phone = "5555555555"
+	deleted, err = repo.CreateContact(ctx, deleted)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteContact(ctx, createdUser.ID, deleted.ID))
+
+	t.Run("deleted contacts are hidden from regular listing", func(t *testing.T) {
+		contacts, total, err := repo.ListContacts(ctx, createdUser.ID, models.ContactFilter{}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, live.ID, contacts[0].ID)
+	})
+
+	t.Run("deleted contacts are hidden from admin listing by default", func(t *testing.T) {
+		contacts, total, err := repo.AdminListContacts(ctx, createdUser.ID, false, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, contacts, 1)
+		assert.Equal(t, live.ID, contacts[0].ID)
+	})
+
+	t.Run("include deleted returns soft-deleted contacts", func(t *testing.T) {
+		contacts, total, err := repo.AdminListContacts(ctx, 0, true, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, contacts, 2)
+		assert.False(t, contacts[0].DeletedAt.Valid)
+		assert.Equal(t, deleted.ID, contacts[1].ID)
+		assert.True(t, contacts[1].DeletedAt.Valid)
+	})
+}
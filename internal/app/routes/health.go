@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"user-service/pkg/db"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// livenessHandler reports that the process is serving requests. It does no
+// I/O, so orchestrators can probe it often without loading the database.
+func livenessHandler(database *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := db.Stats(database)
+		c.JSON(http.StatusOK, gin.H{
+			"status": "healthy",
+			"database": gin.H{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+			},
+		})
+	}
+}
+
+// readinessHandler pings the database and, when configured, Redis, answering
+// 503 with the status of each component when any of them is unreachable
+func readinessHandler(database *gorm.DB, redisClient *goredis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		err := db.Ping(ctx, database)
+		components := gin.H{"database": componentStatus(err)}
+		ready := err == nil
+		if redisClient != nil {
+			err := pingRedis(ctx, redisClient)
+			components["redis"] = componentStatus(err)
+			ready = ready && err == nil
+		}
+
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "components": components})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "healthy", "components": components})
+	}
+}
+
+func pingRedis(ctx context.Context, client *goredis.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, db.PingTimeout)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+func componentStatus(err error) gin.H {
+	if err != nil {
+		return gin.H{"status": "down", "error": err.Error()}
+	}
+	return gin.H{"status": "up"}
+}
@@ -1,49 +1,184 @@
 package routes
 
 import (
+	"fmt"
+	"path"
+	"strings"
 	"time"
+	"user-service/configs"
 	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/storage"
+	"user-service/internal/app/token"
 	"user-service/internal/logger"
+	"user-service/internal/metrics"
 	"user-service/internal/middleware"
+	"user-service/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
-// SetupRoutes configures all the routes for the application
-func SetupRoutes(router *gin.Engine, h *handlers.Handler, jwtSecretKey string) {
-	// Add middlewares
+// SetupRoutes configures all the routes for the application, mounting each
+// configured API version under the configured prefix. redisClient is checked
+// by the readiness probe and may be nil when Redis is not used.
+func SetupRoutes(router *gin.Engine, h *handlers.Handler, cfg configs.Config, database *gorm.DB, redisClient *goredis.Client, keys *token.KeySet) error {
+	if cfg.ValidationStatusCode != 0 {
+		if err := utils.SetValidationStatus(cfg.ValidationStatusCode); err != nil {
+			return err
+		}
+	}
+	// Examples describe the request schema, which production should not advertise
+	utils.SetValidationExamples(cfg.ValidationExamples && cfg.Environment != "production")
+	logger.SetBodyLogging(cfg.LogBodies)
+	if cfg.LogLevel != "" {
+		if err := logger.SetLevel(cfg.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	// Add middlewares. Recovery comes first so a panic anywhere is answered
+	// with a 500; it is repeated inside Metrics and Tracing so they still see
+	// the status of a panicking handler. Both carry the correlation ID, which
+	// is read when the panic is logged.
+	router.Use(middleware.Recovery())
+	router.Use(middleware.CorrelationID())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Recovery())
+	router.Use(middleware.CORS(cfg.AllowedOrigins))
 	router.Use(middleware.SecureHeaders())
 	router.Use(middleware.TimeoutMiddleware(30 * time.Second)) // 30 second timeout
 	router.Use(logger.JSONLogMiddleware())
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	// Health checks: /health for liveness, /health/ready for readiness
+	router.GET("/health", livenessHandler(database))
+	router.GET("/health/ready", readinessHandler(database, redisClient))
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Default.Handler()))
+
+	// Locally stored avatars are served from their public path
+	if cfg.AvatarStorage == storage.KindLocal && strings.HasPrefix(cfg.AvatarPublicURL, "/") {
+		router.Static(cfg.AvatarPublicURL, cfg.AvatarLocalDir)
+	}
+
+	public := []gin.HandlerFunc{middleware.NoStore()}
+	if cfg.AuthRateLimitPerMinute > 0 {
+		public = append(public, middleware.RateLimit(middleware.NewRateLimiter(cfg.AuthRateLimitPerMinute, time.Minute), middleware.ClientIPKey))
+	}
+	protected := []gin.HandlerFunc{middleware.AuthMiddlewareWithKeys(keys, middleware.WithRevocationCheck(h.IsTokenRevoked))}
+	if cfg.AuthCheckUser {
+		protected = append(protected, h.RequireUser)
+	}
+	protected = append(protected, middleware.PrivateCache(30*time.Second))
+
+	available := Versions(h, cfg)
+	for _, version := range cfg.APIVersions {
+		routes, ok := available[version]
+		if !ok {
+			return fmt.Errorf("unknown API version %q", version)
+		}
+		RegisterVersion(router, cfg.APIPrefix, version, public, protected, routes)
+	}
+	return nil
+}
 
-	// Public routes
-	public := router.Group("/api/v1")
-	{
+// VersionRoutes registers one API version's handlers on its public and
+// protected groups
+type VersionRoutes func(public, protected *gin.RouterGroup)
+
+// Versions returns the route sets for every supported API version. A breaking
+// change gets a new entry, typically reusing the previous version's routes and
+// replacing only what changed, so older clients keep their version.
+func Versions(h *handlers.Handler, cfg configs.Config) map[string]VersionRoutes {
+	return map[string]VersionRoutes{
+		"v1": v1Routes(h, cfg),
+	}
+}
+
+// RegisterVersion mounts a version's routes under prefix/version, e.g. /api/v1,
+// with the given middleware on each group
+func RegisterVersion(router gin.IRouter, prefix, version string, public, protected []gin.HandlerFunc, routes VersionRoutes) {
+	base := path.Join("/", prefix, version)
+	routes(router.Group(base, public...), router.Group(base, protected...))
+}
+
+func v1Routes(h *handlers.Handler, cfg configs.Config) VersionRoutes {
+	return func(public, protected *gin.RouterGroup) {
 		public.POST("/auth/register", h.Register)
 		public.POST("/auth/login", h.Login)
-	}
+		public.POST("/auth/forgot-password", h.ForgotPassword)
+		public.POST("/auth/reset-password", h.ResetPassword)
+		protected.POST("/auth/logout", middleware.NoStore(), h.Logout)
 
-	// Protected routes
-	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(jwtSecretKey))
-	{
 		// User routes
 		protected.GET("/me", h.GetProfile)
 		protected.PUT("/me", h.UpdateProfile)
+		protected.PATCH("/me", h.PatchProfile)
+		protected.POST("/me/verify-password",
+			middleware.NoStore(),
+			middleware.RateLimit(middleware.NewRateLimiter(cfg.VerifyPasswordRateLimitPerMinute, time.Minute), middleware.UserKey),
+			h.VerifyPassword,
+		)
+		protected.PUT("/me/password",
+			middleware.NoStore(),
+			middleware.RateLimit(middleware.NewRateLimiter(cfg.VerifyPasswordRateLimitPerMinute, time.Minute), middleware.UserKey),
+			h.ChangePassword,
+		)
+		protected.POST("/me/avatar", h.UploadAvatar)
+
+		// Directory routes
+		protected.POST("/lookup/batch",
+			middleware.RateLimit(middleware.NewRateLimiter(cfg.DirectoryLookupRateLimitPerMinute, time.Minute), middleware.UserKey),
+			h.LookupDirectory,
+		)
 
 		// Contact routes
 		contacts := protected.Group("/contacts")
 		{
 			contacts.GET("", h.ListContacts)
 			contacts.POST("", h.CreateContact)
+			contacts.POST("/validate", h.ValidateContact)
+			contacts.POST("/batch", h.CreateContactsBatch)
+			contacts.POST("/tag-by-query", h.TagContactsByQuery)
+			contacts.POST("/merge", h.MergeContacts)
+			contacts.GET("/duplicates", h.ListDuplicates)
+			contacts.GET("/meta", h.ContactFieldsMeta)
+			contacts.GET("/check", h.CheckContactPhone)
+			contacts.POST("/import", h.ImportContacts)
+			// Job status is polled, so it must not be served from the browser cache
+			contacts.GET("/import/:jobId", middleware.NoStore(), h.GetImportJob)
 			contacts.GET("/:id", h.GetContact)
+			contacts.GET("/:id/vcard", h.ExportContactVCard)
+			contacts.GET("/:id/qr", h.GetContactQRCode)
 			contacts.PUT("/:id", h.UpdateContact)
+			contacts.PATCH("/:id", h.PatchContact)
 			contacts.DELETE("/:id", h.DeleteContact)
+			contacts.POST("/:id/restore", h.RestoreContact)
+		}
+
+		// Contact group routes
+		groups := protected.Group("/groups")
+		{
+			groups.GET("", h.ListGroups)
+			groups.POST("", h.CreateGroup)
+			groups.GET("/:id", h.GetGroup)
+			groups.PUT("/:id", h.RenameGroup)
+			groups.DELETE("/:id", h.DeleteGroup)
+			groups.PUT("/:id/contacts/:contactId", h.AddContactToGroup)
+			groups.DELETE("/:id/contacts/:contactId", h.RemoveContactFromGroup)
+		}
+
+		// Admin routes
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireRole(models.RoleAdmin), middleware.NoStore())
+		{
+			admin.POST("/invite-codes", h.CreateInviteCode)
+			admin.GET("/invite-codes", h.ListInviteCodes)
+			admin.GET("/contacts", h.AdminListContacts)
+			admin.GET("/migrations", h.MigrationStatus)
 		}
 	}
 }
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"user-service/configs"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/routes"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupRoutes_APIPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := GetTestJWTSecret()
+	accessToken, err := token.NewService(secret).Generate(1, models.RoleUser)
+	require.NoError(t, err)
+
+	profile := func(router *gin.Engine, path string) int {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", path, nil)
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+		router.ServeHTTP(w, httpReq)
+		return w.Code
+	}
+
+	t.Run("handlers are reachable under the configured prefix", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("GetUserProfile", mock.Anything, uint(1)).Return(&models.User{ID: 1}, nil).Once()
+		mockService.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil).Once()
+
+		router := gin.New()
+		cfg := configs.Config{APIPrefix: "/contacts-service", APIVersions: []string{"v1"}}
+		require.NoError(t, routes.SetupRoutes(router, handlers.NewHandler(mockService), cfg, nil, nil, token.SecretKeySet(secret)))
+
+		assert.Equal(t, http.StatusOK, profile(router, "/contacts-service/v1/me"))
+		assert.Equal(t, http.StatusNotFound, profile(router, "/api/v1/me"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("payload examples stay off in production", func(t *testing.T) {
+		t.Cleanup(func() { utils.SetValidationExamples(false) })
+		for env, want := range map[string]bool{"development": true, "production": false} {
+			cfg := configs.Config{Environment: env, APIPrefix: "/api", APIVersions: []string{"v1"}, ValidationExamples: true}
+			require.NoError(t, routes.SetupRoutes(gin.New(), handlers.NewHandler(new(MockService)), cfg, nil, nil, token.SecretKeySet(secret)))
+			assert.Equal(t, want, utils.ValidationExamplesEnabled(), env)
+		}
+	})
+
+	t.Run("unknown versions are rejected", func(t *testing.T) {
+		cfg := configs.Config{APIPrefix: "/api", APIVersions: []string{"v9"}}
+		err := routes.SetupRoutes(gin.New(), handlers.NewHandler(new(MockService)), cfg, nil, nil, token.SecretKeySet(secret))
+		assert.Error(t, err)
+	})
+
+	t.Run("versions run side by side", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("GetUserProfile", mock.Anything, uint(1)).Return(&models.User{ID: 1}, nil).Twice()
+
+		h := handlers.NewHandler(mockService)
+		v1 := routes.Versions(h, configs.Config{})["v1"]
+		require.NotNil(t, v1)
+
+		router := gin.New()
+		protected := []gin.HandlerFunc{func(c *gin.Context) { c.Set("user_id", uint(1)) }}
+		routes.RegisterVersion(router, "/api", "v1", nil, protected, v1)
+		routes.RegisterVersion(router, "/api", "v2", nil, protected, func(public, protected *gin.RouterGroup) {
+			v1(public, protected)
+			protected.GET("/me/settings", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		})
+
+		assert.Equal(t, http.StatusOK, profile(router, "/api/v1/me"))
+		assert.Equal(t, http.StatusOK, profile(router, "/api/v2/me"))
+		assert.Equal(t, http.StatusNoContent, profile(router, "/api/v2/me/settings"))
+		assert.Equal(t, http.StatusNotFound, profile(router, "/api/v1/me/settings"))
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestSetupRoutes_ImportJobNotCached(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := GetTestJWTSecret()
+	accessToken, err := token.NewService(secret).Generate(1, models.RoleUser)
+	require.NoError(t, err)
+
+	mockService := new(MockService)
+	mockService.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil)
+	mockService.On("GetImportJob", mock.Anything, uint(1), uint(5)).Return(&models.ImportJob{ID: 5, UserID: 1, Status: models.ImportStatusRunning}, nil).Once()
+	mockService.On("GetUserProfile", mock.Anything, uint(1)).Return(&models.User{ID: 1}, nil).Once()
+
+	router := gin.New()
+	cfg := configs.Config{APIPrefix: "/api", APIVersions: []string{"v1"}}
+	require.NoError(t, routes.SetupRoutes(router, handlers.NewHandler(mockService), cfg, nil, nil, token.SecretKeySet(secret)))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(http.MethodGet, path, nil)
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	w := get("/api/v1/contacts/import/5")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	// Other reads keep the short private cache
+	w = get("/api/v1/me")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, max-age=30", w.Header().Get("Cache-Control"))
+	mockService.AssertExpectations(t)
+}
+
+func TestSetupRoutes_Health(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := configs.Config{APIPrefix: "/api", APIVersions: []string{"v1"}}
+
+	probe := func(t *testing.T, router *gin.Engine, path string) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, httpReq)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+	components := func(body map[string]interface{}) map[string]interface{} {
+		return body["components"].(map[string]interface{})
+	}
+
+	t.Run("ready while the database is reachable", func(t *testing.T) {
+		testDB, err := SetupTestDB()
+		require.NoError(t, err)
+		defer testDB.Close()
+
+		router := gin.New()
+		require.NoError(t, routes.SetupRoutes(router, handlers.NewHandler(new(MockService)), cfg, testDB.DB, nil, token.SecretKeySet(GetTestJWTSecret())))
+
+		code, body := probe(t, router, "/health/ready")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]interface{}{"status": "up"}, components(body)["database"])
+		assert.NotContains(t, components(body), "redis")
+	})
+
+	t.Run("closed database is not ready but still live", func(t *testing.T) {
+		testDB, err := SetupTestDB()
+		require.NoError(t, err)
+		require.NoError(t, testDB.SqlDB.Close())
+
+		router := gin.New()
+		require.NoError(t, routes.SetupRoutes(router, handlers.NewHandler(new(MockService)), cfg, testDB.DB, nil, token.SecretKeySet(GetTestJWTSecret())))
+
+		code, body := probe(t, router, "/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unhealthy", body["status"])
+		assert.Equal(t, "down", components(body)["database"].(map[string]interface{})["status"])
+
+		code, _ = probe(t, router, "/health")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("unreachable redis is not ready", func(t *testing.T) {
+		testDB, err := SetupTestDB()
+		require.NoError(t, err)
+		defer testDB.Close()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+		client := goredis.NewClient(&goredis.Options{Addr: addr, MaxRetries: -1})
+		defer client.Close()
+
+		router := gin.New()
+		require.NoError(t, routes.SetupRoutes(router, handlers.NewHandler(new(MockService)), cfg, testDB.DB, client, token.SecretKeySet(GetTestJWTSecret())))
+
+		code, body := probe(t, router, "/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "up", components(body)["database"].(map[string]interface{})["status"])
+		assert.Equal(t, "down", components(body)["redis"].(map[string]interface{})["status"])
+	})
+}
+
+func TestSetupRoutes_Metrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+	_, err := CreateTestUser(context.Background(), repo)
+	require.NoError(t, err)
+
+	secret := GetTestJWTSecret()
+	router := gin.New()
+	h := handlers.NewHandler(service.NewService(repo, token.NewService(secret)))
+	cfg := configs.Config{APIPrefix: "/api", APIVersions: []string{"v1"}}
+	require.NoError(t, routes.SetupRoutes(router, h, cfg, nil, nil, token.SecretKeySet(secret)))
+
+	// scrape returns the value of a series, or 0 when it is absent
+	scrape := func(series string) float64 {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+		for _, line := range strings.Split(w.Body.String(), "\n") {
+			if value, ok := strings.CutPrefix(line, series+" "); ok {
+				v, err := strconv.ParseFloat(value, 64)
+				require.NoError(t, err)
+				return v
+			}
+		}
+		return 0
+	}
+	const failures = `user_service_login_attempts_total{result="failure"}`
+	const requests = `http_requests_total{method="POST",route="/api/v1/auth/login",status="401"}`
+	failuresBefore, requestsBefore := scrape(failures), scrape(requests)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"test@example.com","password":"wrong-password"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	assert.Equal(t, failuresBefore+1, scrape(failures))
+	assert.Equal(t, requestsBefore+1, scrape(requests))
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListContacts_FoldedSearch(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	str := func(s string) *string { return &s }
+	for _, contact := range []models.Contact{
+		{FullName: "José Álvarez", Phone: "14155550001"},
+		{FullName: "Alice Martin", Phone: "14155550002"},
+		{FullName: "ZOË ADAMS", Phone: "14155550003"},
+		{FullName: "Bob Stone", Phone: "14155550004", Company: str("Café Crème"), JobTitle: str("Gérant")},
+	} {
+		contact.UserID = user.ID
+		_, err := repo.CreateContact(ctx, &contact)
+		require.NoError(t, err)
+	}
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+	search := func(query string) []string {
+		t.Helper()
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Query: query, Sort: "full_name", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		names := []string{}
+		for _, contact := range contacts {
+			names = append(names, contact.FullName)
+		}
+		return names
+	}
+
+	t.Run("mixed case", func(t *testing.T) {
+		assert.Equal(t, []string{"Alice Martin"}, search("alice"))
+		assert.Equal(t, []string{"Alice Martin"}, search("ALICE mar"))
+		assert.Equal(t, []string{"ZOË ADAMS"}, search("adams"))
+	})
+
+	t.Run("accents", func(t *testing.T) {
+		assert.Equal(t, []string{"José Álvarez"}, search("jose"))
+		assert.Equal(t, []string{"José Álvarez"}, search("JOSÉ ALVAREZ"))
+		assert.Equal(t, []string{"José Álvarez"}, search("álvarez"))
+		assert.Equal(t, []string{"ZOË ADAMS"}, search("zoe"))
+	})
+
+	t.Run("company and job title", func(t *testing.T) {
+		assert.Equal(t, []string{"Bob Stone"}, search("cafe creme"))
+		assert.Equal(t, []string{"Bob Stone"}, search("gerant"))
+		// A match cannot span two fields
+		assert.Empty(t, search("stone cafe"))
+	})
+
+	t.Run("updates refresh the search text", func(t *testing.T) {
+		contact, err := repo.GetContactByPhone(ctx, user.ID, "14155550004")
+		require.NoError(t, err)
+		_, err = svc.PatchContact(ctx, user.ID, contact.ID, map[string]interface{}{"full_name": "Björn Stone", "company": (*string)(nil)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Björn Stone"}, search("bjorn"))
+		assert.Empty(t, search("cafe"))
+		assert.Equal(t, []string{"Björn Stone"}, search("gerant"))
+	})
+
+	t.Run("contacts written before the column are backfilled", func(t *testing.T) {
+		require.NoError(t, tdb.DB.Model(&models.Contact{}).Where("user_id = ?", user.ID).
+			Update("search_text", nil).Error)
+		assert.Empty(t, search("jose"))
+
+		backfilled, err := repo.BackfillSearchText(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), backfilled)
+		assert.Equal(t, []string{"José Álvarez"}, search("jose"))
+	})
+}
@@ -0,0 +1,110 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// Config holds the settings for seeding sample data
+type Config struct {
+	Users           int
+	ContactsPerUser int
+	// Seed makes the generated names and numbers reproducible
+	Seed int64
+	// Password is the password of every seeded user
+	Password string
+}
+
+// Result summarizes a seeding run
+type Result struct {
+	Users    int  `json:"users"`
+	Contacts int  `json:"contacts"`
+	Skipped  bool `json:"skipped"`
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken", "Frances", "Edsger", "Radia", "Tim"}
+	lastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson", "Allen", "Dijkstra", "Perlman", "Berners-Lee"}
+	companies  = []string{"Acme Corp", "Globex", "Initech", "Umbrella", "Hooli", "Stark Industries"}
+	jobTitles  = []string{"Engineer", "Designer", "Product Manager", "Sales Lead", "Support Specialist", "CTO"}
+)
+
+// Email returns the email of the i-th seeded user, counting from zero
+func Email(i int) string {
+	return fmt.Sprintf("seed.user%d@example.com", i+1)
+}
+
+// Run inserts cfg.Users users with cfg.ContactsPerUser contacts each. It
+// skips seeding when the first seeded user already exists, so running it again
+// leaves the data as it is.
+func Run(ctx context.Context, repo repository.Repository, cfg Config) (Result, error) {
+	if cfg.Users <= 0 {
+		return Result{}, nil
+	}
+
+	_, err := repo.GetUserByEmail(ctx, Email(0))
+	if err == nil {
+		return Result{Skipped: true}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Result{}, fmt.Errorf("failed to check for seeded data: %w", err)
+	}
+
+	hashedPassword, err := utils.HashPassword(cfg.Password)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to hash seed password: %w", err)
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	var result Result
+	for i := 0; i < cfg.Users; i++ {
+		user, err := repo.CreateUser(ctx, &models.User{
+			FullName: fullName(rng),
+			Email:    Email(i),
+			Password: hashedPassword,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to create seed user %d: %w", i+1, err)
+		}
+		result.Users++
+
+		contacts := make([]*models.Contact, 0, cfg.ContactsPerUser)
+		for j := 0; j < cfg.ContactsPerUser; j++ {
+			name := fullName(rng)
+			email := strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com"
+			company := companies[rng.Intn(len(companies))]
+			jobTitle := jobTitles[rng.Intn(len(jobTitles))]
+			contacts = append(contacts, &models.Contact{
+				UserID:   user.ID,
+				FullName: name,
+				// The contact index keeps numbers unique within the user
+				Phone:    fmt.Sprintf("1%d555%04d", 200+rng.Intn(800), j),
+				Email:    &email,
+				Company:  &company,
+				JobTitle: &jobTitle,
+				Favorite: rng.Intn(5) == 0,
+				Source:   models.ContactSourceManual,
+			})
+		}
+		if len(contacts) == 0 {
+			continue
+		}
+		if err := repo.CreateContacts(ctx, contacts); err != nil {
+			return result, fmt.Errorf("failed to create contacts of seed user %d: %w", i+1, err)
+		}
+		result.Contacts += len(contacts)
+	}
+	return result, nil
+}
+
+func fullName(rng *rand.Rand) string {
+	return firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))]
+}
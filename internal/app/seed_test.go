@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/seed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed_Run(t *testing.T) {
+	cfg := seed.Config{Users: 3, ContactsPerUser: 4, Seed: 42, Password: "password123"}
+
+	seedNames := func(t *testing.T) []string {
+		tdb, repo, cleanup := SetupTestEnvironment(t)
+		defer cleanup()
+
+		ctx := context.Background()
+		result, err := seed.Run(ctx, repo, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, seed.Result{Users: 3, Contacts: 12}, result)
+
+		var users, contacts int64
+		require.NoError(t, tdb.DB.Model(&models.User{}).Count(&users).Error)
+		require.NoError(t, tdb.DB.Model(&models.Contact{}).Count(&contacts).Error)
+		assert.Equal(t, int64(3), users)
+		assert.Equal(t, int64(12), contacts)
+
+		user, err := repo.GetUserByEmail(ctx, seed.Email(2))
+		require.NoError(t, err)
+		count, err := repo.CountContacts(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+
+		t.Run("second run skips", func(t *testing.T) {
+			result, err := seed.Run(ctx, repo, cfg)
+			require.NoError(t, err)
+			assert.True(t, result.Skipped)
+
+			require.NoError(t, tdb.DB.Model(&models.User{}).Count(&users).Error)
+			require.NoError(t, tdb.DB.Model(&models.Contact{}).Count(&contacts).Error)
+			assert.Equal(t, int64(3), users)
+			assert.Equal(t, int64(12), contacts)
+		})
+
+		var names []string
+		require.NoError(t, tdb.DB.Model(&models.Contact{}).Order("id").Pluck("full_name", &names).Error)
+		return names
+	}
+
+	first := seedNames(t)
+	assert.Equal(t, first, seedNames(t), "the same seed creates the same data")
+}
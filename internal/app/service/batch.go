@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"user-service/internal/app/models"
+	"user-service/internal/metrics"
+	"user-service/internal/utils"
+)
+
+// MaxBatchContacts caps the number of contacts accepted in one batch request
+const MaxBatchContacts = 100
+
+var (
+	ErrBatchEmpty           = errors.New("batch must contain at least one contact")
+	ErrBatchTooLarge        = errors.New("batch exceeds the maximum number of contacts")
+	ErrContactQuotaExceeded = errors.New("contact quota exceeded")
+	ErrFullNameRequired     = errors.New("full_name is required")
+	ErrPhoneRequired        = errors.New("phone is required")
+	ErrInvalidEmail         = errors.New("email must be a valid email address")
+	ErrDuplicateInBatch     = errors.New("phone number appears more than once in the batch")
+)
+
+// WithContactQuota caps how many contacts a user may own. Zero means unlimited.
+func WithContactQuota(max int) Option {
+	return func(s *service) {
+		s.contactQuota = max
+	}
+}
+
+// checkContactQuota fails when adding contacts would take the user over the quota
+func (s *service) checkContactQuota(ctx context.Context, userID uint, adding int) error {
+	if s.contactQuota <= 0 {
+		return nil
+	}
+	count, err := s.repo.CountContacts(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count+int64(adding) > int64(s.contactQuota) {
+		return ErrContactQuotaExceeded
+	}
+	return nil
+}
+
+// CreateContactsBatch validates every item and creates the valid ones in a single
+// transaction. Each item gets a result with either the created ID or its error;
+// the quota applies to the batch as a whole.
+func (s *service) CreateContactsBatch(ctx context.Context, userID uint, reqs []models.CreateContactRequest) ([]models.BatchContactResult, error) {
+	if len(reqs) == 0 {
+		return nil, ErrBatchEmpty
+	}
+	if len(reqs) > MaxBatchContacts {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]models.BatchContactResult, len(reqs))
+	contacts := make([]*models.Contact, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+	seen := make(map[string]struct{}, len(reqs))
+
+	for i := range reqs {
+		results[i].Index = i
+
+		if err := validateBatchContact(&reqs[i]); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if _, dup := seen[reqs[i].Phone]; dup {
+			results[i].Error = ErrDuplicateInBatch.Error()
+			continue
+		}
+
+		contact, err := s.buildContact(ctx, userID, &reqs[i], models.ContactSourceAPI)
+		if err != nil {
+			if errors.Is(err, ErrPhoneExists) {
+				results[i].Error = ErrPhoneExists.Error()
+				continue
+			}
+			return nil, err
+		}
+
+		seen[reqs[i].Phone] = struct{}{}
+		contacts = append(contacts, contact)
+		positions = append(positions, i)
+	}
+
+	if len(contacts) == 0 {
+		return results, nil
+	}
+
+	if err := s.checkContactQuota(ctx, userID, len(contacts)); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateContacts(ctx, contacts); err != nil {
+		if isContactPhoneConflict(err) {
+			return nil, ErrPhoneExists
+		}
+		return nil, err
+	}
+	metrics.ContactsCreated.Add(float64(len(contacts)))
+
+	for i, contact := range contacts {
+		results[positions[i]].ID = contact.ID
+	}
+
+	return results, nil
+}
+
+// validateBatchContact applies the checks that request binding performs for
+// single creates to batch and import rows, and normalizes the phone, so
+// duplicates in the batch are caught whatever their formatting
+func validateBatchContact(req *models.CreateContactRequest) error {
+	if req.FullName == "" {
+		return ErrFullNameRequired
+	}
+	if req.Phone == "" {
+		return ErrPhoneRequired
+	}
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	req.Phone = phone
+	if req.Email != nil && *req.Email != "" && !utils.ValidateEmail(*req.Email) {
+		return ErrInvalidEmail
+	}
+	return nil
+}
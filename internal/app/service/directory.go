@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"user-service/internal/app/models"
+)
+
+// MaxLookupPhones caps the number of phone numbers in one directory lookup
+const MaxLookupPhones = 100
+
+var (
+	ErrLookupEmpty    = errors.New("lookup must contain at least one phone number")
+	ErrLookupTooLarge = errors.New("lookup exceeds the maximum number of phone numbers")
+)
+
+// LookupDirectory returns the public info and spam reports of users holding
+// the given phone numbers. Only users who opted in to the directory are
+// returned; other numbers are left out without revealing whether they exist.
+func (s *service) LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error) {
+	unique := make([]string, 0, len(phones))
+	seen := make(map[string]struct{}, len(phones))
+	for _, phone := range phones {
+		phone = strings.TrimSpace(phone)
+		if phone == "" {
+			continue
+		}
+		if _, dup := seen[phone]; dup {
+			continue
+		}
+		seen[phone] = struct{}{}
+		unique = append(unique, phone)
+	}
+
+	if len(unique) == 0 {
+		return nil, ErrLookupEmpty
+	}
+	if len(unique) > MaxLookupPhones {
+		return nil, ErrLookupTooLarge
+	}
+	return s.repo.LookupDirectory(ctx, unique)
+}
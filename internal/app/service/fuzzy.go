@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"user-service/internal/app/models"
+)
+
+const (
+	// fuzzyNameThreshold is the name similarity, from 0 to 1, at which two
+	// contacts with similar phones are considered likely duplicates
+	fuzzyNameThreshold = 0.8
+
+	// fuzzyPhoneDistance is the number of digit edits two phones may differ by
+	// and still count as similar
+	fuzzyPhoneDistance = 1
+)
+
+// dedupCandidate is a contact an import row is compared against
+type dedupCandidate struct {
+	id       uint
+	row      int
+	fullName string
+	name     []rune
+	phone    []rune
+}
+
+// fuzzyMatcher finds likely duplicates by name similarity combined with a
+// matching or nearly matching phone number
+type fuzzyMatcher struct {
+	candidates []dedupCandidate
+}
+
+// loadFuzzyMatcher seeds a matcher with all of the user's contacts
+func (s *service) loadFuzzyMatcher(ctx context.Context, userID uint) (*fuzzyMatcher, error) {
+	m := &fuzzyMatcher{}
+	err := s.repo.StreamContacts(ctx, userID, models.ContactFilter{}, 0, -1, func(contact *models.Contact) error {
+		m.add(contact.ID, 0, contact.FullName, contact.Phone)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// add makes a contact a candidate for later rows; row is 0 for contacts that
+// existed before the import
+func (m *fuzzyMatcher) add(id uint, row int, fullName, phone string) {
+	m.candidates = append(m.candidates, dedupCandidate{
+		id:       id,
+		row:      row,
+		fullName: fullName,
+		name:     []rune(strings.ToLower(collapseSpace(fullName))),
+		phone:    []rune(phoneDigits(phone)),
+	})
+}
+
+// match returns the first candidate resembling the contact, or nil
+func (m *fuzzyMatcher) match(fullName, phone string) *dedupCandidate {
+	name := []rune(strings.ToLower(collapseSpace(fullName)))
+	digits := []rune(phoneDigits(phone))
+
+	for i := range m.candidates {
+		c := &m.candidates[i]
+		if levenshtein(digits, c.phone) > fuzzyPhoneDistance {
+			continue
+		}
+		if similarity(name, c.name) >= fuzzyNameThreshold {
+			return c
+		}
+	}
+	return nil
+}
+
+// similarity scales the edit distance of two strings to 1 for equal strings
+// and 0 for entirely different ones
+func similarity(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions turning a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func phoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"user-service/internal/app/models"
+)
+
+var (
+	ErrGroupNotFound     = errors.New("group not found")
+	ErrGroupNameRequired = errors.New("group name is required")
+	ErrGroupNameTaken    = errors.New("a group with this name already exists")
+)
+
+// ListGroups returns the user's contact groups ordered by name
+func (s *service) ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error) {
+	return s.repo.ListGroups(ctx, userID)
+}
+
+// CreateGroup creates a contact group. Names are stored with whitespace
+// collapsed and must be unique per user.
+func (s *service) CreateGroup(ctx context.Context, userID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	name := collapseSpace(req.Name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	group, err := s.repo.CreateGroup(ctx, &models.ContactGroup{UserID: userID, Name: name})
+	if err != nil {
+		if isGroupNameConflict(err) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *service) GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error) {
+	group, err := s.repo.GetGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// RenameGroup renames a contact group under the same rules as CreateGroup
+func (s *service) RenameGroup(ctx context.Context, userID, groupID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	name := collapseSpace(req.Name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	group, err := s.repo.RenameGroup(ctx, userID, groupID, name)
+	if err != nil {
+		if isGroupNameConflict(err) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// DeleteGroup deletes a contact group; its contacts are kept
+func (s *service) DeleteGroup(ctx context.Context, userID, groupID uint) error {
+	memberIDs, err := s.repo.DeleteGroup(ctx, userID, groupID)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+	// The former members were touched, so their cached copies are stale
+	for _, contactID := range memberIDs {
+		s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	}
+	return nil
+}
+
+// AddContactToGroup puts one of the user's contacts in one of their groups
+func (s *service) AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	if err := s.checkGroupMember(ctx, userID, groupID, contactID); err != nil {
+		return err
+	}
+	err := s.repo.AddContactToGroup(ctx, userID, groupID, contactID)
+	s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	return err
+}
+
+// RemoveContactFromGroup takes one of the user's contacts out of one of their groups
+func (s *service) RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	if err := s.checkGroupMember(ctx, userID, groupID, contactID); err != nil {
+		return err
+	}
+	err := s.repo.RemoveContactFromGroup(ctx, userID, groupID, contactID)
+	s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	return err
+}
+
+// checkGroupMember checks that the group and the contact both belong to the user
+func (s *service) checkGroupMember(ctx context.Context, userID, groupID, contactID uint) error {
+	if _, err := s.repo.GetGroup(ctx, userID, groupID); err != nil {
+		return ErrGroupNotFound
+	}
+	if _, err := s.repo.GetContact(ctx, userID, contactID); err != nil {
+		return ErrContactNotFound
+	}
+	return nil
+}
+
+// isGroupNameConflict reports whether err is a violation of the unique index
+// on the user's group names, covering the MySQL and SQLite error messages
+func isGroupNameConflict(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "idx_contact_groups_user_name") ||
+		strings.Contains(msg, "UNIQUE constraint failed: contact_groups.user_id, contact_groups.name")
+}
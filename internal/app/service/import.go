@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"user-service/internal/app/models"
+	"user-service/internal/app/vcard"
+	"user-service/internal/logger"
+	"user-service/internal/metrics"
+)
+
+var (
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrInvalidImportFile = errors.New("import file must be a vCard file or a CSV with full_name and phone columns")
+	ErrInvalidDedupMode  = errors.New("dedup must be exact or fuzzy")
+)
+
+// importProgressInterval is how many rows an import processes between writes
+// of its counts
+const importProgressInterval = 100
+
+// Runner executes background tasks
+type Runner interface {
+	Go(task func())
+}
+
+// goroutineRunner runs each task on its own goroutine
+type goroutineRunner struct{}
+
+func (goroutineRunner) Go(task func()) {
+	go task()
+}
+
+// WithRunner sets the runner used for background work such as contact imports
+func WithRunner(runner Runner) Option {
+	return func(s *service) {
+		s.runner = runner
+	}
+}
+
+// StartContactImport records an import job for the CSV or vCard data and
+// processes it in the background, deduplicating rows with the given mode
+// (exact when empty)
+func (s *service) StartContactImport(ctx context.Context, userID uint, data []byte, dedup string) (*models.ImportJob, error) {
+	switch dedup {
+	case "":
+		dedup = models.ImportDedupExact
+	case models.ImportDedupExact, models.ImportDedupFuzzy:
+	default:
+		return nil, ErrInvalidDedupMode
+	}
+
+	rows, source, err := parseContactFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.CreateImportJob(ctx, &models.ImportJob{
+		UserID: userID,
+		Status: models.ImportStatusPending,
+		Total:  len(rows),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobID := job.ID
+	s.runner.Go(func() {
+		s.processContactImport(context.Background(), userID, jobID, rows, source, dedup)
+	})
+
+	return job, nil
+}
+
+// GetImportJob returns the status of one of the user's import jobs
+func (s *service) GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// processContactImport creates the contacts of an import job, skipping duplicates
+func (s *service) processContactImport(ctx context.Context, userID, jobID uint, rows []models.CreateContactRequest, source, dedup string) {
+	// A panic in a background task would otherwise leave the job running forever
+	defer func() {
+		if r := recover(); r != nil {
+			message := fmt.Sprintf("import aborted: %v", r)
+			_ = s.repo.UpdateImportJob(ctx, jobID, map[string]interface{}{
+				"status": models.ImportStatusFailed,
+				"error":  message,
+			})
+			logger.Error(errors.New(message), map[string]interface{}{"service": "ContactImport", "job_id": jobID})
+		}
+	}()
+
+	if err := s.repo.UpdateImportJob(ctx, jobID, map[string]interface{}{"status": models.ImportStatusRunning}); err != nil {
+		logger.Error(err, map[string]interface{}{"service": "ContactImport", "job_id": jobID})
+		return
+	}
+
+	var matcher *fuzzyMatcher
+	if dedup == models.ImportDedupFuzzy {
+		var err error
+		if matcher, err = s.loadFuzzyMatcher(ctx, userID); err != nil {
+			message := "import aborted: failed to load contacts for deduplication"
+			_ = s.repo.UpdateImportJob(ctx, jobID, map[string]interface{}{
+				"status": models.ImportStatusFailed,
+				"error":  message,
+			})
+			logger.Error(err, map[string]interface{}{"service": "ContactImport", "job_id": jobID})
+			return
+		}
+	}
+
+	var imported, skipped, failed int
+	var duplicates models.ImportDuplicates
+	counts := func() map[string]interface{} {
+		return map[string]interface{}{
+			"imported": imported,
+			"skipped":  skipped,
+			"failed":   failed,
+			"flagged":  len(duplicates),
+		}
+	}
+	for i := range rows {
+		// Pollers see the counts grow while a long import runs
+		if i > 0 && i%importProgressInterval == 0 {
+			if err := s.repo.UpdateImportJob(ctx, jobID, counts()); err != nil {
+				logger.Error(err, map[string]interface{}{"service": "ContactImport", "job_id": jobID})
+			}
+		}
+
+		// Rows miss the request binding, so they get the checks batch creates do
+		if err := validateBatchContact(&rows[i]); err != nil {
+			failed++
+			continue
+		}
+
+		contact, err := s.buildContact(ctx, userID, &rows[i], source)
+		if errors.Is(err, ErrPhoneExists) {
+			skipped++
+			continue
+		}
+		if err == nil && matcher != nil {
+			if match := matcher.match(contact.FullName, contact.Phone); match != nil {
+				duplicates = append(duplicates, models.ImportDuplicate{
+					Row:       i + 1,
+					FullName:  contact.FullName,
+					Phone:     contact.Phone,
+					MatchID:   match.id,
+					MatchRow:  match.row,
+					MatchName: match.fullName,
+				})
+				continue
+			}
+		}
+		if err == nil {
+			err = s.checkContactQuota(ctx, userID, 1)
+		}
+		if err == nil {
+			_, err = s.repo.CreateContact(ctx, contact)
+		}
+		if err != nil {
+			failed++
+			continue
+		}
+		imported++
+		metrics.ContactsCreated.Inc()
+		if matcher != nil {
+			matcher.add(contact.ID, i+1, contact.FullName, contact.Phone)
+		}
+	}
+
+	updates := counts()
+	updates["status"] = models.ImportStatusDone
+	if len(duplicates) > 0 {
+		updates["duplicates"] = duplicates
+	}
+	if err := s.repo.UpdateImportJob(ctx, jobID, updates); err != nil {
+		logger.Error(err, map[string]interface{}{"service": "ContactImport", "job_id": jobID})
+	}
+}
+
+// parseContactFile reads contact rows from a vCard file or a CSV and returns
+// the source the contacts are recorded with
+func parseContactFile(data []byte) ([]models.CreateContactRequest, string, error) {
+	if vcard.IsVCard(data) {
+		rows, err := vcard.Unmarshal(data)
+		if err != nil {
+			return nil, "", ErrInvalidImportFile
+		}
+		return rows, models.ContactSourceVCardImport, nil
+	}
+
+	rows, err := parseContactCSV(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, models.ContactSourceCSVImport, nil
+}
+
+// parseContactCSV reads contact rows from a CSV with a header row.
+// The full_name and phone columns are required; email, company and job_title
+// are optional.
+func parseContactCSV(data []byte) ([]models.CreateContactRequest, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidImportFile
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	nameCol, hasName := columns["full_name"]
+	phoneCol, hasPhone := columns["phone"]
+	emailCol, hasEmail := columns["email"]
+	companyCol, hasCompany := columns["company"]
+	jobTitleCol, hasJobTitle := columns["job_title"]
+	if !hasName || !hasPhone {
+		return nil, ErrInvalidImportFile
+	}
+
+	field := func(record []string, col int) string {
+		if col < len(record) {
+			return strings.TrimSpace(record[col])
+		}
+		return ""
+	}
+
+	var rows []models.CreateContactRequest
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidImportFile
+		}
+
+		row := models.CreateContactRequest{
+			FullName: field(record, nameCol),
+			Phone:    field(record, phoneCol),
+		}
+		if hasEmail {
+			if email := field(record, emailCol); email != "" {
+				row.Email = &email
+			}
+		}
+		if hasCompany {
+			if company := field(record, companyCol); company != "" {
+				row.Company = &company
+			}
+		}
+		if hasJobTitle {
+			if jobTitle := field(record, jobTitleCol); jobTitle != "" {
+				row.JobTitle = &jobTitle
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"user-service/internal/app/models"
+)
+
+var (
+	ErrMergeDuplicatesRequired = errors.New("at least one duplicate contact is required")
+	ErrMergeTooLarge           = errors.New("merge exceeds the maximum number of contacts")
+	ErrMergeIntoSelf           = errors.New("a contact cannot be merged into itself")
+)
+
+// MergeContacts folds duplicate contacts into the primary one and deletes
+// them. The primary keeps its own name, phone and any other field it has;
+// empty fields are filled from the first duplicate, in the given order, that
+// has them. The contact is a favorite or blocked if any of them is, and it
+// gains the duplicates' tags and groups. Every contact must belong to the
+// user, otherwise nothing is merged.
+func (s *service) MergeContacts(ctx context.Context, userID, primaryID uint, duplicateIDs []uint) (*models.Contact, error) {
+	if len(duplicateIDs) == 0 {
+		return nil, ErrMergeDuplicatesRequired
+	}
+	if len(duplicateIDs) > MaxBatchContacts {
+		return nil, ErrMergeTooLarge
+	}
+
+	primary, err := s.repo.GetContact(ctx, userID, primaryID)
+	if err != nil {
+		return nil, ErrContactNotFound
+	}
+
+	ids := make([]uint, 0, len(duplicateIDs))
+	seen := make(map[uint]bool, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		if id == primaryID {
+			return nil, ErrMergeIntoSelf
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		duplicate, err := s.repo.GetContact(ctx, userID, id)
+		if err != nil {
+			return nil, ErrContactNotFound
+		}
+		mergeContactFields(primary, duplicate)
+		ids = append(ids, id)
+	}
+
+	err = s.repo.MergeContacts(ctx, primary, ids)
+	for _, id := range append(ids, primaryID) {
+		s.cacheDelete(ctx, contactCacheKey(userID, id))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return primary, nil
+}
+
+// mergeContactFields fills the primary contact's empty fields from the duplicate
+func mergeContactFields(primary, duplicate *models.Contact) {
+	for _, field := range []struct{ dst, src **string }{
+		{&primary.Email, &duplicate.Email},
+		{&primary.AvatarURL, &duplicate.AvatarURL},
+		{&primary.Company, &duplicate.Company},
+		{&primary.JobTitle, &duplicate.JobTitle},
+	} {
+		if emptyToNil(*field.dst) == nil {
+			*field.dst = emptyToNil(*field.src)
+		}
+	}
+	primary.Favorite = primary.Favorite || duplicate.Favorite
+	primary.Blocked = primary.Blocked || duplicate.Blocked
+}
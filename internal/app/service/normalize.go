@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Contact name casing modes
+const (
+	NameCasePreserve = "preserve" // keep the casing as sent
+	NameCaseTitle    = "title"    // capitalize each word, e.g. "jOHN doe" -> "John Doe"
+)
+
+// WithNameCasing sets how contact names are cased after whitespace is
+// normalized. Unknown modes fall back to NameCasePreserve.
+func WithNameCasing(mode string) Option {
+	return func(s *service) {
+		switch mode {
+		case NameCaseTitle:
+			s.nameCasing = mode
+		default:
+			s.nameCasing = NameCasePreserve
+		}
+	}
+}
+
+// normalizeName returns the canonical form of a contact name
+func (s *service) normalizeName(name string) string {
+	name = collapseSpace(name)
+	if s.nameCasing == NameCaseTitle {
+		name = titleCase(name)
+	}
+	return name
+}
+
+// collapseSpace trims the value and collapses runs of whitespace into one space
+func collapseSpace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// titleCase upper-cases the first letter of every word, including each part of
+// a hyphenated word, and lower-cases the rest
+func titleCase(value string) string {
+	runes := []rune(value)
+	start := true
+	for i, r := range runes {
+		if start {
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+		start = r == ' ' || r == '-'
+	}
+	return string(runes)
+}
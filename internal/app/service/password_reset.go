@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+	"user-service/internal/logger"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultPasswordResetTTL is how long a reset token stays valid unless
+// WithPasswordReset says otherwise
+const DefaultPasswordResetTTL = time.Hour
+
+var (
+	ErrResetTokenInvalid = errors.New("password reset token is invalid")
+	ErrResetTokenExpired = errors.New("password reset token has expired")
+	ErrResetTokenUsed    = errors.New("password reset token has already been used")
+)
+
+// ResetSender delivers password reset tokens to their users
+type ResetSender interface {
+	SendPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error
+}
+
+// LogResetSender writes reset requests to the application log. It is meant for
+// development, where no mail delivery is set up. The token itself is only
+// logged with IncludeToken, since anyone reading the log could use it.
+type LogResetSender struct {
+	IncludeToken bool
+}
+
+// SendPasswordReset logs the reset request
+func (s LogResetSender) SendPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error {
+	fields := map[string]interface{}{
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	}
+	if s.IncludeToken {
+		fields["token"] = token
+	}
+	logger.Info("Password reset requested", fields)
+	return nil
+}
+
+// WebhookResetSender posts reset tokens to an HTTP endpoint that mails them
+type WebhookResetSender struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookResetSender creates a webhook reset sender for the given URL
+func NewWebhookResetSender(url string) *WebhookResetSender {
+	return &WebhookResetSender{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SendPasswordReset posts the reset token as JSON
+func (s *WebhookResetSender) SendPasswordReset(ctx context.Context, user models.User, token string, expiresAt time.Time) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":      "password_reset_requested",
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"token":      token,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("password reset webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WithPasswordReset sets how long reset tokens stay valid and how they are
+// delivered. A non-positive ttl keeps DefaultPasswordResetTTL and a nil sender
+// keeps LogResetSender.
+func WithPasswordReset(ttl time.Duration, sender ResetSender) Option {
+	return func(s *service) {
+		if ttl > 0 {
+			s.resetTTL = ttl
+		}
+		if sender != nil {
+			s.resetSender = sender
+		}
+	}
+}
+
+// RequestPasswordReset issues a single-use reset token for the user with the
+// given email and sends it to them. Unknown emails are ignored without an
+// error so callers cannot tell which accounts exist.
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+
+	// Only the hash is stored, so a leaked table cannot be used to reset passwords
+	reset := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(s.resetTTL),
+	}
+	if err := s.repo.CreatePasswordResetToken(ctx, reset); err != nil {
+		return err
+	}
+	return s.resetSender.SendPasswordReset(ctx, *user, token, reset.ExpiresAt)
+}
+
+// ResetPassword sets a new password for the owner of a reset token and uses
+// the token up
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tokenHash := hashResetToken(token)
+	now := time.Now()
+	var userID uint
+	// The token is used up in the same transaction as the password write, so
+	// a failed write leaves it usable
+	err = s.repo.WithTx(ctx, func(repo repository.Repository) error {
+		consumed, err := repo.ConsumePasswordResetToken(ctx, tokenHash, now)
+		if err != nil {
+			return err
+		}
+
+		reset, err := repo.GetPasswordResetToken(ctx, tokenHash)
+		if err != nil || reset == nil {
+			return ErrResetTokenInvalid
+		}
+		if !consumed {
+			// Work out why the token could not be used
+			if reset.UsedAt != nil {
+				return ErrResetTokenUsed
+			}
+			return ErrResetTokenExpired
+		}
+
+		userID = reset.UserID
+		_, err = repo.UpdateUser(ctx, reset.UserID, map[string]interface{}{"password": string(hashedPassword)})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProfile(ctx, userID)
+	return nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,14 +2,27 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"regexp"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"user-service/internal/app/cache"
+	"user-service/internal/app/migrations"
 	"user-service/internal/app/models"
 	"user-service/internal/app/repository"
+	"user-service/internal/app/token"
+	"user-service/internal/logger"
+	"user-service/internal/metrics"
+	"user-service/internal/utils"
+	sharedcache "user-service/pkg/cache"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
 var (
@@ -17,53 +30,269 @@ var (
 	ErrEmailTaken         = errors.New("email is already taken")
 	ErrContactNotFound    = errors.New("contact not found")
 	ErrPhoneExists        = errors.New("phone number already exists for this user")
-	ErrInvalidPhone       = errors.New("phone number must contain only digits (0-9)")
+	ErrInvalidPhone       = errors.New("phone number must be in E.164 format, e.g. +14155552671")
+	ErrInvalidDuplicateBy = errors.New("duplicates can only be grouped by name or phone")
+	ErrRegistrationClosed = errors.New("registration disabled")
+	ErrInviteCodeInvalid  = errors.New("invite code is invalid")
+	ErrInviteCodeUsedUp   = errors.New("invite code has no uses remaining")
+	ErrInviteCodeExpired  = errors.New("invite code has expired")
+	ErrSearchRequired     = errors.New("search query is required")
+	ErrPhoneTaken         = errors.New("phone number is already registered")
+	ErrIncorrectPassword  = errors.New("password is incorrect")
+	ErrContactChanged     = errors.New("contact was modified since it was loaded")
+	ErrTokenNotRevocable  = errors.New("token has no ID or expiry and cannot be revoked")
+	ErrAccountLocked      = errors.New("account is locked after too many failed logins, try again later")
+	ErrInvalidPatchField  = errors.New("field cannot be patched")
+	ErrUserNotFound       = errors.New("user not found")
 )
 
+// Contact search modes control what ListContacts does with a blank query
+const (
+	SearchModeOptional = "optional" // blank query lists all contacts
+	SearchModeEmpty    = "empty"    // blank query returns no contacts
+	SearchModeRequired = "required" // blank query is rejected with ErrSearchRequired
+)
+
+// Registration phone policies control what Register does when another user
+// already holds the phone number
+const (
+	PhonePolicyAllow = "allow" // register as usual
+	PhonePolicyWarn  = "warn"  // register and log a warning
+	PhonePolicyBlock = "block" // reject with ErrPhoneTaken
+)
+
+// PhoneConflictError reports a phone number clash together with the contact
+// that already uses it. It matches ErrPhoneExists with errors.Is.
+type PhoneConflictError struct {
+	Contact *models.Contact
+}
+
+func (e *PhoneConflictError) Error() string {
+	return ErrPhoneExists.Error()
+}
+
+func (e *PhoneConflictError) Unwrap() error {
+	return ErrPhoneExists
+}
+
+// AccountLockedError reports a login rejected because the account is locked
+// until Until. It matches ErrAccountLocked with errors.Is.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
 type Service interface {
-	Register(ctx context.Context, req models.RegisterRequest) (*models.User, error)
+	Register(ctx context.Context, req models.RegisterRequest) (*models.User, string, error)
 	Login(ctx context.Context, req models.LoginRequest) (map[string]interface{}, error)
 	GetUserProfile(ctx context.Context, userID uint) (*models.User, error)
+	GetUser(ctx context.Context, userID uint) (*models.User, error)
+	GetUserProfileStats(ctx context.Context, userID uint) (*models.UserProfile, error)
 	UpdateProfile(ctx context.Context, userID uint, req models.UpdateProfileRequest) (*models.User, error)
+	PatchProfile(ctx context.Context, userID uint, req models.PatchProfileRequest) (*models.User, error)
+	VerifyPassword(ctx context.Context, userID uint, password string) error
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error
+	UpdateAvatar(ctx context.Context, userID uint, url string) (*models.User, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	Logout(ctx context.Context, userID uint, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error)
+
+	CreateInviteCode(ctx context.Context, adminID uint, req models.CreateInviteCodeRequest) (*models.InviteCode, error)
+	ListInviteCodes(ctx context.Context) ([]models.InviteCode, error)
 
 	ListContacts(ctx context.Context, userID uint, req *models.ListContactsRequest) ([]models.Contact, int64, error)
+	StreamContacts(ctx context.Context, userID uint, req *models.ListContactsRequest, fn func(*models.Contact) error) error
+	ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error)
 	CreateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error)
+	ValidateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error)
+	ContactPhoneExists(ctx context.Context, userID uint, phone string) (bool, error)
+	ListDuplicates(ctx context.Context, userID uint, req *models.ListDuplicatesRequest) ([]models.DuplicateGroup, int64, error)
 	GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error)
 	UpdateContact(ctx context.Context, userID, contactID uint, req *models.UpdateContactRequest) (*models.Contact, error)
+	PatchContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error)
 	DeleteContact(ctx context.Context, userID, contactID uint) error
+	RestoreContact(ctx context.Context, userID, contactID uint) error
+	DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error
+	MergeContacts(ctx context.Context, userID, primaryID uint, duplicateIDs []uint) (*models.Contact, error)
+	CreateContactsBatch(ctx context.Context, userID uint, reqs []models.CreateContactRequest) ([]models.BatchContactResult, error)
+	TagContactsByQuery(ctx context.Context, userID uint, req *models.TagByQueryRequest) (int64, error)
+	AdminListContacts(ctx context.Context, req *models.AdminListContactsRequest) ([]models.AdminContact, int64, error)
+
+	ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error)
+	CreateGroup(ctx context.Context, userID uint, req models.ContactGroupRequest) (*models.ContactGroup, error)
+	GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error)
+	RenameGroup(ctx context.Context, userID, groupID uint, req models.ContactGroupRequest) (*models.ContactGroup, error)
+	DeleteGroup(ctx context.Context, userID, groupID uint) error
+	AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error
+	RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error
+
+	StartContactImport(ctx context.Context, userID uint, data []byte, dedup string) (*models.ImportJob, error)
+	GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error)
 }
 
 type service struct {
-	repo      repository.Repository
-	jwtSecret string
+	repo   repository.Repository
+	tokens token.Service
+	runner Runner
+
+	registrationEnabled bool
+	inviteCodes         map[string]struct{}
+	searchMode          string
+	contactQuota        int
+	uniqueUserPhone     bool
+	phonePolicy         string
+	contactCountCap     int
+	nameCasing          string
+	resetTTL            time.Duration
+	resetSender         ResetSender
+	lockoutAttempts     int
+	lockoutDuration     time.Duration
+
+	profileReads singleflight.Group
+	profileCache *cache.LRU[uint, models.User]
+
+	sharedCache    sharedcache.Store
+	sharedCacheTTL time.Duration
 }
 
-func NewService(repo repository.Repository, jwtSecret string) Service {
-	return &service{
-		repo:      repo,
-		jwtSecret: jwtSecret,
+// Option configures optional service behaviour
+type Option func(*service)
+
+// WithRegistration controls whether open registration is allowed. When disabled,
+// a request carrying one of the given static invite codes, or a valid code from
+// the invite_codes table, may still register.
+func WithRegistration(enabled bool, inviteCodes []string) Option {
+	return func(s *service) {
+		s.registrationEnabled = enabled
+		s.inviteCodes = make(map[string]struct{}, len(inviteCodes))
+		for _, code := range inviteCodes {
+			if code = strings.TrimSpace(code); code != "" {
+				s.inviteCodes[code] = struct{}{}
+			}
+		}
 	}
 }
 
-// Register creates a new user account
-func (s *service) Register(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
-	// Validate phone if provided
-	if req.Phone != nil && *req.Phone != "" {
-		if err := validatePhone(*req.Phone); err != nil {
-			return nil, err
+// WithSearchMode sets how ListContacts treats a blank search query. Unknown
+// modes fall back to SearchModeOptional.
+func WithSearchMode(mode string) Option {
+	return func(s *service) {
+		switch mode {
+		case SearchModeEmpty, SearchModeRequired:
+			s.searchMode = mode
+		default:
+			s.searchMode = SearchModeOptional
 		}
 	}
+}
+
+// WithContactCountCap bounds how many matching contacts ListContacts counts or
+// returns. Zero means unlimited.
+func WithContactCountCap(max int) Option {
+	return func(s *service) {
+		s.contactCountCap = max
+	}
+}
+
+// WithProfileCache caches user profiles for up to ttl, holding at most size
+// entries. A size of zero disables the cache.
+func WithProfileCache(size int, ttl time.Duration) Option {
+	return func(s *service) {
+		s.profileCache = cache.NewLRU[uint, models.User](size, ttl)
+	}
+}
+
+// WithUniqueUserPhone rejects registrations and profile updates that reuse a
+// phone number already held by another user
+func WithUniqueUserPhone(enabled bool) Option {
+	return func(s *service) {
+		s.uniqueUserPhone = enabled
+	}
+}
+
+// WithRegistrationPhonePolicy sets how Register treats a phone number already
+// held by another user. Unknown policies fall back to PhonePolicyAllow; unique
+// user phones always block.
+func WithRegistrationPhonePolicy(policy string) Option {
+	return func(s *service) {
+		switch policy {
+		case PhonePolicyWarn, PhonePolicyBlock:
+			s.phonePolicy = policy
+		default:
+			s.phonePolicy = PhonePolicyAllow
+		}
+	}
+}
+
+// WithLoginLockout locks an account for duration once maxAttempts logins in a
+// row fail. Zero attempts disables the lockout.
+func WithLoginLockout(maxAttempts int, duration time.Duration) Option {
+	return func(s *service) {
+		s.lockoutAttempts = maxAttempts
+		s.lockoutDuration = duration
+	}
+}
+
+func NewService(repo repository.Repository, tokens token.Service, opts ...Option) Service {
+	s := &service{
+		repo:                repo,
+		tokens:              tokens,
+		runner:              goroutineRunner{},
+		registrationEnabled: true,
+		searchMode:          SearchModeOptional,
+		phonePolicy:         PhonePolicyAllow,
+		nameCasing:          NameCasePreserve,
+		resetTTL:            DefaultPasswordResetTTL,
+		resetSender:         LogResetSender{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register creates a new user account and returns it with an access token
+func (s *service) Register(ctx context.Context, req models.RegisterRequest) (*models.User, string, error) {
+	// Invite-only mode requires a code before any other work is done
+	if !s.registrationEnabled && strings.TrimSpace(req.InviteCode) == "" {
+		return nil, "", ErrRegistrationClosed
+	}
+
+	// Validate and normalize phone if provided
+	req.Phone = emptyToNil(trimSpace(req.Phone))
+	if req.Phone != nil {
+		phone, err := normalizePhone(*req.Phone)
+		if err != nil {
+			return nil, "", err
+		}
+		req.Phone = &phone
+	}
 
 	// Check if email already exists
 	existingUser, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
-		return nil, ErrEmailTaken
+		return nil, "", ErrEmailTaken
+	}
+
+	if req.Phone != nil {
+		if err := s.checkRegistrationPhone(ctx, req.Email, *req.Phone); err != nil {
+			return nil, "", err
+		}
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	user := &models.User{
@@ -71,115 +300,748 @@ func (s *service) Register(ctx context.Context, req models.RegisterRequest) (*mo
 		Email:    req.Email,
 		Phone:    req.Phone,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
 	}
 
-	return s.repo.CreateUser(ctx, user)
+	// The invite code is redeemed in the same transaction as the insert, so
+	// a registration that fails gives the use back
+	err = s.repo.WithTx(ctx, func(repo repository.Repository) error {
+		if !s.registrationEnabled {
+			if err := s.redeemInviteCode(ctx, repo, strings.TrimSpace(req.InviteCode)); err != nil {
+				return err
+			}
+		}
+
+		// The email check above is not atomic with the insert; a concurrent
+		// registration that wins the race is caught by the unique constraint
+		created, err := repo.CreateUser(ctx, user)
+		if err != nil {
+			if isUserEmailConflict(err) {
+				return ErrEmailTaken
+			}
+			if isUserPhoneConflict(err) {
+				return ErrPhoneTaken
+			}
+			return err
+		}
+		user = created
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	tokenString, err := s.tokens.Generate(user.ID, user.Role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, tokenString, nil
 }
 
+// redeemInviteCode accepts a static invite code or consumes one use of a stored code
+func (s *service) redeemInviteCode(ctx context.Context, repo repository.Repository, code string) error {
+	if _, ok := s.inviteCodes[code]; ok {
+		return nil
+	}
+
+	now := time.Now()
+	consumed, err := repo.ConsumeInviteCode(ctx, code, now)
+	if err != nil {
+		return err
+	}
+	if consumed {
+		return nil
+	}
+
+	// Work out why the code could not be used
+	invite, err := repo.GetInviteCode(ctx, code)
+	if err != nil || invite == nil {
+		return ErrInviteCodeInvalid
+	}
+	if invite.ExpiresAt != nil && !invite.ExpiresAt.After(now) {
+		return ErrInviteCodeExpired
+	}
+	return ErrInviteCodeUsedUp
+}
+
+// CreateInviteCode mints a new invite code on behalf of an admin
+func (s *service) CreateInviteCode(ctx context.Context, adminID uint, req models.CreateInviteCodeRequest) (*models.InviteCode, error) {
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		generated, err := generateInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	}
+
+	invite := &models.InviteCode{
+		Code:          code,
+		CreatedBy:     adminID,
+		UsesRemaining: req.Uses,
+	}
+	if req.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	return s.repo.CreateInviteCode(ctx, invite)
+}
+
+// ListInviteCodes returns all invite codes
+func (s *service) ListInviteCodes(ctx context.Context) ([]models.InviteCode, error) {
+	return s.repo.ListInviteCodes(ctx)
+}
+
+// generateInviteCode returns a random, human-typeable invite code
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(buf)), nil
+}
+
+// GetUserProfile loads a profile from the optional caches, or from the
+// repository with concurrent reads of the same user sharing a single query.
+// The shared cache does not hold password hashes.
 func (s *service) GetUserProfile(ctx context.Context, userID uint) (*models.User, error) {
-	return s.repo.GetUserByID(ctx, userID)
+	if user, ok := s.profileCache.Get(userID); ok {
+		return &user, nil
+	}
+
+	v, err, _ := s.profileReads.Do(profileKey(userID), func() (interface{}, error) {
+		var cached models.User
+		if s.cacheGet(ctx, profileCacheKey(userID), &cached) {
+			s.profileCache.Set(userID, cached)
+			return cached, nil
+		}
+
+		user, err := s.repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		s.profileCache.Set(userID, *user)
+		cached = *user
+		cached.Password = ""
+		s.cacheSet(ctx, profileCacheKey(userID), cached)
+		return *user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each caller gets its own copy of the shared result
+	user := v.(models.User)
+	return &user, nil
+}
+
+// GetUserProfileStats returns the user's profile with when they joined and
+// how many contacts they have. Both are read uncached, so the two agree.
+func (s *service) GetUserProfileStats(ctx context.Context, userID uint) (*models.UserProfile, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountContacts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserProfile{User: *user, MemberSince: user.CreatedAt, TotalContacts: total}, nil
+}
+
+// GetUser loads the user from the repository, bypassing the profile caches, so
+// an account deleted by any path is noticed on its next request. It returns
+// ErrUserNotFound when the user does not exist.
+func (s *service) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// invalidateProfile drops any cached or in-flight read of the user's profile
+func (s *service) invalidateProfile(ctx context.Context, userID uint) {
+	s.profileReads.Forget(profileKey(userID))
+	s.profileCache.Delete(userID)
+	s.cacheDelete(ctx, profileCacheKey(userID))
+}
+
+func profileKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
 }
 
 func (s *service) UpdateProfile(ctx context.Context, userID uint, req models.UpdateProfileRequest) (*models.User, error) {
-	updates := make(map[string]interface{})
+	patch := models.PatchProfileRequest{Phone: req.Phone, DirectoryOptIn: req.DirectoryOptIn}
 	if req.FullName != "" {
-		updates["full_name"] = req.FullName
+		patch.FullName = &req.FullName
+	}
+	return s.PatchProfile(ctx, userID, patch)
+}
+
+// PatchProfile changes only the profile fields that were sent. A sent name
+// must not be blank; an empty phone is ignored as on UpdateProfile.
+func (s *service) PatchProfile(ctx context.Context, userID uint, req models.PatchProfileRequest) (*models.User, error) {
+	updates := make(map[string]interface{})
+	if req.FullName != nil {
+		fullName := strings.TrimSpace(*req.FullName)
+		if fullName == "" {
+			return nil, ErrFullNameRequired
+		}
+		updates["full_name"] = fullName
 	}
 	if req.Phone != nil && *req.Phone != "" {
-		if err := validatePhone(*req.Phone); err != nil {
+		phone, err := normalizePhone(*req.Phone)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkUserPhoneAvailable(ctx, userID, phone); err != nil {
 			return nil, err
 		}
-		updates["phone"] = *req.Phone
+		updates["phone"] = phone
 	}
+	if req.DirectoryOptIn != nil {
+		updates["directory_opt_in"] = *req.DirectoryOptIn
+	}
+
+	user, err := s.repo.UpdateUser(ctx, userID, updates)
+	if err != nil && isUserPhoneConflict(err) {
+		return nil, ErrPhoneTaken
+	}
+	s.invalidateProfile(ctx, userID)
+	return user, err
+}
+
+// VerifyPassword checks password against the user's stored hash without any side effects
+func (s *service) VerifyPassword(ctx context.Context, userID uint, password string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// ChangePassword replaces the user's password after checking the current one
+func (s *service) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	if err := s.VerifyPassword(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.UpdateUser(ctx, userID, map[string]interface{}{"password": string(hashedPassword)})
+	s.invalidateProfile(ctx, userID)
+	return err
+}
+
+// UpdateAvatar points the user's avatar at an uploaded image
+func (s *service) UpdateAvatar(ctx context.Context, userID uint, url string) (*models.User, error) {
+	user, err := s.repo.UpdateUser(ctx, userID, map[string]interface{}{"avatar_url": url})
+	s.invalidateProfile(ctx, userID)
+	return user, err
+}
+
+// Logout revokes the token with the given ID until it expires
+func (s *service) Logout(ctx context.Context, userID uint, jti string, expiresAt time.Time) error {
+	if jti == "" || expiresAt.IsZero() {
+		return ErrTokenNotRevocable
+	}
+	return s.repo.RevokeToken(ctx, &models.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt}, time.Now())
+}
 
-	return s.repo.UpdateUser(ctx, userID, updates)
+// IsTokenRevoked reports whether the token with the given ID was logged out
+func (s *service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.repo.IsTokenRevoked(ctx, jti)
+}
+
+// checkUserPhoneAvailable returns ErrPhoneTaken when unique user phones are
+// enforced and another user already holds the number
+func (s *service) checkUserPhoneAvailable(ctx context.Context, userID uint, phone string) error {
+	if !s.uniqueUserPhone {
+		return nil
+	}
+	exists, err := s.repo.CheckUserPhoneExists(ctx, phone, userID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrPhoneTaken
+	}
+	return nil
+}
+
+// checkRegistrationPhone applies the registration phone policy to a number
+// another user may already hold
+func (s *service) checkRegistrationPhone(ctx context.Context, email, phone string) error {
+	policy := s.phonePolicy
+	if s.uniqueUserPhone {
+		policy = PhonePolicyBlock
+	}
+	if policy == PhonePolicyAllow {
+		return nil
+	}
+
+	exists, err := s.repo.CheckUserPhoneExists(ctx, phone, 0)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if policy == PhonePolicyWarn {
+		logger.Warn("Registration reuses a phone number held by another user", map[string]interface{}{
+			"service": "Register",
+			"email":   email,
+		})
+		return nil
+	}
+	return ErrPhoneTaken
+}
+
+// isUserEmailConflict reports whether err is a violation of the unique
+// constraint on users.email, covering the MySQL and SQLite error messages
+func isUserEmailConflict(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "for key 'users.email'") ||
+		strings.Contains(msg, "for key 'email'") ||
+		strings.Contains(msg, migrations.UniqueUserEmailIndex) ||
+		strings.Contains(msg, "uni_users_email") ||
+		strings.Contains(msg, "UNIQUE constraint failed: users.email")
+}
+
+// isContactPhoneConflict reports whether err is a violation of the unique
+// index on the phone numbers of a user's contacts, covering the MySQL and
+// SQLite error messages
+func isContactPhoneConflict(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "idx_contacts_user_phone_key") ||
+		strings.Contains(msg, "UNIQUE constraint failed: contacts.user_id, contacts.phone_key")
+}
+
+// isUserPhoneConflict reports whether err is a violation of the unique index on
+// users.phone, covering the MySQL and SQLite error messages
+func isUserPhoneConflict(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, migrations.UniqueUserPhoneIndex) ||
+		strings.Contains(msg, "UNIQUE constraint failed: users.phone")
 }
 
 func (s *service) ListContacts(ctx context.Context, userID uint, req *models.ListContactsRequest) ([]models.Contact, int64, error) {
+	filter, empty, err := s.listFilter(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if empty {
+		if req.WithTotal {
+			if req.TotalAll, err = s.repo.CountContacts(ctx, userID); err != nil {
+				return nil, 0, err
+			}
+		}
+		return []models.Contact{}, 0, nil
+	}
+	filter.MaxCount = s.contactCountCap
+
+	contacts, total, err := s.repo.ListContacts(ctx, userID, filter, req.Offset, req.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.contactCountCap > 0 && total > int64(s.contactCountCap) {
+		total = int64(s.contactCountCap)
+		req.CountCapped = true
+	}
+
+	if req.WithTotal {
+		// Without filters the filtered count already is the grand total
+		if filter.IsEmpty() && !req.CountCapped {
+			req.TotalAll = total
+		} else if req.TotalAll, err = s.repo.CountContacts(ctx, userID); err != nil {
+			return nil, 0, err
+		}
+	}
+	return contacts, total, nil
+}
+
+// StreamContacts calls fn for each contact ListContacts would return for req,
+// without loading the whole page at once
+func (s *service) StreamContacts(ctx context.Context, userID uint, req *models.ListContactsRequest, fn func(*models.Contact) error) error {
+	filter, empty, err := s.listFilter(req)
+	if err != nil || empty {
+		return err
+	}
+	return s.repo.StreamContacts(ctx, userID, filter, req.Offset, req.Limit, fn)
+}
+
+// listFilter normalizes a list request and builds its filter. empty is true
+// when the search mode says a blank query lists nothing.
+func (s *service) listFilter(req *models.ListContactsRequest) (filter models.ContactFilter, empty bool, err error) {
+	if strings.TrimSpace(req.Query) == "" {
+		switch s.searchMode {
+		case SearchModeRequired:
+			return filter, false, ErrSearchRequired
+		case SearchModeEmpty:
+			return filter, true, nil
+		}
+	}
+
+	// Stored names are whitespace-normalized, so queries are too
+	req.Query = collapseSpace(req.Query)
+	req.Tag = strings.ToLower(collapseSpace(req.Tag))
 	req.Offset = (req.Page - 1) * req.Limit
-	return s.repo.ListContacts(ctx, userID, req.Query, req.Offset, req.Limit)
+	return req.Filter(), false, nil
+}
+
+// ContactsVersion returns the version of the user's contact list, used to
+// detect an unchanged list
+func (s *service) ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error) {
+	return s.repo.ContactsVersion(ctx, userID)
 }
 
 func (s *service) CreateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error) {
+	contact, err := s.buildContact(ctx, userID, req, models.ContactSourceManual)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkContactQuota(ctx, userID, 1); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateContact(ctx, contact)
+	if err != nil {
+		// Another request took the number after buildContact checked it
+		if isContactPhoneConflict(err) {
+			return nil, s.phoneConflict(ctx, s.repo, userID, contact.Phone)
+		}
+		return nil, err
+	}
+	metrics.ContactsCreated.Inc()
+	return created, nil
+}
+
+// ValidateContact runs the create-time validations, quota included, and
+// returns the contact that would be created, without persisting it
+func (s *service) ValidateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error) {
+	contact, err := s.buildContact(ctx, userID, req, models.ContactSourceManual)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkContactQuota(ctx, userID, 1); err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// buildContact validates a create request and builds the contact to persist,
+// recording the path it arrived through as its source
+func (s *service) buildContact(ctx context.Context, userID uint, req *models.CreateContactRequest, source string) (*models.Contact, error) {
+	fullName := s.normalizeName(req.FullName)
+	if fullName == "" {
+		return nil, ErrFullNameRequired
+	}
+
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if phone number already exists
-	exists, err := s.repo.CheckContactExists(ctx, userID, req.Phone)
+	exists, err := s.repo.CheckContactExists(ctx, userID, phone, 0)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, ErrPhoneExists
+		return nil, s.phoneConflict(ctx, s.repo, userID, phone)
 	}
 
-	contact := &models.Contact{
-		UserID:   userID,
-		FullName: req.FullName,
-		Phone:    req.Phone,
-		Email:    req.Email,
+	return &models.Contact{
+		UserID:    userID,
+		FullName:  fullName,
+		Phone:     phone,
+		Email:     req.Email,
+		AvatarURL: emptyToNil(req.AvatarURL),
+		Company:   emptyToNil(trimSpace(req.Company)),
+		JobTitle:  emptyToNil(trimSpace(req.JobTitle)),
+		Blocked:   req.Blocked,
+		Source:    source,
+	}, nil
+}
+
+// ListDuplicates returns a page of contact groups sharing the same name or phone
+func (s *service) ListDuplicates(ctx context.Context, userID uint, req *models.ListDuplicatesRequest) ([]models.DuplicateGroup, int64, error) {
+	if req.By != "name" && req.By != "phone" {
+		return nil, 0, ErrInvalidDuplicateBy
+	}
+	req.Offset = (req.Page - 1) * req.Limit
+	return s.repo.ListDuplicateGroups(ctx, userID, req.By, req.Offset, req.Limit)
+}
+
+// AdminListContacts returns a page of contacts across users, marking soft-deleted ones
+func (s *service) AdminListContacts(ctx context.Context, req *models.AdminListContactsRequest) ([]models.AdminContact, int64, error) {
+	req.Offset = (req.Page - 1) * req.Limit
+	contacts, count, err := s.repo.AdminListContacts(ctx, req.UserID, req.IncludeDeleted, req.Offset, req.Limit)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return s.repo.CreateContact(ctx, contact)
+	result := make([]models.AdminContact, len(contacts))
+	for i, contact := range contacts {
+		result[i] = models.AdminContact{Contact: contact, UserID: contact.UserID}
+		if contact.DeletedAt.Valid {
+			deletedAt := contact.DeletedAt.Time
+			result[i].Deleted = true
+			result[i].DeletedAt = &deletedAt
+		}
+	}
+
+	return result, count, nil
 }
 
+// phoneConflict builds the duplicate-phone error, including the existing contact when it can be loaded
+func (s *service) phoneConflict(ctx context.Context, repo repository.Repository, userID uint, phone string) error {
+	existing, err := repo.GetContactByPhone(ctx, userID, phone)
+	if err != nil {
+		return ErrPhoneExists
+	}
+	return &PhoneConflictError{Contact: existing}
+}
+
+// ContactPhoneExists reports whether one of the user's contacts has the phone
+// number, normalized as on create
+func (s *service) ContactPhoneExists(ctx context.Context, userID uint, phone string) (bool, error) {
+	if strings.TrimSpace(phone) == "" {
+		return false, ErrPhoneRequired
+	}
+	normalized, err := normalizePhone(phone)
+	if err != nil {
+		return false, err
+	}
+	return s.repo.CheckContactExists(ctx, userID, normalized, 0)
+}
+
+// GetContact loads a contact from the shared cache, if any, or the repository
 func (s *service) GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error) {
+	var cached models.Contact
+	if s.cacheGet(ctx, contactCacheKey(userID, contactID), &cached) {
+		return &cached, nil
+	}
+
 	contact, err := s.repo.GetContact(ctx, userID, contactID)
 	if err != nil {
 		return nil, ErrContactNotFound
 	}
+	s.cacheSet(ctx, contactCacheKey(userID, contactID), contact)
 	return contact, nil
 }
 
+// UpdateContact replaces the contact's name and phone and changes the
+// optional fields that were sent
 func (s *service) UpdateContact(ctx context.Context, userID, contactID uint, req *models.UpdateContactRequest) (*models.Contact, error) {
-	// Check if contact exists
-	existing, err := s.repo.GetContact(ctx, userID, contactID)
-	if err != nil {
-		return nil, ErrContactNotFound
+	return s.PatchContact(ctx, userID, contactID, req.Patch().Updates())
+}
+
+// patchableContactColumns lists the columns PatchContact accepts, in the
+// order they are validated
+var patchableContactColumns = []string{"full_name", "phone", "email", "avatar_url", "company", "job_title", "favorite", "blocked"}
+
+// PatchContact changes only the given fields of a contact, keyed by column as
+// built by PatchContactRequest.Updates. Values are normalized and validated
+// like on create, and the phone is checked against the user's other contacts
+// only when it is being changed.
+func (s *service) PatchContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error) {
+	for column := range updates {
+		if !slices.Contains(patchableContactColumns, column) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatchField, column)
+		}
 	}
 
-	// Check if new phone number conflicts with existing contacts (excluding current contact)
-	if existing.Phone != req.Phone {
-		exists, err := s.repo.CheckContactExists(ctx, userID, req.Phone)
-		if err != nil {
-			return nil, err
+	patch := make(map[string]interface{}, len(updates))
+	for _, column := range patchableContactColumns {
+		value, ok := updates[column]
+		if !ok {
+			continue
 		}
-		if exists {
-			return nil, ErrPhoneExists
+		switch column {
+		case "full_name":
+			fullName, _ := value.(string)
+			fullName = s.normalizeName(fullName)
+			if fullName == "" {
+				return nil, ErrFullNameRequired
+			}
+			patch[column] = fullName
+		case "phone":
+			phone, _ := value.(string)
+			normalized, err := normalizePhone(phone)
+			if err != nil {
+				return nil, err
+			}
+			patch[column] = normalized
+		case "email":
+			// A nil or empty email is written as NULL, clearing it; an
+			// email left out of updates is not written at all
+			email, _ := value.(*string)
+			patch[column] = emptyToNil(trimSpace(email))
+		case "avatar_url":
+			// An empty string removes the avatar
+			url, _ := value.(*string)
+			patch[column] = emptyToNil(url)
+		case "company", "job_title":
+			text, _ := value.(*string)
+			patch[column] = emptyToNil(trimSpace(text))
+		case "favorite", "blocked":
+			flag, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPatchField, column)
+			}
+			patch[column] = flag
 		}
 	}
 
-	updates := map[string]interface{}{
-		"full_name": req.FullName,
-		"phone":     req.Phone,
-		"email":     req.Email,
-	}
+	// The phone check and the write share a transaction holding the user's
+	// lock, so a concurrent write cannot take the number in between
+	var contact *models.Contact
+	err := s.repo.WithTx(ctx, func(repo repository.Repository) error {
+		if len(patch) > 0 {
+			// A user that cannot be locked has no contacts either
+			if err := repo.LockUser(ctx, userID); err != nil {
+				return ErrContactNotFound
+			}
+		}
+
+		// Check if contact exists
+		existing, err := repo.GetContact(ctx, userID, contactID)
+		if err != nil {
+			return ErrContactNotFound
+		}
+		if len(patch) == 0 {
+			contact = existing
+			return nil
+		}
 
-	return s.repo.UpdateContact(ctx, userID, contactID, updates)
+		// Check the phone against the user's other contacts; the contact keeping its own number is no conflict
+		if phone, ok := patch["phone"].(string); ok {
+			exists, err := repo.CheckContactExists(ctx, userID, phone, existing.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return s.phoneConflict(ctx, repo, userID, phone)
+			}
+		}
+		// Only an email being set must be valid
+		if email, ok := patch["email"].(*string); ok && email != nil && !utils.ValidateEmail(*email) {
+			return ErrInvalidEmail
+		}
+
+		contact, err = repo.UpdateContact(ctx, userID, contactID, patch)
+		if err != nil && isContactPhoneConflict(err) {
+			return ErrPhoneExists
+		}
+		return err
+	})
+	if len(patch) > 0 {
+		s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return contact, nil
 }
 
 func (s *service) DeleteContact(ctx context.Context, userID, contactID uint) error {
 	err := s.repo.DeleteContact(ctx, userID, contactID)
+	s.cacheDelete(ctx, contactCacheKey(userID, contactID))
 	if err != nil {
 		return ErrContactNotFound
 	}
 	return nil
 }
 
+// RestoreContact brings back a deleted contact. It counts towards the quota
+// again, and fails with ErrPhoneExists when a contact added meanwhile has
+// the same phone.
+func (s *service) RestoreContact(ctx context.Context, userID, contactID uint) error {
+	if err := s.checkContactQuota(ctx, userID, 1); err != nil {
+		return err
+	}
+	if err := s.repo.RestoreContact(ctx, userID, contactID); err != nil {
+		if isContactPhoneConflict(err) {
+			return ErrPhoneExists
+		}
+		return ErrContactNotFound
+	}
+	return nil
+}
+
+// DeleteContactIfUnchanged deletes a contact only if it is still at version,
+// returning ErrContactChanged when it was written since
+func (s *service) DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error {
+	err := s.repo.DeleteContactIfUnchanged(ctx, userID, contactID, version)
+	s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	if err != nil {
+		// Nothing was deleted: either the contact is gone or it changed meanwhile
+		if _, getErr := s.repo.GetContact(ctx, userID, contactID); getErr == nil {
+			return ErrContactChanged
+		}
+		return ErrContactNotFound
+	}
+	return nil
+}
+
 // Login authenticates a user and returns a JWT token
-func (s *service) Login(ctx context.Context, req models.LoginRequest) (map[string]interface{}, error) {
+func (s *service) Login(ctx context.Context, req models.LoginRequest) (result map[string]interface{}, err error) {
+	defer func() {
+		if err != nil {
+			metrics.LoginAttempts.Inc("failure")
+		} else {
+			metrics.LoginAttempts.Inc("success")
+		}
+	}()
+
 	user, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if s.lockoutAttempts > 0 {
+			lockUntil := now.Add(s.lockoutDuration)
+			locked, lockErr := s.repo.RecordFailedLogin(ctx, user.ID, s.lockoutAttempts, lockUntil)
+			if lockErr != nil {
+				logger.Error(lockErr, map[string]interface{}{
+					"service": "Login",
+					"user_id": user.ID,
+				})
+			} else if locked {
+				return nil, &AccountLockedError{Until: lockUntil}
+			}
+		}
 		return nil, errors.New("invalid password")
 	}
 
-	// Generate JWT token
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = user.ID
+	// Record the login for inactivity tracking and reset the failed login
+	// count; a failure here must not block the login
+	if err := s.repo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		logger.Error(err, map[string]interface{}{
+			"service": "Login",
+			"user_id": user.ID,
+		})
+	}
 
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	// Generate JWT token
+	tokenString, err := s.tokens.Generate(user.ID, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -196,21 +1058,28 @@ func (s *service) Login(ctx context.Context, req models.LoginRequest) (map[strin
 	}, nil
 }
 
-// validatePhone checks if phone number contains only digits
-func validatePhone(phone string) error {
-	// Remove whitespace
-	phone = strings.TrimSpace(phone)
-
-	// Check if empty after trimming
-	if phone == "" {
-		return nil // Empty is allowed since it's optional
+// emptyToNil treats an empty optional string as absent
+func emptyToNil(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
 	}
+	return value
+}
 
-	// Check if contains only digits
-	phoneRegex := regexp.MustCompile(`^[0-9]+$`)
-	if !phoneRegex.MatchString(phone) {
-		return ErrInvalidPhone
+// trimSpace trims an optional string, keeping nil as nil
+func trimSpace(value *string) *string {
+	if value == nil {
+		return nil
 	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
 
-	return nil
+// normalizePhone returns the stored form of a phone number, see utils.NormalizePhone
+func normalizePhone(phone string) (string, error) {
+	normalized, ok := utils.NormalizePhone(phone)
+	if !ok {
+		return "", ErrInvalidPhone
+	}
+	return normalized, nil
 }
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/internal/app/fieldcrypt"
+	"user-service/internal/logger"
+	sharedcache "user-service/pkg/cache"
+)
+
+// WithSharedCache caches profiles and contacts in store for ttl, so all
+// instances share them. A failing store is logged and bypassed, never failing
+// the request. A nil store disables the cache.
+func WithSharedCache(store sharedcache.Store, ttl time.Duration) Option {
+	return func(s *service) {
+		s.sharedCache = store
+		s.sharedCacheTTL = ttl
+	}
+}
+
+func profileCacheKey(userID uint) string {
+	return "user-service:profile:" + profileKey(userID)
+}
+
+func contactCacheKey(userID, contactID uint) string {
+	return "user-service:contact:" + strconv.FormatUint(uint64(userID), 10) + ":" + strconv.FormatUint(uint64(contactID), 10)
+}
+
+// cacheGet decodes the value cached under key into v and reports whether there was one
+func (s *service) cacheGet(ctx context.Context, key string, v interface{}) bool {
+	if s.sharedCache == nil {
+		return false
+	}
+
+	data, err := s.sharedCache.Get(ctx, key)
+	if err == nil {
+		err = decodeCached(data, v)
+	}
+	if err != nil {
+		if !errors.Is(err, sharedcache.ErrMiss) {
+			logCacheError("get", key, err)
+		}
+		return false
+	}
+	return true
+}
+
+// cacheSet caches v under key
+func (s *service) cacheSet(ctx context.Context, key string, v interface{}) {
+	if s.sharedCache == nil {
+		return
+	}
+
+	data, err := encodeCached(v)
+	if err == nil {
+		err = s.sharedCache.Set(ctx, key, data, s.sharedCacheTTL)
+	}
+	if err != nil {
+		logCacheError("set", key, err)
+	}
+}
+
+// cacheDelete invalidates key
+func (s *service) cacheDelete(ctx context.Context, key string) {
+	if s.sharedCache == nil {
+		return
+	}
+
+	if err := s.sharedCache.Delete(ctx, key); err != nil {
+		logCacheError("delete", key, err)
+	}
+}
+
+func logCacheError(operation, key string, err error) {
+	logger.Warn("Shared cache unavailable", map[string]interface{}{
+		"operation": operation,
+		"key":       key,
+		"error":     err.Error(),
+	})
+}
+
+// encodeCached gob-encodes v, keeping fields the JSON encoding hides. Cached
+// values are encrypted like contact columns when field encryption is enabled.
+func encodeCached(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	encrypted, err := fieldcrypt.Encrypt(buf.String())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encrypted), nil
+}
+
+func decodeCached(data []byte, v interface{}) error {
+	plaintext, err := fieldcrypt.Decrypt(string(data))
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(strings.NewReader(plaintext)).Decode(v)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"user-service/internal/app/models"
+)
+
+var (
+	ErrTagRequired       = errors.New("tag is required")
+	ErrTagFilterRequired = errors.New("a filter or confirm=true is required to tag every contact")
+)
+
+// TagContactsByQuery tags every contact matching the request's filters and
+// returns how many contacts were newly tagged. Tags are stored lower-cased
+// with whitespace collapsed.
+func (s *service) TagContactsByQuery(ctx context.Context, userID uint, req *models.TagByQueryRequest) (int64, error) {
+	tag := strings.ToLower(collapseSpace(req.Tag))
+	if tag == "" {
+		return 0, ErrTagRequired
+	}
+
+	req.Query = collapseSpace(req.Query)
+	filter := req.Filter()
+	if filter.IsEmpty() && !req.Confirm {
+		return 0, ErrTagFilterRequired
+	}
+
+	taggedIDs, err := s.repo.TagContacts(ctx, userID, filter, tag)
+	if err != nil {
+		return 0, err
+	}
+	// Tagging touched the contacts, so their cached copies are stale
+	for _, contactID := range taggedIDs {
+		s.cacheDelete(ctx, contactCacheKey(userID, contactID))
+	}
+	return int64(len(taggedIDs)), nil
+}
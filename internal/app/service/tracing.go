@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/tracing"
+)
+
+// tracedService wraps a Service, recording a span named after each method
+type tracedService struct {
+	next Service
+}
+
+// NewTracedService wraps next so each call records a span, e.g.
+// "service.Login", as a child of the span in the call's context
+func NewTracedService(next Service) Service {
+	return &tracedService{next: next}
+}
+
+func (s *tracedService) Register(ctx context.Context, req models.RegisterRequest) (*models.User, string, error) {
+	ctx, span := tracing.Start(ctx, "service.Register")
+	defer span.End()
+	user, token, err := s.next.Register(ctx, req)
+	span.RecordError(err)
+	return user, token, err
+}
+
+func (s *tracedService) Login(ctx context.Context, req models.LoginRequest) (map[string]interface{}, error) {
+	ctx, span := tracing.Start(ctx, "service.Login")
+	defer span.End()
+	result, err := s.next.Login(ctx, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) GetUserProfile(ctx context.Context, userID uint) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "service.GetUserProfile")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetUserProfile(ctx, userID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "service.GetUser")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetUser(ctx, userID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) GetUserProfileStats(ctx context.Context, userID uint) (*models.UserProfile, error) {
+	ctx, span := tracing.Start(ctx, "service.GetUserProfileStats")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetUserProfileStats(ctx, userID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) UpdateProfile(ctx context.Context, userID uint, req models.UpdateProfileRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateProfile")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.UpdateProfile(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) PatchProfile(ctx context.Context, userID uint, req models.PatchProfileRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "service.PatchProfile")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.PatchProfile(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) VerifyPassword(ctx context.Context, userID uint, password string) error {
+	ctx, span := tracing.Start(ctx, "service.VerifyPassword")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.VerifyPassword(ctx, userID, password)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "service.ChangePassword")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) UpdateAvatar(ctx context.Context, userID uint, url string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateAvatar")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.UpdateAvatar(ctx, userID, url)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "service.RequestPasswordReset")
+	defer span.End()
+	err := s.next.RequestPasswordReset(ctx, email)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "service.ResetPassword")
+	defer span.End()
+	err := s.next.ResetPassword(ctx, token, newPassword)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) Logout(ctx context.Context, userID uint, jti string, expiresAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "service.Logout")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.Logout(ctx, userID, jti, expiresAt)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "service.IsTokenRevoked")
+	defer span.End()
+	result, err := s.next.IsTokenRevoked(ctx, jti)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error) {
+	ctx, span := tracing.Start(ctx, "service.LookupDirectory")
+	defer span.End()
+	result, err := s.next.LookupDirectory(ctx, phones)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) CreateInviteCode(ctx context.Context, adminID uint, req models.CreateInviteCodeRequest) (*models.InviteCode, error) {
+	ctx, span := tracing.Start(ctx, "service.CreateInviteCode")
+	defer span.End()
+	result, err := s.next.CreateInviteCode(ctx, adminID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) ListInviteCodes(ctx context.Context) ([]models.InviteCode, error) {
+	ctx, span := tracing.Start(ctx, "service.ListInviteCodes")
+	defer span.End()
+	result, err := s.next.ListInviteCodes(ctx)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) ListContacts(ctx context.Context, userID uint, req *models.ListContactsRequest) ([]models.Contact, int64, error) {
+	ctx, span := tracing.Start(ctx, "service.ListContacts")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, total, err := s.next.ListContacts(ctx, userID, req)
+	span.RecordError(err)
+	return result, total, err
+}
+
+func (s *tracedService) StreamContacts(ctx context.Context, userID uint, req *models.ListContactsRequest, fn func(*models.Contact) error) error {
+	ctx, span := tracing.Start(ctx, "service.StreamContacts")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.StreamContacts(ctx, userID, req, fn)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error) {
+	ctx, span := tracing.Start(ctx, "service.ContactsVersion")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.ContactsVersion(ctx, userID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) CreateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.CreateContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.CreateContact(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) ValidateContact(ctx context.Context, userID uint, req *models.CreateContactRequest) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.ValidateContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.ValidateContact(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) ContactPhoneExists(ctx context.Context, userID uint, phone string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "service.ContactPhoneExists")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.ContactPhoneExists(ctx, userID, phone)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) ListDuplicates(ctx context.Context, userID uint, req *models.ListDuplicatesRequest) ([]models.DuplicateGroup, int64, error) {
+	ctx, span := tracing.Start(ctx, "service.ListDuplicates")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, total, err := s.next.ListDuplicates(ctx, userID, req)
+	span.RecordError(err)
+	return result, total, err
+}
+
+func (s *tracedService) GetContact(ctx context.Context, userID, contactID uint) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.GetContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetContact(ctx, userID, contactID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) UpdateContact(ctx context.Context, userID, contactID uint, req *models.UpdateContactRequest) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.UpdateContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.UpdateContact(ctx, userID, contactID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) PatchContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.PatchContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.PatchContact(ctx, userID, contactID, updates)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) DeleteContact(ctx context.Context, userID, contactID uint) error {
+	ctx, span := tracing.Start(ctx, "service.DeleteContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.DeleteContact(ctx, userID, contactID)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) RestoreContact(ctx context.Context, userID, contactID uint) error {
+	ctx, span := tracing.Start(ctx, "service.RestoreContact")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.RestoreContact(ctx, userID, contactID)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error {
+	ctx, span := tracing.Start(ctx, "service.DeleteContactIfUnchanged")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.DeleteContactIfUnchanged(ctx, userID, contactID, version)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) MergeContacts(ctx context.Context, userID, primaryID uint, duplicateIDs []uint) (*models.Contact, error) {
+	ctx, span := tracing.Start(ctx, "service.MergeContacts")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.MergeContacts(ctx, userID, primaryID, duplicateIDs)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) CreateContactsBatch(ctx context.Context, userID uint, reqs []models.CreateContactRequest) ([]models.BatchContactResult, error) {
+	ctx, span := tracing.Start(ctx, "service.CreateContactsBatch")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.CreateContactsBatch(ctx, userID, reqs)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) TagContactsByQuery(ctx context.Context, userID uint, req *models.TagByQueryRequest) (int64, error) {
+	ctx, span := tracing.Start(ctx, "service.TagContactsByQuery")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.TagContactsByQuery(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) AdminListContacts(ctx context.Context, req *models.AdminListContactsRequest) ([]models.AdminContact, int64, error) {
+	ctx, span := tracing.Start(ctx, "service.AdminListContacts")
+	defer span.End()
+	result, total, err := s.next.AdminListContacts(ctx, req)
+	span.RecordError(err)
+	return result, total, err
+}
+
+func (s *tracedService) ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error) {
+	ctx, span := tracing.Start(ctx, "service.ListGroups")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.ListGroups(ctx, userID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) CreateGroup(ctx context.Context, userID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	ctx, span := tracing.Start(ctx, "service.CreateGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.CreateGroup(ctx, userID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error) {
+	ctx, span := tracing.Start(ctx, "service.GetGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetGroup(ctx, userID, groupID)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) RenameGroup(ctx context.Context, userID, groupID uint, req models.ContactGroupRequest) (*models.ContactGroup, error) {
+	ctx, span := tracing.Start(ctx, "service.RenameGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.RenameGroup(ctx, userID, groupID, req)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) DeleteGroup(ctx context.Context, userID, groupID uint) error {
+	ctx, span := tracing.Start(ctx, "service.DeleteGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.DeleteGroup(ctx, userID, groupID)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	ctx, span := tracing.Start(ctx, "service.AddContactToGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.AddContactToGroup(ctx, userID, groupID, contactID)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	ctx, span := tracing.Start(ctx, "service.RemoveContactFromGroup")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	err := s.next.RemoveContactFromGroup(ctx, userID, groupID, contactID)
+	span.RecordError(err)
+	return err
+}
+
+func (s *tracedService) StartContactImport(ctx context.Context, userID uint, data []byte, dedup string) (*models.ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "service.StartContactImport")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.StartContactImport(ctx, userID, data, dedup)
+	span.RecordError(err)
+	return result, err
+}
+
+func (s *tracedService) GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "service.GetImportJob")
+	defer span.End()
+	span.SetAttribute("user.id", userID)
+	result, err := s.next.GetImportJob(ctx, userID, jobID)
+	span.RecordError(err)
+	return result, err
+}
@@ -3,14 +3,19 @@ package app
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
 	"user-service/internal/app/service"
+	"user-service/internal/app/token"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Test errors (matching service package errors)
@@ -18,6 +23,19 @@ var (
 	ErrEmailTaken      = errors.New("email is already taken")
 	ErrContactNotFound = errors.New("contact not found")
 	ErrPhoneExists     = errors.New("phone number already exists for this user")
+
+	ErrInvalidDuplicateBy = errors.New("duplicates can only be grouped by name or phone")
+	ErrSearchRequired     = errors.New("search query is required")
+	ErrRegistrationClosed = errors.New("registration disabled")
+	ErrInviteCodeInvalid  = errors.New("invite code is invalid")
+	ErrPhoneTaken         = errors.New("phone number is already registered")
+	ErrIncorrectPassword  = errors.New("password is incorrect")
+
+	ErrContactQuotaExceeded = errors.New("contact quota exceeded")
+	ErrInvalidEmail         = errors.New("email must be a valid email address")
+	ErrFullNameRequired     = errors.New("full_name is required")
+	ErrTagFilterRequired    = errors.New("a filter or confirm=true is required to tag every contact")
+	ErrContactChanged       = errors.New("contact was modified since it was loaded")
 )
 
 // MockRepository is a mock implementation of the Repository interface
@@ -57,8 +75,92 @@ func (m *MockRepository) UpdateUser(ctx context.Context, userID uint, updates ma
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockRepository) ListContacts(ctx context.Context, userID uint, query string, offset, limit int) ([]models.Contact, int64, error) {
-	args := m.Called(ctx, userID, query, offset, limit)
+func (m *MockRepository) UpdateLastLogin(ctx context.Context, userID uint, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RecordFailedLogin(ctx context.Context, userID uint, maxAttempts int, lockUntil time.Time) (bool, error) {
+	args := m.Called(ctx, userID, maxAttempts, lockUntil)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteUser(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListInactiveUsers(ctx context.Context, cutoff time.Time) ([]models.User, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockRepository) MarkInactivityWarned(ctx context.Context, userID uint, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FlagUserInactive(ctx context.Context, userID uint, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateInviteCode(ctx context.Context, code *models.InviteCode) (*models.InviteCode, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InviteCode), args.Error(1)
+}
+
+func (m *MockRepository) GetInviteCode(ctx context.Context, code string) (*models.InviteCode, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InviteCode), args.Error(1)
+}
+
+func (m *MockRepository) ListInviteCodes(ctx context.Context) ([]models.InviteCode, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.InviteCode), args.Error(1)
+}
+
+func (m *MockRepository) ConsumeInviteCode(ctx context.Context, code string, now time.Time) (bool, error) {
+	args := m.Called(ctx, code, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeToken(ctx context.Context, revoked *models.RevokedToken, now time.Time) error {
+	args := m.Called(ctx, revoked, now)
+	return args.Error(0)
+}
+
+func (m *MockRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreatePasswordResetToken(ctx context.Context, reset *models.PasswordResetToken) error {
+	args := m.Called(ctx, reset)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPasswordResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (bool, error) {
+	args := m.Called(ctx, tokenHash, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) ListContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int) ([]models.Contact, int64, error) {
+	args := m.Called(ctx, userID, filter, offset, limit)
 	return args.Get(0).([]models.Contact), args.Get(1).(int64), args.Error(2)
 }
 
@@ -78,11 +180,40 @@ func (m *MockRepository) GetContact(ctx context.Context, userID, contactID uint)
 	return args.Get(0).(*models.Contact), args.Error(1)
 }
 
-func (m *MockRepository) CheckContactExists(ctx context.Context, userID uint, phone string) (bool, error) {
-	args := m.Called(ctx, userID, phone)
+func (m *MockRepository) LookupDirectory(ctx context.Context, phones []string) ([]models.DirectoryEntry, error) {
+	args := m.Called(ctx, phones)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DirectoryEntry), args.Error(1)
+}
+
+func (m *MockRepository) CheckUserPhoneExists(ctx context.Context, phone string, excludeUserID uint) (bool, error) {
+	args := m.Called(ctx, phone, excludeUserID)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockRepository) CheckContactExists(ctx context.Context, userID uint, phone string, excludeContactID uint) (bool, error) {
+	args := m.Called(ctx, userID, phone, excludeContactID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetContactByPhone(ctx context.Context, userID uint, phone string) (*models.Contact, error) {
+	args := m.Called(ctx, userID, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contact), args.Error(1)
+}
+
+func (m *MockRepository) ListDuplicateGroups(ctx context.Context, userID uint, field string, offset, limit int) ([]models.DuplicateGroup, int64, error) {
+	args := m.Called(ctx, userID, field, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.DuplicateGroup), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockRepository) UpdateContact(ctx context.Context, userID, contactID uint, updates map[string]interface{}) (*models.Contact, error) {
 	args := m.Called(ctx, userID, contactID, updates)
 	if args.Get(0) == nil {
@@ -91,274 +222,1256 @@ func (m *MockRepository) UpdateContact(ctx context.Context, userID, contactID ui
 	return args.Get(0).(*models.Contact), args.Error(1)
 }
 
-func (m *MockRepository) DeleteContact(ctx context.Context, userID, contactID uint) error {
-	args := m.Called(ctx, userID, contactID)
-	return args.Error(0)
+func (m *MockRepository) RestoreContact(ctx context.Context, userID, contactID uint) error {
+	args := m.Called(ctx, userID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteContact(ctx context.Context, userID, contactID uint) error {
+	args := m.Called(ctx, userID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateContacts(ctx context.Context, contacts []*models.Contact) error {
+	args := m.Called(ctx, contacts)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteContactIfUnchanged(ctx context.Context, userID, contactID uint, version uint) error {
+	args := m.Called(ctx, userID, contactID, version)
+	return args.Error(0)
+}
+
+// WithTx runs fn on the mock itself, which does not model transactions
+func (m *MockRepository) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	return fn(m)
+}
+
+// LockUser has no effect on the mock
+func (m *MockRepository) LockUser(ctx context.Context, userID uint) error {
+	return nil
+}
+
+func (m *MockRepository) MergeContacts(ctx context.Context, primary *models.Contact, duplicateIDs []uint) error {
+	args := m.Called(ctx, primary, duplicateIDs)
+	return args.Error(0)
+}
+
+func (m *MockRepository) StreamContacts(ctx context.Context, userID uint, filter models.ContactFilter, offset, limit int, fn func(*models.Contact) error) error {
+	args := m.Called(ctx, userID, filter, offset, limit, fn)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ContactsVersion(ctx context.Context, userID uint) (models.ContactsVersion, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.ContactsVersion), args.Error(1)
+}
+
+func (m *MockRepository) TagContacts(ctx context.Context, userID uint, filter models.ContactFilter, tag string) ([]uint, error) {
+	args := m.Called(ctx, userID, filter, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockRepository) CountContacts(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) BackfillSearchText(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) BackfillPhoneHashes(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) AdminListContacts(ctx context.Context, userID uint, includeDeleted bool, offset, limit int) ([]models.Contact, int64, error) {
+	args := m.Called(ctx, userID, includeDeleted, offset, limit)
+	return args.Get(0).([]models.Contact), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository) CreateImportJob(ctx context.Context, job *models.ImportJob) (*models.ImportJob, error) {
+	args := m.Called(ctx, job)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *MockRepository) CreateGroup(ctx context.Context, group *models.ContactGroup) (*models.ContactGroup, error) {
+	args := m.Called(ctx, group)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockRepository) ListGroups(ctx context.Context, userID uint) ([]models.ContactGroup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ContactGroup), args.Error(1)
+}
+
+func (m *MockRepository) GetGroup(ctx context.Context, userID, groupID uint) (*models.ContactGroup, error) {
+	args := m.Called(ctx, userID, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockRepository) RenameGroup(ctx context.Context, userID, groupID uint, name string) (*models.ContactGroup, error) {
+	args := m.Called(ctx, userID, groupID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContactGroup), args.Error(1)
+}
+
+func (m *MockRepository) DeleteGroup(ctx context.Context, userID, groupID uint) ([]uint, error) {
+	args := m.Called(ctx, userID, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockRepository) AddContactToGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	args := m.Called(ctx, userID, groupID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RemoveContactFromGroup(ctx context.Context, userID, groupID, contactID uint) error {
+	args := m.Called(ctx, userID, groupID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetImportJob(ctx context.Context, userID, jobID uint) (*models.ImportJob, error) {
+	args := m.Called(ctx, userID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *MockRepository) UpdateImportJob(ctx context.Context, jobID uint, updates map[string]interface{}) error {
+	args := m.Called(ctx, jobID, updates)
+	return args.Error(0)
+}
+
+// conflictingContact extracts the existing contact from a phone conflict error
+func conflictingContact(err error) *models.Contact {
+	var conflict *service.PhoneConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Contact
+	}
+	return nil
+}
+
+func TestService_Register(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("successful registration", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "John Doe",
+			Email:    "john@example.com",
+			Phone:    stringPtr("1234567890"),
+			Password: "password123",
+		}
+
+		expectedUser := &models.User{
+			ID:       1,
+			FullName: req.FullName,
+			Email:    req.Email,
+			Phone:    req.Phone,
+		}
+
+		// Mock repository calls
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, nil).Once()
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).Return(expectedUser, nil).Once()
+
+		user, accessToken, err := service.Register(ctx, req)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, accessToken)
+		assert.Equal(t, expectedUser.ID, user.ID)
+		assert.Equal(t, expectedUser.FullName, user.FullName)
+		assert.Equal(t, expectedUser.Email, user.Email)
+		assert.Equal(t, expectedUser.Phone, user.Phone)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("email already taken", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Jane Doe",
+			Email:    "existing@example.com",
+			Phone:    stringPtr("1234567890"),
+			Password: "password123",
+		}
+
+		existingUser := &models.User{
+			ID:    1,
+			Email: req.Email,
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(existingUser, nil).Once()
+
+		user, accessToken, err := service.Register(ctx, req)
+
+		assert.Error(t, err)
+		assert.Empty(t, accessToken)
+		assert.Equal(t, ErrEmailTaken, err)
+		assert.Nil(t, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("email taken by a concurrent registration", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Jane Doe",
+			Email:    "racing@example.com",
+			Password: "password123",
+		}
+
+		// The other registration commits between the check and the insert
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).
+			Return(nil, errors.New("Error 1062 (23000): Duplicate entry 'racing@example.com' for key 'users.email'")).Once()
+
+		user, accessToken, err := service.Register(ctx, req)
+
+		assert.Equal(t, ErrEmailTaken, err)
+		assert.Nil(t, user)
+		assert.Empty(t, accessToken)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("email taken under the index added by migration", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Jane Doe",
+			Email:    "racing-again@example.com",
+			Password: "password123",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).
+			Return(nil, errors.New("Error 1062 (23000): Duplicate entry 'racing-again@example.com' for key 'users.idx_users_email_unique'")).Once()
+
+		_, _, err := service.Register(ctx, req)
+
+		assert.Equal(t, ErrEmailTaken, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_Register_UniquePhone(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"),
+		service.WithUniqueUserPhone(true),
+	)
+	ctx := context.Background()
+
+	t.Run("phone already registered", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Second Owner",
+			Email:    "second@example.com",
+			Phone:    stringPtr("1234567890"),
+			Password: "password123",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CheckUserPhoneExists", ctx, "1234567890", uint(0)).Return(true, nil).Once()
+
+		user, accessToken, err := service.Register(ctx, req)
+
+		assert.Equal(t, ErrPhoneTaken, err)
+		assert.Nil(t, user)
+		assert.Empty(t, accessToken)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("constraint violation on insert", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Racing Owner",
+			Email:    "racing@example.com",
+			Phone:    stringPtr("5550001111"),
+			Password: "password123",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CheckUserPhoneExists", ctx, "5550001111", uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).
+			Return(nil, errors.New("Error 1062 (23000): Duplicate entry '5550001111' for key 'users.idx_users_phone_unique'")).Once()
+
+		user, _, err := service.Register(ctx, req)
+
+		assert.Equal(t, ErrPhoneTaken, err)
+		assert.Nil(t, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("registration without phone skips the check", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "No Phone",
+			Email:    "nophone@example.com",
+			Phone:    stringPtr(""),
+			Password: "password123",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CreateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.Phone == nil
+		})).Return(&models.User{ID: 3, Email: req.Email}, nil).Once()
+
+		_, _, err := service.Register(ctx, req)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_Register_RegistrationDisabled(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewService(mockRepo, token.NewService("test_secret"),
+		service.WithRegistration(false, []string{"BETA-2025"}),
+	)
+	ctx := context.Background()
+
+	t.Run("rejected without invite code", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName: "Closed Door",
+			Email:    "closed@example.com",
+			Password: "password123",
+		}
+
+		user, accessToken, err := svc.Register(ctx, req)
+
+		assert.Equal(t, ErrRegistrationClosed, err)
+		assert.Nil(t, user)
+		assert.Empty(t, accessToken)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected with unknown invite code", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName:   "Wrong Code",
+			Email:      "wrong@example.com",
+			Password:   "password123",
+			InviteCode: "NOPE",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, nil).Once()
+		mockRepo.On("ConsumeInviteCode", ctx, "NOPE", mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+		mockRepo.On("GetInviteCode", ctx, "NOPE").Return(nil, errors.New("record not found")).Once()
+
+		_, _, err := svc.Register(ctx, req)
+
+		assert.Equal(t, ErrInviteCodeInvalid, err)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("accepted with valid invite code", func(t *testing.T) {
+		req := models.RegisterRequest{
+			FullName:   "Invited User",
+			Email:      "invited@example.com",
+			Password:   "password123",
+			InviteCode: "BETA-2025",
+		}
+
+		createdUser := &models.User{ID: 7, FullName: req.FullName, Email: req.Email, Role: models.RoleUser}
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, nil).Once()
+		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).Return(createdUser, nil).Once()
+
+		user, accessToken, err := svc.Register(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, createdUser.ID, user.ID)
+		assert.NotEmpty(t, accessToken)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_Login(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("successful login", func(t *testing.T) {
+		req := models.LoginRequest{
+			Email:    "john@example.com",
+			Password: "password123",
+		}
+
+		// Create a proper bcrypt hash of the password
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		user := &models.User{
+			ID:       1,
+			FullName: "John Doe",
+			Email:    req.Email,
+			Phone:    stringPtr("1234567890"),
+			Password: string(hashedPassword),
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(user, nil).Once()
+		mockRepo.On("UpdateLastLogin", ctx, user.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		result, err := service.Login(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, result["id"])
+		assert.Equal(t, user.FullName, result["full_name"])
+		assert.Equal(t, user.Email, result["email"])
+		assert.Equal(t, user.Phone, result["phone"])
+		assert.Contains(t, result, "token")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		req := models.LoginRequest{
+			Email:    "nonexistent@example.com",
+			Password: "password123",
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, errors.New("user not found")).Once()
+
+		result, err := service.Login(ctx, req)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		req := models.LoginRequest{
+			Email:    "john@example.com",
+			Password: "wrongpassword",
+		}
+
+		hashedPassword := "$2a$10$hashedpassword"
+		user := &models.User{
+			ID:       1,
+			Email:    req.Email,
+			Password: hashedPassword,
+		}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(user, nil).Once()
+
+		result, err := service.Login(ctx, req)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_GetUserProfile(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("successful profile retrieval", func(t *testing.T) {
+		userID := uint(1)
+		expectedUser := &models.User{
+			ID:       userID,
+			FullName: "John Doe",
+			Email:    "john@example.com",
+			Phone:    stringPtr("1234567890"),
+		}
+
+		mockRepo.On("GetUserByID", ctx, userID).Return(expectedUser, nil).Once()
+
+		user, err := service.GetUserProfile(ctx, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		userID := uint(999)
+
+		mockRepo.On("GetUserByID", ctx, userID).Return(nil, errors.New("user not found")).Once()
+
+		user, err := service.GetUserProfile(ctx, userID)
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_VerifyPassword(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := &models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}
+
+	t.Run("correct password", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil).Once()
+
+		assert.NoError(t, service.VerifyPassword(ctx, 1, "password123"))
+		mockRepo.AssertNotCalled(t, "UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("incorrect password", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil).Once()
+
+		err := service.VerifyPassword(ctx, 1, "wrongpassword")
+
+		assert.Equal(t, ErrIncorrectPassword, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := &models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}
+
+	t.Run("stores the new password hashed", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil).Once()
+		mockRepo.On("UpdateUser", ctx, uint(1), mock.MatchedBy(func(updates map[string]interface{}) bool {
+			hash, ok := updates["password"].(string)
+			return ok && len(updates) == 1 &&
+				bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword456")) == nil
+		})).Return(user, nil).Once()
+
+		assert.NoError(t, service.ChangePassword(ctx, 1, "password123", "newpassword456"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("incorrect current password", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil).Once()
+
+		err := service.ChangePassword(ctx, 1, "wrongpassword", "newpassword456")
+
+		// UpdateUser has no expectation left, so storing a password would fail the test
+		assert.Equal(t, ErrIncorrectPassword, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_GetUserProfile_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(1)
+	expectedUser := &models.User{ID: userID, FullName: "John Doe", Email: "john@example.com"}
+
+	t.Run("concurrent reads share one repository call", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		release := make(chan struct{})
+		mockRepo.On("GetUserByID", ctx, userID).
+			Run(func(mock.Arguments) { <-release }).
+			Return(expectedUser, nil).Once()
+
+		const readers = 20
+		var wg sync.WaitGroup
+		users := make([]*models.User, readers)
+		errs := make([]error, readers)
+		for i := 0; i < readers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				users[i], errs[i] = service.GetUserProfile(ctx, userID)
+			}(i)
+		}
+
+		// Give every reader time to join the in-flight lookup before it completes
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for i := 0; i < readers; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, expectedUser, users[i])
+		}
+		mockRepo.AssertNumberOfCalls(t, "GetUserByID", 1)
+	})
+
+	t.Run("cached profile is invalidated by an update", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithProfileCache(10, time.Minute))
+
+		updatedUser := &models.User{ID: userID, FullName: "Jane Doe", Email: "john@example.com"}
+		mockRepo.On("GetUserByID", ctx, userID).Return(expectedUser, nil).Once()
+		mockRepo.On("UpdateUser", ctx, userID, map[string]interface{}{"full_name": "Jane Doe"}).Return(updatedUser, nil).Once()
+		mockRepo.On("GetUserByID", ctx, userID).Return(updatedUser, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			user, err := service.GetUserProfile(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, "John Doe", user.FullName)
+		}
+		mockRepo.AssertNumberOfCalls(t, "GetUserByID", 1)
+
+		_, err := service.UpdateProfile(ctx, userID, models.UpdateProfileRequest{FullName: "Jane Doe"})
+		require.NoError(t, err)
+
+		user, err := service.GetUserProfile(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", user.FullName)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_UpdateProfile(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("successful profile update", func(t *testing.T) {
+		userID := uint(1)
+		req := models.UpdateProfileRequest{
+			FullName: "Updated Name",
+			Phone:    stringPtr("9876543210"),
+		}
+
+		expectedUser := &models.User{
+			ID:       userID,
+			FullName: req.FullName,
+			Email:    "john@example.com",
+			Phone:    req.Phone,
+		}
+
+		mockRepo.On("UpdateUser", ctx, userID, mock.AnythingOfType("map[string]interface {}")).Return(expectedUser, nil).Once()
+
+		user, err := service.UpdateProfile(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_PatchProfile(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+	userID := uint(1)
+
+	t.Run("omitting full_name leaves it unchanged", func(t *testing.T) {
+		expectedUser := &models.User{ID: userID, FullName: "John Doe", Phone: stringPtr("14155552671")}
+		mockRepo.On("UpdateUser", ctx, userID, map[string]interface{}{"phone": "14155552671"}).Return(expectedUser, nil).Once()
+
+		user, err := service.PatchProfile(ctx, userID, models.PatchProfileRequest{Phone: stringPtr("+1 415 555 2671")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", user.FullName)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("omitting phone leaves it unchanged", func(t *testing.T) {
+		mockRepo.On("UpdateUser", ctx, userID, map[string]interface{}{"full_name": "Jane Doe"}).
+			Return(&models.User{ID: userID, FullName: "Jane Doe"}, nil).Once()
+
+		_, err := service.PatchProfile(ctx, userID, models.PatchProfileRequest{FullName: stringPtr(" Jane Doe ")})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("a sent name must not be blank", func(t *testing.T) {
+		_, err := service.PatchProfile(ctx, userID, models.PatchProfileRequest{FullName: stringPtr("  ")})
+
+		assert.Equal(t, ErrFullNameRequired, err)
+		mockRepo.AssertNumberOfCalls(t, "UpdateUser", 2)
+	})
+}
+
+func TestService_ListContacts(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("successful contact listing", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.ListContactsRequest{
+			Page:  1,
+			Limit: 10,
+			Query: "test",
+		}
+
+		expectedContacts := []models.Contact{
+			{ID: 1, FullName: "Test Contact", Phone: "1234567890"},
+		}
+		expectedTotal := int64(1)
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{Query: req.Query}, 0, req.Limit).Return(expectedContacts, expectedTotal, nil).Once()
+
+		contacts, total, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedContacts, contacts)
+		assert.Equal(t, expectedTotal, total)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_ListContacts_CountCap(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(1)
+
+	t.Run("count beyond the cap is capped", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithContactCountCap(100))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{MaxCount: 100}, 0, 10).Return([]models.Contact{}, int64(101), nil).Once()
+
+		req := &models.ListContactsRequest{Page: 1, Limit: 10}
+		_, total, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), total)
+		assert.True(t, req.CountCapped)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("count within the cap is exact", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithContactCountCap(100))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{MaxCount: 100}, 0, 10).Return([]models.Contact{}, int64(100), nil).Once()
+
+		req := &models.ListContactsRequest{Page: 1, Limit: 10}
+		_, total, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), total)
+		assert.False(t, req.CountCapped)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_ListContacts_WithTotal(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(1)
+
+	t.Run("filtered list counts all contacts separately", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{Query: "john"}, 0, 10).Return([]models.Contact{}, int64(2), nil).Once()
+		mockRepo.On("CountContacts", ctx, userID).Return(int64(5), nil).Once()
+
+		req := &models.ListContactsRequest{Query: "john", WithTotal: true, Page: 1, Limit: 10}
+		_, total, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, int64(5), req.TotalAll)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unfiltered list reuses its own count", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{}, 0, 10).Return([]models.Contact{}, int64(5), nil).Once()
+
+		req := &models.ListContactsRequest{WithTotal: true, Page: 1, Limit: 10}
+		_, _, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), req.TotalAll)
+		mockRepo.AssertNotCalled(t, "CountContacts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("total is not counted unless requested", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{Query: "john"}, 0, 10).Return([]models.Contact{}, int64(2), nil).Once()
+
+		req := &models.ListContactsRequest{Query: "john", Page: 1, Limit: 10}
+		_, _, err := service.ListContacts(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Zero(t, req.TotalAll)
+		mockRepo.AssertNotCalled(t, "CountContacts", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_ListContacts_BlankQuery(t *testing.T) {
+	ctx := context.Background()
+	userID := uint(1)
+
+	t.Run("optional mode lists all contacts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithSearchMode("optional"))
+
+		expectedContacts := []models.Contact{{ID: 1, FullName: "Alice"}}
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{}, 0, 10).Return(expectedContacts, int64(1), nil).Once()
+
+		contacts, total, err := service.ListContacts(ctx, userID, &models.ListContactsRequest{Page: 1, Limit: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedContacts, contacts)
+		assert.Equal(t, int64(1), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty mode returns no contacts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithSearchMode("empty"))
+
+		contacts, total, err := service.ListContacts(ctx, userID, &models.ListContactsRequest{Query: "  ", Page: 1, Limit: 10})
+
+		require.NoError(t, err)
+		assert.Empty(t, contacts)
+		assert.NotNil(t, contacts)
+		assert.Zero(t, total)
+		mockRepo.AssertNotCalled(t, "ListContacts", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("required mode rejects blank query", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithSearchMode("required"))
+
+		contacts, _, err := service.ListContacts(ctx, userID, &models.ListContactsRequest{Page: 1, Limit: 10})
+
+		assert.Equal(t, ErrSearchRequired, err)
+		assert.Nil(t, contacts)
+		mockRepo.AssertNotCalled(t, "ListContacts", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("required mode still searches non-blank query", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithSearchMode("required"))
+
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{Query: "ali"}, 0, 10).Return([]models.Contact{}, int64(0), nil).Once()
+
+		_, _, err := service.ListContacts(ctx, userID, &models.ListContactsRequest{Query: "ali", Page: 1, Limit: 10})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_ListDuplicates(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("passes group offset and limit", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.ListDuplicatesRequest{By: "name", Page: 3, Limit: 2}
+
+		expectedGroups := []models.DuplicateGroup{{Key: "alice", Count: 2}}
+		mockRepo.On("ListDuplicateGroups", ctx, userID, "name", 4, 2).Return(expectedGroups, int64(5), nil).Once()
+
+		groups, total, err := service.ListDuplicates(ctx, userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedGroups, groups)
+		assert.Equal(t, int64(5), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown grouping field", func(t *testing.T) {
+		req := &models.ListDuplicatesRequest{By: "email", Page: 1, Limit: 10}
+
+		groups, _, err := service.ListDuplicates(ctx, 1, req)
+
+		assert.Equal(t, ErrInvalidDuplicateBy, err)
+		assert.Nil(t, groups)
+	})
+}
+
+func TestService_AdminListContacts(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+
+	t.Run("marks soft-deleted contacts", func(t *testing.T) {
+		req := &models.AdminListContactsRequest{UserID: 2, IncludeDeleted: true, Page: 1, Limit: 10}
+		deletedAt := time.Now()
+		contacts := []models.Contact{
+			{ID: 1, UserID: 2, FullName: "Live"},
+			{ID: 2, UserID: 2, FullName: "Gone", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+		}
+		mockRepo.On("AdminListContacts", ctx, uint(2), true, 0, 10).Return(contacts, int64(2), nil).Once()
+
+		result, total, err := service.AdminListContacts(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, result, 2)
+		assert.Equal(t, uint(2), result[0].UserID)
+		assert.False(t, result[0].Deleted)
+		assert.Nil(t, result[0].DeletedAt)
+		assert.True(t, result[1].Deleted)
+		require.NotNil(t, result[1].DeletedAt)
+		assert.True(t, deletedAt.Equal(*result[1].DeletedAt))
+		mockRepo.AssertExpectations(t)
+	})
 }
 
-func TestService_Register(t *testing.T) {
+func TestService_CreateContact(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
 	ctx := context.Background()
 
-	t.Run("successful registration", func(t *testing.T) {
-		req := models.RegisterRequest{
-			FullName: "John Doe",
-			Email:    "john@example.com",
-			Phone:    "+1234567890",
-			Password: "password123",
+	t.Run("successful contact creation", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.CreateContactRequest{
+			FullName: "New Contact",
+			Phone:    "1234567890",
 		}
 
-		expectedUser := &models.User{
+		expectedContact := &models.Contact{
 			ID:       1,
+			UserID:   userID,
 			FullName: req.FullName,
-			Email:    req.Email,
 			Phone:    req.Phone,
 		}
 
-		// Mock repository calls
-		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, nil).Once()
-		mockRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).Return(expectedUser, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateContact", ctx, mock.AnythingOfType("*models.Contact")).Return(expectedContact, nil).Once()
 
-		user, err := service.Register(ctx, req)
+		contact, err := service.CreateContact(ctx, userID, req)
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedUser.ID, user.ID)
-		assert.Equal(t, expectedUser.FullName, user.FullName)
-		assert.Equal(t, expectedUser.Email, user.Email)
-		assert.Equal(t, expectedUser.Phone, user.Phone)
+		assert.Equal(t, expectedContact, contact)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("email already taken", func(t *testing.T) {
-		req := models.RegisterRequest{
-			FullName: "Jane Doe",
-			Email:    "existing@example.com",
-			Phone:    "+1234567890",
-			Password: "password123",
-		}
-
-		existingUser := &models.User{
-			ID:    1,
-			Email: req.Email,
+	t.Run("phone number already exists", func(t *testing.T) {
+		userID := uint(1)
+		req := &models.CreateContactRequest{
+			FullName: "New Contact",
+			Phone:    "1234567890",
 		}
 
-		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(existingUser, nil).Once()
+		conflicting := &models.Contact{ID: 42, UserID: userID, FullName: "Existing Contact", Phone: req.Phone}
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(true, nil).Once()
+		mockRepo.On("GetContactByPhone", ctx, userID, req.Phone).Return(conflicting, nil).Once()
 
-		user, err := service.Register(ctx, req)
+		contact, err := service.CreateContact(ctx, userID, req)
 
 		assert.Error(t, err)
-		assert.Equal(t, ErrEmailTaken, err)
-		assert.Nil(t, user)
+		assert.EqualError(t, err, ErrPhoneExists.Error())
+		assert.Equal(t, conflicting, conflictingContact(err))
+		assert.Nil(t, contact)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestService_Login(t *testing.T) {
-	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+func TestService_ContactNameNormalization(t *testing.T) {
 	ctx := context.Background()
+	userID := uint(1)
 
-	t.Run("successful login", func(t *testing.T) {
-		req := models.LoginRequest{
-			Email:    "john@example.com",
-			Password: "password123",
-		}
+	t.Run("create trims and collapses whitespace", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+		req := &models.CreateContactRequest{FullName: "  jOHN \t  van   DOE \n", Phone: "1234567890"}
 
-		// Create a proper bcrypt hash of the password
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-		user := &models.User{
-			ID:       1,
-			FullName: "John Doe",
-			Email:    req.Email,
-			Phone:    "+1234567890",
-			Password: string(hashedPassword),
-		}
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(c *models.Contact) bool {
+			return c.FullName == "jOHN van DOE"
+		})).Return(&models.Contact{ID: 1, FullName: "jOHN van DOE"}, nil).Once()
 
-		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(user, nil).Once()
+		_, err := service.CreateContact(ctx, userID, req)
 
-		result, err := service.Login(ctx, req)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create applies title casing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithNameCasing(service.NameCaseTitle))
+		req := &models.CreateContactRequest{FullName: "  jOHN   mary-JANE  doe ", Phone: "1234567890"}
+
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(c *models.Contact) bool {
+			return c.FullName == "John Mary-Jane Doe"
+		})).Return(&models.Contact{ID: 1, FullName: "John Mary-Jane Doe"}, nil).Once()
+
+		_, err := service.CreateContact(ctx, userID, req)
 
 		require.NoError(t, err)
-		assert.Equal(t, user.ID, result["id"])
-		assert.Equal(t, user.FullName, result["full_name"])
-		assert.Equal(t, user.Email, result["email"])
-		assert.Equal(t, user.Phone, result["phone"])
-		assert.Contains(t, result, "token")
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("invalid email", func(t *testing.T) {
-		req := models.LoginRequest{
-			Email:    "nonexistent@example.com",
-			Password: "password123",
-		}
+	t.Run("update stores the normalized name", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithNameCasing(service.NameCaseTitle))
+		existing := &models.Contact{ID: 1, UserID: userID, FullName: "Old", Phone: "1234567890"}
+		req := &models.UpdateContactRequest{FullName: " alice\u00a0  SMITH", Phone: existing.Phone}
 
-		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, errors.New("user not found")).Once()
+		mockRepo.On("GetContact", ctx, userID, uint(1)).Return(existing, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, existing.Phone, uint(1)).Return(false, nil).Once()
+		mockRepo.On("UpdateContact", ctx, userID, uint(1), mock.MatchedBy(func(updates map[string]interface{}) bool {
+			return updates["full_name"] == "Alice Smith"
+		})).Return(&models.Contact{ID: 1, FullName: "Alice Smith"}, nil).Once()
 
-		result, err := service.Login(ctx, req)
+		_, err := service.UpdateContact(ctx, userID, 1, req)
 
-		assert.Error(t, err)
-		assert.Nil(t, result)
+		require.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("invalid password", func(t *testing.T) {
-		req := models.LoginRequest{
-			Email:    "john@example.com",
-			Password: "wrongpassword",
-		}
+	t.Run("whitespace-only name is rejected", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
 
-		hashedPassword := "$2a$10$hashedpassword"
-		user := &models.User{
-			ID:       1,
-			Email:    req.Email,
-			Password: hashedPassword,
-		}
+		contact, err := service.CreateContact(ctx, userID, &models.CreateContactRequest{FullName: " \t ", Phone: "1234567890"})
 
-		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(user, nil).Once()
+		assert.EqualError(t, err, ErrFullNameRequired.Error())
+		assert.Nil(t, contact)
+		mockRepo.AssertNotCalled(t, "CreateContact", mock.Anything, mock.Anything)
+	})
 
-		result, err := service.Login(ctx, req)
+	t.Run("search query is normalized the same way", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
 
-		assert.Error(t, err)
-		assert.Nil(t, result)
+		mockRepo.On("ListContacts", ctx, userID, models.ContactFilter{Query: "john doe"}, 0, 10).Return([]models.Contact{}, int64(0), nil).Once()
+
+		_, _, err := service.ListContacts(ctx, userID, &models.ListContactsRequest{Query: "  john    doe ", Page: 1, Limit: 10})
+
+		require.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestService_GetUserProfile(t *testing.T) {
-	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+func TestService_PhoneNormalization(t *testing.T) {
 	ctx := context.Background()
+	userID := uint(1)
 
-	t.Run("successful profile retrieval", func(t *testing.T) {
-		userID := uint(1)
-		expectedUser := &models.User{
-			ID:       userID,
-			FullName: "John Doe",
-			Email:    "john@example.com",
-			Phone:    "+1234567890",
-		}
+	t.Run("create stores the canonical number", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+		req := &models.CreateContactRequest{FullName: "Caller", Phone: "+1 (415) 555-2671"}
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(expectedUser, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, "14155552671", uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateContact", ctx, mock.MatchedBy(func(c *models.Contact) bool {
+			return c.Phone == "14155552671"
+		})).Return(&models.Contact{ID: 1, Phone: "14155552671"}, nil).Once()
 
-		user, err := service.GetUserProfile(ctx, userID)
+		_, err := service.CreateContact(ctx, userID, req)
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedUser, user)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("user not found", func(t *testing.T) {
-		userID := uint(999)
+	t.Run("update compares and stores the canonical number", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+		existing := &models.Contact{ID: 1, UserID: userID, FullName: "Caller", Phone: "14155552671"}
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(nil, errors.New("user not found")).Once()
+		// The same number formatted differently belongs to the contact itself
+		mockRepo.On("GetContact", ctx, userID, uint(1)).Return(existing, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, "14155552671", uint(1)).Return(false, nil).Once()
+		mockRepo.On("UpdateContact", ctx, userID, uint(1), mock.MatchedBy(func(updates map[string]interface{}) bool {
+			return updates["phone"] == "14155552671"
+		})).Return(existing, nil).Once()
 
-		user, err := service.GetUserProfile(ctx, userID)
+		_, err := service.UpdateContact(ctx, userID, 1, &models.UpdateContactRequest{FullName: "Caller", Phone: "+1 415-555-2671"})
 
-		assert.Error(t, err)
-		assert.Nil(t, user)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid numbers are rejected before any lookup", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		_, err := svc.CreateContact(ctx, userID, &models.CreateContactRequest{FullName: "Caller", Phone: "call me"})
+		assert.Equal(t, service.ErrInvalidPhone, err)
+		_, err = svc.UpdateContact(ctx, userID, 1, &models.UpdateContactRequest{FullName: "Caller", Phone: "0812-3456-7890"})
+		assert.Equal(t, service.ErrInvalidPhone, err)
+		_, err = svc.UpdateProfile(ctx, userID, models.UpdateProfileRequest{Phone: stringPtr("+1 415 #555")})
+		assert.Equal(t, service.ErrInvalidPhone, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("register stores the canonical number", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+		req := models.RegisterRequest{FullName: "Jane", Email: "jane@example.com", Phone: stringPtr("+44 20 7946 0958"), Password: "password123"}
+
+		mockRepo.On("GetUserByEmail", ctx, req.Email).Return(nil, nil).Once()
+		mockRepo.On("CreateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.Phone != nil && *u.Phone == "442079460958"
+		})).Return(&models.User{ID: 1, Email: req.Email}, nil).Once()
+
+		_, _, err := service.Register(ctx, req)
+
+		require.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestService_UpdateProfile(t *testing.T) {
-	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+func TestService_CreateContactsBatch(t *testing.T) {
 	ctx := context.Background()
+	userID := uint(1)
 
-	t.Run("successful profile update", func(t *testing.T) {
-		userID := uint(1)
-		req := models.UpdateProfileRequest{
-			FullName: "Updated Name",
-			Phone:    "+0987654321",
+	mixedBatch := func() []models.CreateContactRequest {
+		return []models.CreateContactRequest{
+			{FullName: "Alice", Phone: "1111111111"},
+			{FullName: "", Phone: "2222222222"},
+			{FullName: "Carol", Phone: "3333333333", Email: stringPtr("carol@example.com")},
 		}
+	}
 
-		expectedUser := &models.User{
-			ID:       userID,
-			FullName: req.FullName,
-			Email:    "john@example.com",
-			Phone:    req.Phone,
-		}
+	t.Run("creates valid items and reports the invalid one", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
 
-		mockRepo.On("UpdateUser", ctx, userID, mock.AnythingOfType("map[string]interface {}")).Return(expectedUser, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, "1111111111", uint(0)).Return(false, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, "3333333333", uint(0)).Return(false, nil).Once()
+		mockRepo.On("CreateContacts", ctx, mock.MatchedBy(func(contacts []*models.Contact) bool {
+			return len(contacts) == 2 && contacts[0].FullName == "Alice" && contacts[1].FullName == "Carol"
+		})).Run(func(args mock.Arguments) {
+			for i, contact := range args.Get(1).([]*models.Contact) {
+				contact.ID = uint(10 + i)
+			}
+		}).Return(nil).Once()
 
-		user, err := service.UpdateProfile(ctx, userID, req)
+		results, err := service.CreateContactsBatch(ctx, userID, mixedBatch())
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedUser, user)
+		require.Len(t, results, 3)
+		assert.Equal(t, models.BatchContactResult{Index: 0, ID: 10}, results[0])
+		assert.Equal(t, models.BatchContactResult{Index: 1, Error: "full_name is required"}, results[1])
+		assert.Equal(t, models.BatchContactResult{Index: 2, ID: 11}, results[2])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports duplicates within the batch and against existing contacts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		mockRepo.On("CheckContactExists", ctx, userID, "1111111111", uint(0)).Return(false, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, "4444444444", uint(0)).Return(true, nil).Once()
+		mockRepo.On("GetContactByPhone", ctx, userID, "4444444444").Return(&models.Contact{ID: 7}, nil).Once()
+		mockRepo.On("CreateContacts", ctx, mock.Anything).Return(nil).Once()
+
+		results, err := service.CreateContactsBatch(ctx, userID, []models.CreateContactRequest{
+			{FullName: "Alice", Phone: "1111111111"},
+			{FullName: "Alice again", Phone: "1111111111"},
+			{FullName: "Dave", Phone: "4444444444"},
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, results[0].Error)
+		assert.Equal(t, "phone number appears more than once in the batch", results[1].Error)
+		assert.Equal(t, ErrPhoneExists.Error(), results[2].Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("enforces the quota across the whole batch", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithContactQuota(3))
+
+		mockRepo.On("CheckContactExists", ctx, userID, mock.Anything, uint(0)).Return(false, nil).Twice()
+		mockRepo.On("CountContacts", ctx, userID).Return(int64(2), nil).Once()
+
+		results, err := service.CreateContactsBatch(ctx, userID, mixedBatch())
+
+		assert.Equal(t, ErrContactQuotaExceeded, err)
+		assert.Nil(t, results)
+		mockRepo.AssertNotCalled(t, "CreateContacts", mock.Anything, mock.Anything)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestService_ListContacts(t *testing.T) {
-	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+func TestService_TagContactsByQuery(t *testing.T) {
 	ctx := context.Background()
+	userID := uint(1)
 
-	t.Run("successful contact listing", func(t *testing.T) {
-		userID := uint(1)
-		req := &models.ListContactsRequest{
-			Page:  1,
-			Limit: 10,
-			Query: "test",
-		}
+	t.Run("tags contacts matching the filter", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+		blocked := true
 
-		expectedContacts := []models.Contact{
-			{ID: 1, FullName: "Test Contact", Phone: "+1234567890"},
-		}
-		expectedTotal := int64(1)
+		mockRepo.On("TagContacts", ctx, userID, models.ContactFilter{Query: "spam caller", Blocked: &blocked}, "spam").Return([]uint{1, 2, 3, 4}, nil).Once()
 
-		mockRepo.On("ListContacts", ctx, userID, req.Query, 0, req.Limit).Return(expectedContacts, expectedTotal, nil).Once()
+		tagged, err := service.TagContactsByQuery(ctx, userID, &models.TagByQueryRequest{Query: " spam   caller", Blocked: &blocked, Tag: "  Spam "})
 
-		contacts, total, err := service.ListContacts(ctx, userID, req)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), tagged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty filter requires confirm", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		_, err := service.TagContactsByQuery(ctx, userID, &models.TagByQueryRequest{Query: "   ", Tag: "everyone"})
+
+		assert.EqualError(t, err, ErrTagFilterRequired.Error())
+		mockRepo.AssertNotCalled(t, "TagContacts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("confirmed empty filter tags everything", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := service.NewService(mockRepo, token.NewService("test_secret"))
+
+		mockRepo.On("TagContacts", ctx, userID, models.ContactFilter{}, "everyone").Return([]uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil).Once()
+
+		tagged, err := service.TagContactsByQuery(ctx, userID, &models.TagByQueryRequest{Tag: "everyone", Confirm: true})
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedContacts, contacts)
-		assert.Equal(t, expectedTotal, total)
+		assert.Equal(t, int64(10), tagged)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestService_CreateContact(t *testing.T) {
+func TestService_ValidateContact(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
 	ctx := context.Background()
 
-	t.Run("successful contact creation", func(t *testing.T) {
+	t.Run("valid payload is not persisted", func(t *testing.T) {
 		userID := uint(1)
 		req := &models.CreateContactRequest{
 			FullName: "New Contact",
-			Phone:    "+1234567890",
-		}
-
-		expectedContact := &models.Contact{
-			ID:       1,
-			UserID:   userID,
-			FullName: req.FullName,
-			Phone:    req.Phone,
+			Phone:    "1234567890",
 		}
 
-		mockRepo.On("CheckContactExists", ctx, userID, req.Phone).Return(false, nil).Once()
-		mockRepo.On("CreateContact", ctx, mock.AnythingOfType("*models.Contact")).Return(expectedContact, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(false, nil).Once()
 
-		contact, err := service.CreateContact(ctx, userID, req)
+		contact, err := service.ValidateContact(ctx, userID, req)
 
 		require.NoError(t, err)
-		assert.Equal(t, expectedContact, contact)
+		assert.Zero(t, contact.ID)
+		assert.Equal(t, userID, contact.UserID)
+		assert.Equal(t, req.FullName, contact.FullName)
+		assert.Equal(t, req.Phone, contact.Phone)
 		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateContact", mock.Anything, mock.Anything)
 	})
 
-	t.Run("phone number already exists", func(t *testing.T) {
+	t.Run("duplicate phone", func(t *testing.T) {
 		userID := uint(1)
 		req := &models.CreateContactRequest{
-			FullName: "New Contact",
-			Phone:    "+1234567890",
+			FullName: "Duplicate Contact",
+			Phone:    "1234567890",
 		}
 
-		mockRepo.On("CheckContactExists", ctx, userID, req.Phone).Return(true, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(true, nil).Once()
+		mockRepo.On("GetContactByPhone", ctx, userID, req.Phone).Return(nil, errors.New("record not found")).Once()
 
-		contact, err := service.CreateContact(ctx, userID, req)
+		contact, err := service.ValidateContact(ctx, userID, req)
 
 		assert.Error(t, err)
 		assert.Equal(t, ErrPhoneExists, err)
@@ -367,9 +1480,26 @@ func TestService_CreateContact(t *testing.T) {
 	})
 }
 
+func TestService_ValidateContact_Quota(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"), service.WithContactQuota(3))
+	ctx := context.Background()
+	userID := uint(1)
+	req := &models.CreateContactRequest{FullName: "One Too Many", Phone: "1234567890"}
+
+	mockRepo.On("CheckContactExists", ctx, userID, req.Phone, uint(0)).Return(false, nil).Once()
+	mockRepo.On("CountContacts", ctx, userID).Return(int64(3), nil).Once()
+
+	contact, err := service.ValidateContact(ctx, userID, req)
+
+	assert.Equal(t, ErrContactQuotaExceeded, err)
+	assert.Nil(t, contact)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_GetContact(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
 	ctx := context.Background()
 
 	t.Run("successful contact retrieval", func(t *testing.T) {
@@ -380,7 +1510,7 @@ func TestService_GetContact(t *testing.T) {
 			ID:       contactID,
 			UserID:   userID,
 			FullName: "Test Contact",
-			Phone:    "+1234567890",
+			Phone:    "1234567890",
 		}
 
 		mockRepo.On("GetContact", ctx, userID, contactID).Return(expectedContact, nil).Once()
@@ -409,7 +1539,7 @@ func TestService_GetContact(t *testing.T) {
 
 func TestService_UpdateContact(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
 	ctx := context.Background()
 
 	t.Run("successful contact update", func(t *testing.T) {
@@ -417,14 +1547,14 @@ func TestService_UpdateContact(t *testing.T) {
 		contactID := uint(1)
 		req := &models.UpdateContactRequest{
 			FullName: "Updated Contact",
-			Phone:    "+0987654321",
+			Phone:    "9876543210",
 		}
 
 		existingContact := &models.Contact{
 			ID:       contactID,
 			UserID:   userID,
 			FullName: "Old Contact",
-			Phone:    "+1234567890",
+			Phone:    "1234567890",
 		}
 
 		updatedContact := &models.Contact{
@@ -435,7 +1565,7 @@ func TestService_UpdateContact(t *testing.T) {
 		}
 
 		mockRepo.On("GetContact", ctx, userID, contactID).Return(existingContact, nil).Once()
-		mockRepo.On("CheckContactExists", ctx, userID, req.Phone).Return(false, nil).Once()
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, contactID).Return(false, nil).Once()
 		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.AnythingOfType("map[string]interface {}")).Return(updatedContact, nil).Once()
 
 		contact, err := service.UpdateContact(ctx, userID, contactID, req)
@@ -445,12 +1575,88 @@ func TestService_UpdateContact(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("email semantics", func(t *testing.T) {
+		userID := uint(1)
+		contactID := uint(1)
+		existingContact := &models.Contact{ID: contactID, UserID: userID, FullName: "Caller", Phone: "1234567890", Email: stringPtr("old@example.com")}
+		update := func(email models.OptionalString) error {
+			_, err := service.UpdateContact(ctx, userID, contactID, &models.UpdateContactRequest{FullName: "Caller", Phone: "1234567890", Email: email})
+			return err
+		}
+
+		mockRepo.On("GetContact", ctx, userID, contactID).Return(existingContact, nil).Times(4)
+		mockRepo.On("CheckContactExists", ctx, userID, "1234567890", contactID).Return(false, nil).Times(4)
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			_, ok := updates["email"]
+			return !ok
+		})).Return(existingContact, nil).Once()
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			email, ok := updates["email"]
+			return ok && email == (*string)(nil)
+		})).Return(existingContact, nil).Twice()
+
+		// Omitted leaves the email unchanged
+		require.NoError(t, update(models.OptionalString{}))
+		// null and an empty string set it to NULL
+		require.NoError(t, update(models.NullString()))
+		require.NoError(t, update(models.NewOptionalString("")))
+		// Anything else must be valid
+		assert.Equal(t, ErrInvalidEmail, update(models.NewOptionalString("caller")))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("blocked flag only changes when sent", func(t *testing.T) {
+		userID := uint(1)
+		contactID := uint(1)
+		existingContact := &models.Contact{ID: contactID, UserID: userID, FullName: "Caller", Phone: "1234567890", Blocked: true}
+		blocked := false
+
+		mockRepo.On("GetContact", ctx, userID, contactID).Return(existingContact, nil).Twice()
+		mockRepo.On("CheckContactExists", ctx, userID, "1234567890", contactID).Return(false, nil).Twice()
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			_, ok := updates["blocked"]
+			return !ok
+		})).Return(existingContact, nil).Once()
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			return updates["blocked"] == false
+		})).Return(existingContact, nil).Once()
+
+		_, err := service.UpdateContact(ctx, userID, contactID, &models.UpdateContactRequest{FullName: "Caller", Phone: "1234567890"})
+		require.NoError(t, err)
+		_, err = service.UpdateContact(ctx, userID, contactID, &models.UpdateContactRequest{FullName: "Caller", Phone: "1234567890", Blocked: &blocked})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("favorite flag only changes when sent", func(t *testing.T) {
+		userID := uint(1)
+		contactID := uint(1)
+		existingContact := &models.Contact{ID: contactID, UserID: userID, FullName: "Caller", Phone: "1234567890", Favorite: true}
+		favorite := false
+
+		mockRepo.On("GetContact", ctx, userID, contactID).Return(existingContact, nil).Twice()
+		mockRepo.On("CheckContactExists", ctx, userID, "1234567890", contactID).Return(false, nil).Twice()
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			_, ok := updates["favorite"]
+			return !ok
+		})).Return(existingContact, nil).Once()
+		mockRepo.On("UpdateContact", ctx, userID, contactID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			return updates["favorite"] == false
+		})).Return(existingContact, nil).Once()
+
+		_, err := service.UpdateContact(ctx, userID, contactID, &models.UpdateContactRequest{FullName: "Caller", Phone: "1234567890"})
+		require.NoError(t, err)
+		_, err = service.UpdateContact(ctx, userID, contactID, &models.UpdateContactRequest{FullName: "Caller", Phone: "1234567890", Favorite: &favorite})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("contact not found", func(t *testing.T) {
 		userID := uint(1)
 		contactID := uint(999)
 		req := &models.UpdateContactRequest{
 			FullName: "Updated Contact",
-			Phone:    "+0987654321",
+			Phone:    "9876543210",
 		}
 
 		mockRepo.On("GetContact", ctx, userID, contactID).Return(nil, errors.New("contact not found")).Once()
@@ -468,23 +1674,26 @@ func TestService_UpdateContact(t *testing.T) {
 		contactID := uint(1)
 		req := &models.UpdateContactRequest{
 			FullName: "Updated Contact",
-			Phone:    "+0987654321",
+			Phone:    "9876543210",
 		}
 
 		existingContact := &models.Contact{
 			ID:       contactID,
 			UserID:   userID,
 			FullName: "Old Contact",
-			Phone:    "+1234567890",
+			Phone:    "1234567890",
 		}
 
 		mockRepo.On("GetContact", ctx, userID, contactID).Return(existingContact, nil).Once()
-		mockRepo.On("CheckContactExists", ctx, userID, req.Phone).Return(true, nil).Once()
+		conflicting := &models.Contact{ID: 7, UserID: userID, FullName: "Other Contact", Phone: req.Phone}
+		mockRepo.On("CheckContactExists", ctx, userID, req.Phone, contactID).Return(true, nil).Once()
+		mockRepo.On("GetContactByPhone", ctx, userID, req.Phone).Return(conflicting, nil).Once()
 
 		contact, err := service.UpdateContact(ctx, userID, contactID, req)
 
 		assert.Error(t, err)
-		assert.Equal(t, ErrPhoneExists, err)
+		assert.EqualError(t, err, ErrPhoneExists.Error())
+		assert.Equal(t, conflicting, conflictingContact(err))
 		assert.Nil(t, contact)
 		mockRepo.AssertExpectations(t)
 	})
@@ -492,7 +1701,7 @@ func TestService_UpdateContact(t *testing.T) {
 
 func TestService_DeleteContact(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := service.NewService(mockRepo, "test_secret")
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
 	ctx := context.Background()
 
 	t.Run("successful contact deletion", func(t *testing.T) {
@@ -520,3 +1729,38 @@ func TestService_DeleteContact(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestService_DeleteContactIfUnchanged(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := service.NewService(mockRepo, token.NewService("test_secret"))
+	ctx := context.Background()
+	userID := uint(1)
+	loaded := uint(4)
+
+	t.Run("unchanged contact is deleted", func(t *testing.T) {
+		mockRepo.On("DeleteContactIfUnchanged", ctx, userID, uint(1), loaded).Return(nil).Once()
+
+		assert.NoError(t, service.DeleteContactIfUnchanged(ctx, userID, 1, loaded))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("contact updated in the meantime", func(t *testing.T) {
+		mockRepo.On("DeleteContactIfUnchanged", ctx, userID, uint(2), loaded).Return(errors.New("record not found")).Once()
+		mockRepo.On("GetContact", ctx, userID, uint(2)).Return(&models.Contact{ID: 2, Version: loaded + 1}, nil).Once()
+
+		err := service.DeleteContactIfUnchanged(ctx, userID, 2, loaded)
+
+		assert.Equal(t, ErrContactChanged, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("contact deleted in the meantime", func(t *testing.T) {
+		mockRepo.On("DeleteContactIfUnchanged", ctx, userID, uint(3), loaded).Return(errors.New("record not found")).Once()
+		mockRepo.On("GetContact", ctx, userID, uint(3)).Return(nil, errors.New("record not found")).Once()
+
+		err := service.DeleteContactIfUnchanged(ctx, userID, 3, loaded)
+
+		assert.Equal(t, ErrContactNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	sharedcache "user-service/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a shared cache kept in a map
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: map[string][]byte{}}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	if !ok {
+		return nil, sharedcache.ErrMiss
+	}
+	return value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	return nil
+}
+
+// failingStore is a shared cache that cannot be reached
+type failingStore struct{}
+
+var errStoreDown = errors.New("connection refused")
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, error) { return nil, errStoreDown }
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errStoreDown
+}
+func (failingStore) Delete(ctx context.Context, keys ...string) error { return errStoreDown }
+
+func TestService_SharedCache(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	contact, err := CreateTestContact(ctx, repo, user.ID)
+	require.NoError(t, err)
+
+	store := newMemoryStore()
+	newService := func() service.Service {
+		return service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithSharedCache(store, time.Minute))
+	}
+
+	t.Run("profile is shared without the password hash", func(t *testing.T) {
+		_, err := newService().GetUserProfile(ctx, user.ID)
+		require.NoError(t, err)
+
+		// Another instance is served from the cache, not the changed row
+		_, err = repo.UpdateUser(ctx, user.ID, map[string]interface{}{"full_name": "Changed Elsewhere"})
+		require.NoError(t, err)
+		profile, err := newService().GetUserProfile(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.FullName, profile.FullName)
+		assert.Empty(t, profile.Password)
+	})
+
+	t.Run("profile update invalidates", func(t *testing.T) {
+		_, err := newService().UpdateProfile(ctx, user.ID, models.UpdateProfileRequest{FullName: "Updated Name"})
+		require.NoError(t, err)
+
+		profile, err := newService().GetUserProfile(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Name", profile.FullName)
+	})
+
+	t.Run("contact update invalidates", func(t *testing.T) {
+		cached, err := newService().GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Equal(t, contact.FullName, cached.FullName)
+
+		_, err = newService().UpdateContact(ctx, user.ID, contact.ID, &models.UpdateContactRequest{FullName: "Renamed Contact", Phone: "+14155552671"})
+		require.NoError(t, err)
+
+		updated, err := newService().GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed Contact", updated.FullName)
+	})
+
+	t.Run("group membership changes invalidate", func(t *testing.T) {
+		svc := newService()
+		group, err := svc.CreateGroup(ctx, user.ID, models.ContactGroupRequest{Name: "Cached"})
+		require.NoError(t, err)
+		version := func() uint {
+			t.Helper()
+			cached, err := newService().GetContact(ctx, user.ID, contact.ID)
+			require.NoError(t, err)
+			return cached.Version
+		}
+
+		before := version()
+		require.NoError(t, svc.AddContactToGroup(ctx, user.ID, group.ID, contact.ID))
+		added := version()
+		assert.Greater(t, added, before)
+
+		require.NoError(t, svc.RemoveContactFromGroup(ctx, user.ID, group.ID, contact.ID))
+		removed := version()
+		assert.Greater(t, removed, added)
+
+		require.NoError(t, svc.AddContactToGroup(ctx, user.ID, group.ID, contact.ID))
+		readded := version()
+		require.NoError(t, svc.DeleteGroup(ctx, user.ID, group.ID))
+		assert.Greater(t, version(), readded)
+	})
+
+	t.Run("tagging invalidates", func(t *testing.T) {
+		cached, err := newService().GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+
+		tagged, err := newService().TagContactsByQuery(ctx, user.ID, &models.TagByQueryRequest{Tag: "cached", Confirm: true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tagged)
+
+		updated, err := newService().GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Greater(t, updated.Version, cached.Version)
+	})
+
+	t.Run("contact delete invalidates", func(t *testing.T) {
+		require.NoError(t, newService().DeleteContact(ctx, user.ID, contact.ID))
+
+		_, err := newService().GetContact(ctx, user.ID, contact.ID)
+		assert.ErrorIs(t, err, service.ErrContactNotFound)
+	})
+}
+
+func TestService_SharedCacheUnavailable(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	contact, err := CreateTestContact(ctx, repo, user.ID)
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithSharedCache(failingStore{}, time.Minute))
+
+	profile, err := svc.GetUserProfile(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, profile.Email)
+
+	_, err = svc.UpdateProfile(ctx, user.ID, models.UpdateProfileRequest{FullName: "Updated Name"})
+	require.NoError(t, err)
+
+	got, err := svc.GetContact(ctx, user.ID, contact.ID)
+	require.NoError(t, err)
+	assert.Equal(t, contact.FullName, got.FullName)
+	require.NoError(t, svc.DeleteContact(ctx, user.ID, contact.ID))
+}
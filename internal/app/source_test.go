@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactSource(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	runner := &fakeRunner{}
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()), service.WithRunner(runner))
+
+	manual, err := svc.CreateContact(ctx, user.ID, &models.CreateContactRequest{FullName: "Manual", Phone: "1111111111"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ContactSourceManual, manual.Source)
+
+	results, err := svc.CreateContactsBatch(ctx, user.ID, []models.CreateContactRequest{
+		{FullName: "Batch One", Phone: "2222222222"},
+		{FullName: "Batch Two", Phone: "3333333333"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	_, err = svc.StartContactImport(ctx, user.ID, []byte("full_name,phone,email\nImported,4444444444,\n"), "")
+	require.NoError(t, err)
+	runner.RunAll()
+
+	bySource := func(t *testing.T, source string) []string {
+		contacts, _, err := svc.ListContacts(ctx, user.ID, &models.ListContactsRequest{Source: source, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		names := make([]string, len(contacts))
+		for i, c := range contacts {
+			assert.Equal(t, source, c.Source)
+			names[i] = c.FullName
+		}
+		return names
+	}
+
+	t.Run("manual entry", func(t *testing.T) {
+		assert.Equal(t, []string{"Manual"}, bySource(t, models.ContactSourceManual))
+	})
+
+	t.Run("api batch", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Batch One", "Batch Two"}, bySource(t, models.ContactSourceAPI))
+	})
+
+	t.Run("csv import", func(t *testing.T) {
+		assert.Equal(t, []string{"Imported"}, bySource(t, models.ContactSourceCSVImport))
+	})
+
+	t.Run("source without contacts", func(t *testing.T) {
+		assert.Empty(t, bySource(t, models.ContactSourceShared))
+	})
+
+	t.Run("source is kept on update", func(t *testing.T) {
+		updated, err := svc.UpdateContact(ctx, user.ID, manual.ID, &models.UpdateContactRequest{FullName: "Manual Renamed", Phone: manual.Phone})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ContactSourceManual, updated.Source)
+	})
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store kinds accepted by New
+const (
+	KindLocal = "local"
+	KindS3    = "s3"
+)
+
+var ErrUnknownKind = errors.New("storage must be local or s3")
+
+// Store saves uploaded files and returns the URL they are served from
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+}
+
+// New creates a store of the given kind linking files under publicURL, which
+// defaults to the bucket URL for S3
+func New(kind, localDir, publicURL string, s3 S3Config) (Store, error) {
+	switch kind {
+	case KindLocal:
+		return NewLocalStore(localDir, publicURL), nil
+	case KindS3:
+		s3.PublicURL = publicURL
+		return NewS3Store(s3), nil
+	}
+	return nil, ErrUnknownKind
+}
+
+// LocalStore writes files to a directory that the server exposes under BaseURL
+type LocalStore struct {
+	Dir     string
+	BaseURL string
+}
+
+// NewLocalStore creates a store writing to dir and linking files under baseURL
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Put writes the file to the directory
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return s.BaseURL + "/" + key, nil
+}
+
+// S3Config describes an S3-compatible bucket. Requests use path-style URLs
+// ({Endpoint}/{Bucket}/{key}) so MinIO and similar servers work unchanged.
+// PublicURL is the prefix of the returned file URLs and defaults to
+// {Endpoint}/{Bucket}.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+}
+
+// S3Store uploads files to an S3-compatible bucket with signed PUT requests
+type S3Store struct {
+	cfg    S3Config
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store creates a store for the given bucket
+func NewS3Store(cfg S3Config) *S3Store {
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+	return &S3Store{
+		cfg:    cfg,
+		Client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Put uploads the file to the bucket
+func (s *S3Store) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.cfg.Endpoint+"/"+s.cfg.Bucket+"/"+key, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, hashHex(data), s.now())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("object storage returned status %d", resp.StatusCode)
+	}
+	return s.cfg.PublicURL + "/" + key, nil
+}
+
+// sign adds AWS Signature Version 4 headers covering the host and every
+// header already set on the request
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
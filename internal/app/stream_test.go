@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListContacts_NDJSON(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	// Enough contacts that a full page spans several repository batches
+	for i := 0; i < 120; i++ {
+		name := fmt.Sprintf("Contact %03d", i)
+		if i%10 == 0 {
+			name = fmt.Sprintf("Friend %03d", i)
+		}
+		_, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: name, Phone: fmt.Sprintf("555%07d", i)})
+		require.NoError(t, err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewHandler(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	api.GET("/contacts", handler.ListContacts)
+
+	stream := func(t *testing.T, target string) []models.Contact {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		var contacts []models.Contact
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var contact models.Contact
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &contact))
+			contacts = append(contacts, contact)
+		}
+		require.NoError(t, scanner.Err())
+		return contacts
+	}
+
+	t.Run("one line per contact of the page", func(t *testing.T) {
+		contacts := stream(t, "/api/v1/contacts?limit=100")
+
+		// Sorted by name: the 108 "Contact" names come before the 12 "Friend" ones
+		require.Len(t, contacts, 100)
+		assert.Equal(t, "Contact 001", contacts[0].FullName)
+		assert.Equal(t, "Contact 111", contacts[99].FullName)
+	})
+
+	t.Run("later pages start at the offset", func(t *testing.T) {
+		contacts := stream(t, "/api/v1/contacts?limit=100&page=2")
+
+		require.Len(t, contacts, 20)
+		assert.Equal(t, "Contact 112", contacts[0].FullName)
+		assert.Equal(t, "Friend 000", contacts[8].FullName)
+	})
+
+	t.Run("filters apply", func(t *testing.T) {
+		contacts := stream(t, "/api/v1/contacts?q=Friend&limit=100")
+
+		require.Len(t, contacts, 12)
+		for _, contact := range contacts {
+			assert.True(t, strings.HasPrefix(contact.FullName, "Friend"))
+		}
+	})
+
+	t.Run("no matches is an empty stream", func(t *testing.T) {
+		assert.Empty(t, stream(t, "/api/v1/contacts?q=nobody"))
+	})
+
+	t.Run("other clients keep the buffered JSON envelope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/contacts?limit=100", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data.(map[string]interface{})["contacts"], 100)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"user-service/internal/app/models"
 	"user-service/internal/app/repository"
+	"user-service/internal/testutil"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/glebarez/sqlite"
@@ -81,7 +82,7 @@ func (tdb *TestDB) Close() error {
 // MigrateTestDB runs migrations on test database
 func (tdb *TestDB) MigrateTestDB() error {
 	// Auto-migrate the schema
-	err := tdb.DB.AutoMigrate(&models.User{}, &models.Contact{})
+	err := tdb.DB.AutoMigrate(&models.User{}, &models.Contact{}, &models.InviteCode{}, &models.ImportJob{}, &models.ContactTag{}, &models.ContactGroup{}, &models.ContactGroupMember{}, &models.RevokedToken{}, &models.PasswordResetToken{})
 	if err != nil {
 		return fmt.Errorf("failed to migrate test database: %w", err)
 	}
@@ -90,10 +91,11 @@ func (tdb *TestDB) MigrateTestDB() error {
 
 // TestUser creates a test user for testing
 func TestUser() *models.User {
+	phone := "1234567890"
 	return &models.User{
 		FullName: "Test User",
 		Email:    "test@example.com",
-		Phone:    "+1234567890",
+		Phone:    &phone,
 		Password: "hashedpassword",
 	}
 }
@@ -181,5 +183,10 @@ func GetTestJWTToken() string {
 
 // GetTestJWTSecret returns a test JWT secret
 func GetTestJWTSecret() string {
-	return "test_jwt_secret_key"
+	return testutil.GetTestJWTSecret()
+}
+
+// stringPtr returns a pointer to the given string, for optional model fields
+func stringPtr(s string) *string {
+	return &s
 }
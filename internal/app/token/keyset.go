@@ -0,0 +1,103 @@
+package token
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey       = errors.New("token signed with an unknown key")
+	ErrUnexpectedMethod = errors.New("token signed with an unexpected method")
+)
+
+// KeySet holds the keys used to verify access tokens, identified by the
+// token's kid header, and the key new tokens are signed with. Tokens without a
+// kid are verified with the legacy secret, so tokens issued before rotation
+// stay valid. Keys given as PEM public keys verify RS256 tokens only.
+type KeySet struct {
+	currentKID string
+	secrets    map[string][]byte
+	publicKeys map[string]*rsa.PublicKey
+}
+
+// NewKeySet builds a key set from the legacy secret and a JSON object mapping
+// kid to an HMAC secret or PEM encoded RSA public key. New tokens are signed
+// with currentKID, or with the legacy secret when currentKID is empty.
+func NewKeySet(legacySecret, keysJSON, currentKID string) (*KeySet, error) {
+	ks := &KeySet{
+		currentKID: currentKID,
+		secrets:    make(map[string][]byte),
+		publicKeys: make(map[string]*rsa.PublicKey),
+	}
+	if legacySecret != "" {
+		ks.secrets[""] = []byte(legacySecret)
+	}
+
+	if strings.TrimSpace(keysJSON) != "" {
+		var keys map[string]string
+		if err := json.Unmarshal([]byte(keysJSON), &keys); err != nil {
+			return nil, fmt.Errorf("invalid JWT key set: %w", err)
+		}
+		for kid, key := range keys {
+			if kid == "" || key == "" {
+				return nil, errors.New("invalid JWT key set: kid and key must not be empty")
+			}
+			if strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+				publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(key))
+				if err != nil {
+					return nil, fmt.Errorf("invalid JWT public key %q: %w", kid, err)
+				}
+				ks.publicKeys[kid] = publicKey
+				continue
+			}
+			ks.secrets[kid] = []byte(key)
+		}
+	}
+
+	if currentKID != "" {
+		if _, ok := ks.secrets[currentKID]; !ok {
+			return nil, fmt.Errorf("current JWT key %q must be a secret in the key set", currentKID)
+		}
+	}
+	return ks, nil
+}
+
+// SecretKeySet is a key set that signs and verifies with a single secret
+func SecretKeySet(secret string) *KeySet {
+	ks, _ := NewKeySet(secret, "", "")
+	return ks
+}
+
+// signingKey returns the kid and secret new tokens are signed with
+func (ks *KeySet) signingKey() (string, []byte, error) {
+	secret, ok := ks.secrets[ks.currentKID]
+	if !ok || len(secret) == 0 {
+		return "", nil, ErrEmptySecret
+	}
+	return ks.currentKID, secret, nil
+}
+
+// Keyfunc selects the verification key by the token's kid header and rejects
+// tokens whose signing method does not match the key type
+func (ks *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	if secret, ok := ks.secrets[kid]; ok {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrUnexpectedMethod
+		}
+		return secret, nil
+	}
+	if publicKey, ok := ks.publicKeys[kid]; ok {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrUnexpectedMethod
+		}
+		return publicKey, nil
+	}
+	return nil, ErrUnknownKey
+}
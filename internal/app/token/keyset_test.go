@@ -0,0 +1,121 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeys = `{"2025-01":"old_secret","2025-06":"new_secret"}`
+
+func parse(t *testing.T, ks *KeySet, tokenString string) (jwt.MapClaims, error) {
+	t.Helper()
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.Keyfunc)
+	return claims, err
+}
+
+func TestKeySet(t *testing.T) {
+	t.Run("signs with the current key and names it in kid", func(t *testing.T) {
+		ks, err := NewKeySet("legacy_secret", testKeys, "2025-06")
+		require.NoError(t, err)
+
+		tokenString, err := NewKeySetService(ks).Generate(7, "user")
+		require.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-06", parsed.Header["kid"])
+
+		claims, err := parse(t, ks, tokenString)
+		require.NoError(t, err)
+		assert.Equal(t, float64(7), claims["user_id"])
+	})
+
+	t.Run("accepts a token signed with an older key still in the set", func(t *testing.T) {
+		oldKeys, err := NewKeySet("", testKeys, "2025-01")
+		require.NoError(t, err)
+		tokenString, err := NewKeySetService(oldKeys).Generate(3, "admin")
+		require.NoError(t, err)
+
+		rotated, err := NewKeySet("", testKeys, "2025-06")
+		require.NoError(t, err)
+
+		claims, err := parse(t, rotated, tokenString)
+		require.NoError(t, err)
+		assert.Equal(t, "admin", claims["role"])
+	})
+
+	t.Run("rejects a token whose key was removed", func(t *testing.T) {
+		oldKeys, err := NewKeySet("", testKeys, "2025-01")
+		require.NoError(t, err)
+		tokenString, err := NewKeySetService(oldKeys).Generate(3, "user")
+		require.NoError(t, err)
+
+		pruned, err := NewKeySet("", `{"2025-06":"new_secret"}`, "2025-06")
+		require.NoError(t, err)
+
+		_, err = parse(t, pruned, tokenString)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("tokens without kid use the legacy secret", func(t *testing.T) {
+		tokenString, err := NewService("legacy_secret").Generate(1, "user")
+		require.NoError(t, err)
+
+		ks, err := NewKeySet("legacy_secret", testKeys, "2025-06")
+		require.NoError(t, err)
+
+		_, err = parse(t, ks, tokenString)
+		assert.NoError(t, err)
+	})
+
+	t.Run("verifies RS256 tokens with a public key", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		require.NoError(t, err)
+		keysJSON, err := json.Marshal(map[string]string{
+			"rsa-1": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		})
+		require.NoError(t, err)
+
+		ks, err := NewKeySet("legacy_secret", string(keysJSON), "")
+		require.NoError(t, err)
+
+		signed := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"user_id": 9})
+		signed.Header["kid"] = "rsa-1"
+		tokenString, err := signed.SignedString(privateKey)
+		require.NoError(t, err)
+
+		_, err = parse(t, ks, tokenString)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects a signing method that does not match the key", func(t *testing.T) {
+		ks, err := NewKeySet("legacy_secret", "", "")
+		require.NoError(t, err)
+
+		signed := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"user_id": 1})
+		tokenString, err := signed.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = parse(t, ks, tokenString)
+		assert.ErrorIs(t, err, ErrUnexpectedMethod)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := NewKeySet("", `not json`, "")
+		assert.Error(t, err)
+
+		_, err = NewKeySet("", testKeys, "missing")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrEmptySecret is returned when a token service is used without a signing secret
+var ErrEmptySecret = errors.New("token signing secret is not configured")
+
+// DefaultTTL is how long issued tokens stay valid unless WithTTL says otherwise
+const DefaultTTL = 24 * time.Hour
+
+// Service issues access tokens for authenticated users
+type Service interface {
+	Generate(userID uint, role string) (string, error)
+}
+
+type jwtService struct {
+	keys *KeySet
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// Option configures a token service
+type Option func(*jwtService)
+
+// WithTTL sets how long issued tokens stay valid. Non-positive values keep
+// DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *jwtService) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+// NewService creates a token service that signs HS256 JWTs with the given secret
+func NewService(secret string, opts ...Option) Service {
+	return NewKeySetService(SecretKeySet(secret), opts...)
+}
+
+// NewKeySetService creates a token service that signs HS256 JWTs with the key
+// set's current key, naming it in the kid header
+func NewKeySetService(keys *KeySet, opts ...Option) Service {
+	s := &jwtService{keys: keys, ttl: DefaultTTL, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Generate creates a signed access token carrying the user ID and role, issued
+// now and expiring after the service's TTL. Each token gets a random jti so it
+// can be revoked on its own.
+func (s *jwtService) Generate(userID uint, role string) (string, error) {
+	kid, secret, err := s.keys.signingKey()
+	if err != nil {
+		return "", err
+	}
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     now.Add(s.ttl).Unix(),
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		t.Header["kid"] = kid
+	}
+	return t.SignedString(secret)
+}
+
+// newTokenID returns a random 128-bit token ID
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
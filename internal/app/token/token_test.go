@@ -0,0 +1,68 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("signs user id and role", func(t *testing.T) {
+		svc := NewService("test_secret")
+
+		tokenString, err := svc.Generate(42, "admin")
+		require.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		parsed, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte("test_secret"), nil
+		})
+		require.NoError(t, err)
+		assert.True(t, parsed.Valid)
+		assert.Equal(t, jwt.SigningMethodHS256.Alg(), parsed.Method.Alg())
+		assert.Equal(t, float64(42), claims["user_id"])
+		assert.Equal(t, "admin", claims["role"])
+		assert.Contains(t, claims, "iat")
+		assert.Contains(t, claims, "exp")
+	})
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		s := NewService("test_secret", WithTTL(time.Hour)).(*jwtService)
+		issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return issued }
+
+		tokenString, err := s.Generate(1, "user")
+		require.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte("test_secret"), nil
+		})
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+		assert.Equal(t, float64(issued.Unix()), claims["iat"])
+		assert.Equal(t, float64(issued.Add(time.Hour).Unix()), claims["exp"])
+	})
+
+	t.Run("defaults to DefaultTTL", func(t *testing.T) {
+		s := NewService("test_secret", WithTTL(0)).(*jwtService)
+		assert.Equal(t, DefaultTTL, s.ttl)
+	})
+
+	t.Run("rejects verification with another secret", func(t *testing.T) {
+		tokenString, err := NewService("test_secret").Generate(1, "user")
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return []byte("other_secret"), nil
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("empty secret", func(t *testing.T) {
+		_, err := NewService("").Generate(1, "user")
+		assert.ErrorIs(t, err, ErrEmptySecret)
+	})
+}
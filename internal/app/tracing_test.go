@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+	"user-service/internal/logger"
+	"user-service/internal/middleware"
+	"user-service/internal/tracing"
+	"user-service/pkg/db"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing_HandlerServiceRepository(t *testing.T) {
+	tdb, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+	user, err := CreateTestUser(context.Background(), repo)
+	require.NoError(t, err)
+
+	recorder := tracing.NewRecorder()
+	tracing.SetExporter(recorder)
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+	require.NoError(t, db.UseTracing(tdb.DB))
+
+	svc := service.NewTracedService(service.NewService(repo, token.NewService(GetTestJWTSecret())))
+	h := handlers.NewHandler(svc)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CorrelationID(), middleware.Tracing())
+	router.GET("/me", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		h.GetProfile(c)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(logger.CorrelationIDHeader, "trace-test")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := map[string][]*tracing.Span{}
+	for _, span := range recorder.Spans() {
+		spans[span.Name] = append(spans[span.Name], span)
+	}
+	require.Len(t, spans["GET /me"], 1)
+	require.Len(t, spans["service.GetUserProfile"], 1)
+	// Loading the profile is a single query
+	require.Len(t, spans["db.query users"], 1)
+	assert.Len(t, recorder.Spans(), 3)
+
+	request, svcSpan, query := spans["GET /me"][0], spans["service.GetUserProfile"][0], spans["db.query users"][0]
+	assert.Equal(t, "trace-test", request.Attribute("correlation_id"))
+	assert.Equal(t, request.SpanID, svcSpan.ParentID)
+	assert.Equal(t, svcSpan.SpanID, query.ParentID)
+	assert.Equal(t, request.TraceID, query.TraceID)
+	assert.Equal(t, user.ID, svcSpan.Attribute("user.id"))
+	assert.Equal(t, tracing.KindClient, query.Kind)
+	assert.Equal(t, "users", query.Attribute("db.sql.table"))
+	assert.Contains(t, query.Attribute("db.statement"), "SELECT")
+}
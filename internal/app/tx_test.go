@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_WithTx(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+	contact, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Original", Phone: "14155550001"})
+	require.NoError(t, err)
+	errFailed := errors.New("step failed")
+
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		err := repo.WithTx(ctx, func(tx repository.Repository) error {
+			require.NoError(t, tx.LockUser(ctx, user.ID))
+			_, err := tx.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Committed", Phone: "14155550002"})
+			return err
+		})
+
+		require.NoError(t, err)
+		_, err = repo.GetContactByPhone(ctx, user.ID, "14155550002")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rolls back every step when a later one fails", func(t *testing.T) {
+		err := repo.WithTx(ctx, func(tx repository.Repository) error {
+			if _, err := tx.UpdateContact(ctx, user.ID, contact.ID, map[string]interface{}{"full_name": "Renamed"}); err != nil {
+				return err
+			}
+			if _, err := tx.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Rolled Back", Phone: "14155550003"}); err != nil {
+				return err
+			}
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		unchanged, err := repo.GetContact(ctx, user.ID, contact.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Original", unchanged.FullName)
+		_, err = repo.GetContactByPhone(ctx, user.ID, "14155550003")
+		assert.Error(t, err)
+	})
+
+	t.Run("rolls back repository transactions run inside it", func(t *testing.T) {
+		duplicate, err := repo.CreateContact(ctx, &models.Contact{UserID: user.ID, FullName: "Duplicate", Phone: "14155550004"})
+		require.NoError(t, err)
+
+		err = repo.WithTx(ctx, func(tx repository.Repository) error {
+			if err := tx.MergeContacts(ctx, contact, []uint{duplicate.ID}); err != nil {
+				return err
+			}
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		_, err = repo.GetContact(ctx, user.ID, duplicate.ID)
+		assert.NoError(t, err, "the merged duplicate should not stay deleted")
+	})
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/models"
+	"user-service/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ValidationErrorFields(t *testing.T) {
+	mockService := new(MockService)
+	router := setupTestRouter(mockService)
+
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid request format", response.Message)
+		data := response.Data.(map[string]interface{})
+		fields, _ := data["fields"].(map[string]interface{})
+		return w.Code, fields
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		fields map[string]interface{}
+	}{
+		{"register", http.MethodPost, "/api/v1/auth/register", `{"email": "not-an-email", "password": "short"}`, map[string]interface{}{
+			"full_name": "is required",
+			"email":     "must be a valid email",
+			"password":  "min 8 characters",
+		}},
+		{"login", http.MethodPost, "/api/v1/auth/login", `{"email": "nope"}`, map[string]interface{}{
+			"email":    "must be a valid email",
+			"password": "is required",
+		}},
+		{"create contact", http.MethodPost, "/api/v1/contacts", `{"avatar_url": "not a url", "company": "` + string(bytes.Repeat([]byte("a"), 256)) + `"}`, map[string]interface{}{
+			"full_name":  "is required",
+			"phone":      "is required",
+			"avatar_url": "must be a valid URL",
+			"company":    "max 255 characters",
+		}},
+		{"update contact", http.MethodPut, "/api/v1/contacts/1", `{"full_name": "Jane"}`, map[string]interface{}{
+			"phone": "is required",
+		}},
+		{"wrong type", http.MethodPut, "/api/v1/contacts/1", `{"full_name": "Jane", "phone": 14155552671}`, map[string]interface{}{
+			"phone": "must be a string",
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, fields := send(tc.method, tc.path, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Equal(t, tc.fields, fields)
+		})
+	}
+
+	t.Run("malformed JSON has no field errors", func(t *testing.T) {
+		code, fields := send(http.MethodPost, "/api/v1/auth/login", `{"email":`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Nil(t, fields)
+	})
+
+	t.Run("every endpoint logs its validation failures", func(t *testing.T) {
+		for _, tc := range []struct{ handler, method, path string }{
+			{"ForgotPassword", http.MethodPost, "/api/v1/auth/forgot-password"},
+			{"ResetPassword", http.MethodPost, "/api/v1/auth/reset-password"},
+			{"UpdateProfile", http.MethodPut, "/api/v1/me"},
+			{"PatchProfile", http.MethodPatch, "/api/v1/me"},
+			{"ChangePassword", http.MethodPut, "/api/v1/me/password"},
+			{"ValidateContact", http.MethodPost, "/api/v1/contacts/validate"},
+			{"CreateContactsBatch", http.MethodPost, "/api/v1/contacts/batch"},
+			{"TagContactsByQuery", http.MethodPost, "/api/v1/contacts/tag-by-query"},
+			{"MergeContacts", http.MethodPost, "/api/v1/contacts/merge"},
+			{"PatchContact", http.MethodPatch, "/api/v1/contacts/1"},
+		} {
+			t.Run(tc.handler, func(t *testing.T) {
+				var buf bytes.Buffer
+				previous := logger.SetDefault(logger.New(&buf))
+				defer logger.SetDefault(previous)
+
+				code, _ := send(tc.method, tc.path, `{"email":`)
+
+				assert.Equal(t, http.StatusBadRequest, code)
+				assert.Contains(t, buf.String(), `"error_type":"validation_error"`)
+				assert.Contains(t, buf.String(), `"handler":"`+tc.handler+`"`)
+			})
+		}
+	})
+
+	mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,250 @@
+// Package vcard encodes contacts as vCard 3.0 and decodes vCard files into
+// contact requests.
+package vcard
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"unicode"
+	"user-service/internal/app/models"
+)
+
+// ContentType is the media type of vCard documents
+const ContentType = "text/vcard; charset=utf-8"
+
+// byteOrderMark is the UTF-8 BOM some apps start exports with
+const byteOrderMark = "\xef\xbb\xbf"
+
+// maxLineLength is the line length, in octets, past which lines are folded
+const maxLineLength = 75
+
+var ErrInvalid = errors.New("invalid vCard")
+
+// IsVCard reports whether data looks like a vCard file
+func IsVCard(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte(byteOrderMark))
+	data = bytes.TrimLeftFunc(data, unicode.IsSpace)
+	return len(data) >= 11 && strings.EqualFold(string(data[:11]), "BEGIN:VCARD")
+}
+
+// Marshal encodes contacts as vCard 3.0, one card per contact
+func Marshal(contacts ...models.Contact) []byte {
+	var buf bytes.Buffer
+	for i := range contacts {
+		c := &contacts[i]
+		writeLine(&buf, "BEGIN:VCARD")
+		writeLine(&buf, "VERSION:3.0")
+		writeLine(&buf, "FN:"+escape(c.FullName))
+		writeLine(&buf, "N:"+structuredName(c.FullName))
+		writeLine(&buf, "TEL;TYPE=CELL:"+escape(c.Phone))
+		if c.Email != nil && *c.Email != "" {
+			writeLine(&buf, "EMAIL;TYPE=INTERNET:"+escape(*c.Email))
+		}
+		if c.Company != nil && *c.Company != "" {
+			writeLine(&buf, "ORG:"+escape(*c.Company))
+		}
+		if c.JobTitle != nil && *c.JobTitle != "" {
+			writeLine(&buf, "TITLE:"+escape(*c.JobTitle))
+		}
+		if c.AvatarURL != nil && *c.AvatarURL != "" {
+			writeLine(&buf, "PHOTO;VALUE=URI:"+*c.AvatarURL)
+		}
+		writeLine(&buf, "END:VCARD")
+	}
+	return buf.Bytes()
+}
+
+// Unmarshal decodes every card in data. Cards without a name or phone are
+// returned with the field empty so callers can count them as failed.
+func Unmarshal(data []byte) ([]models.CreateContactRequest, error) {
+	if !IsVCard(data) {
+		return nil, ErrInvalid
+	}
+	data = bytes.TrimPrefix(data, []byte(byteOrderMark))
+
+	var (
+		cards []models.CreateContactRequest
+		card  *models.CreateContactRequest
+		name  string
+	)
+	for _, line := range unfold(data) {
+		prop, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			card, name = &models.CreateContactRequest{}, ""
+		case card == nil:
+			continue
+		case prop == "END" && strings.EqualFold(value, "VCARD"):
+			if card.FullName == "" {
+				card.FullName = name
+			}
+			cards = append(cards, *card)
+			card = nil
+		case prop == "FN":
+			card.FullName = strings.TrimSpace(unescape(value))
+		case prop == "N":
+			name = nameFromStructured(value)
+		case prop == "TEL" && card.Phone == "":
+			card.Phone = digits(unescape(value))
+		case prop == "EMAIL" && card.Email == nil:
+			card.Email = optional(unescape(value))
+		case prop == "ORG":
+			// The organization name is the first component; units follow
+			card.Company = optional(unescape(splitUnescaped(value, ';')[0]))
+		case prop == "TITLE":
+			card.JobTitle = optional(unescape(value))
+		case prop == "PHOTO" && strings.Contains(strings.ToUpper(params), "VALUE=URI"):
+			card.AvatarURL = optional(value)
+		}
+	}
+	if card != nil {
+		return nil, ErrInvalid
+	}
+	return cards, nil
+}
+
+// writeLine writes a content line, folding it at maxLineLength octets without
+// splitting multi-byte characters
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space that counts towards the limit
+		limit = maxLineLength - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// unfold joins folded continuation lines
+func unfold(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitLine splits a content line into its upper-cased property name (without
+// group), its parameters and its value
+func splitLine(line string) (string, string, string, bool) {
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return "", "", "", false
+	}
+	name, value := line[:colon], line[colon+1:]
+
+	var params string
+	if semi := strings.IndexByte(name, ';'); semi >= 0 {
+		name, params = name[:semi], name[semi+1:]
+	}
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return strings.ToUpper(strings.TrimSpace(name)), params, value, true
+}
+
+// structuredName builds the N property from a full name: the last word is
+// taken as the family name and the rest as given names
+func structuredName(fullName string) string {
+	words := strings.Fields(fullName)
+	if len(words) == 0 {
+		return ";;;;"
+	}
+	family := words[len(words)-1]
+	given := strings.Join(words[:len(words)-1], " ")
+	return escape(family) + ";" + escape(given) + ";;;"
+}
+
+// nameFromStructured joins the given and family names of an N property
+func nameFromStructured(value string) string {
+	parts := splitUnescaped(value, ';')
+	var words []string
+	for _, i := range []int{3, 1, 2, 0, 4} {
+		if i < len(parts) {
+			if part := strings.TrimSpace(unescape(parts[i])); part != "" {
+				words = append(words, part)
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+
+func escape(value string) string {
+	return escaper.Replace(value)
+}
+
+func unescape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			if value[i] == 'n' || value[i] == 'N' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// splitUnescaped splits value at sep characters that are not escaped
+func splitUnescaped(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func digits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+func optional(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,83 @@
+package vcard
+
+import (
+	"strings"
+	"testing"
+	"user-service/internal/app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestRoundTrip(t *testing.T) {
+	contact := models.Contact{
+		FullName:  "Jane Q. Doe",
+		Phone:     "14155552671",
+		Email:     ptr("jane@example.com"),
+		Company:   ptr("Acme, Inc; Widgets"),
+		JobTitle:  ptr("Head of Engineering"),
+		AvatarURL: ptr("https://example.com/jane.png"),
+	}
+
+	data := Marshal(contact)
+	assert.Contains(t, string(data), `ORG:Acme\, Inc\; Widgets`+"\r\n")
+	assert.Contains(t, string(data), "TITLE:Head of Engineering\r\n")
+	assert.Contains(t, string(data), "N:Doe;Jane Q.;;;\r\n")
+
+	cards, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, models.CreateContactRequest{
+		FullName:  contact.FullName,
+		Phone:     contact.Phone,
+		Email:     contact.Email,
+		Company:   contact.Company,
+		JobTitle:  contact.JobTitle,
+		AvatarURL: contact.AvatarURL,
+	}, cards[0])
+}
+
+func TestMarshal_FoldsLongLines(t *testing.T) {
+	title := strings.Repeat("Très long titre ", 10)
+	data := Marshal(models.Contact{FullName: "Long", Phone: "1", JobTitle: ptr(title)})
+
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength)
+	}
+
+	cards, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(title), *cards[0].JobTitle)
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("cards from other apps", func(t *testing.T) {
+		data := "\xef\xbb\xbfBEGIN:VCARD\nVERSION:2.1\nN:Smith;John;;Dr.;\nitem1.TEL;TYPE=CELL:+1 (415) 555-2671\n" +
+			"TEL;TYPE=WORK:5550000\nORG:Globex;Research\nEND:VCARD\n" +
+			"BEGIN:VCARD\nVERSION:3.0\nFN:No Phone\nEND:VCARD\n"
+
+		cards, err := Unmarshal([]byte(data))
+		require.NoError(t, err)
+		require.Len(t, cards, 2)
+
+		assert.Equal(t, "Dr. John Smith", cards[0].FullName)
+		assert.Equal(t, "14155552671", cards[0].Phone)
+		assert.Equal(t, "Globex", *cards[0].Company)
+		assert.Nil(t, cards[0].JobTitle)
+
+		assert.Equal(t, "No Phone", cards[1].FullName)
+		assert.Empty(t, cards[1].Phone)
+	})
+
+	t.Run("not a vCard", func(t *testing.T) {
+		_, err := Unmarshal([]byte("full_name,phone\nAlice,1\n"))
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("unterminated card", func(t *testing.T) {
+		_, err := Unmarshal([]byte("BEGIN:VCARD\nFN:Alice\n"))
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/app/handlers"
+	"user-service/internal/app/models"
+	"user-service/internal/app/service"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListContacts_WithTotal(t *testing.T) {
+	_, repo, cleanup := SetupTestEnvironment(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := CreateTestUser(ctx, repo)
+	require.NoError(t, err)
+
+	svc := service.NewService(repo, token.NewService(GetTestJWTSecret()))
+	for _, c := range []models.CreateContactRequest{
+		{FullName: "John Smith", Phone: "1111111111"},
+		{FullName: "Johnny Cash", Phone: "2222222222"},
+		{FullName: "Alice Jones", Phone: "3333333333"},
+	} {
+		c := c
+		_, err := svc.CreateContact(ctx, user.ID, &c)
+		require.NoError(t, err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handlers.NewHandler(svc)
+	api := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	api.GET("/contacts", h.ListContacts)
+
+	list := func(t *testing.T, query string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/contacts"+query, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.(map[string]interface{})
+	}
+
+	t.Run("with a query", func(t *testing.T) {
+		data := list(t, "?q=john&with_total=true")
+		assert.Equal(t, float64(2), data["count"])
+		assert.Equal(t, float64(3), data["total_all"])
+	})
+
+	t.Run("without a query", func(t *testing.T) {
+		data := list(t, "?with_total=true")
+		assert.Equal(t, float64(3), data["count"])
+		assert.Equal(t, float64(3), data["total_all"])
+	})
+
+	t.Run("omitted unless requested", func(t *testing.T) {
+		data := list(t, "?q=john")
+		assert.Equal(t, float64(2), data["count"])
+		assert.NotContains(t, data, "total_all")
+	})
+}
@@ -7,6 +7,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,98 +17,286 @@ import (
 )
 
 type JSONLogEntry struct {
-	Timestamp     string      `json:"@timestamp"`
+	Timestamp     string      `json:"@timestamp" es:"date"`
 	Level         string      `json:"level"`
 	Method        string      `json:"method"`
 	Path          string      `json:"path"`
 	Status        int         `json:"status"`
 	Latency       float64     `json:"latency_ms"` // in milliseconds
-	ClientIP      string      `json:"client_ip"`
+	ClientIP      string      `json:"client_ip" es:"ip"`
 	UserAgent     string      `json:"user_agent"`
-	ErrorMessage  string      `json:"error_message,omitempty"`
+	ErrorMessage  string      `json:"error_message,omitempty" es:"text"`
+	RequestBytes  int64       `json:"request_bytes"`
+	ResponseBytes int         `json:"response_bytes"`
 	RequestBody   interface{} `json:"request_body,omitempty"`
 	ResponseBody  interface{} `json:"response_body,omitempty"`
 	CorrelationID string      `json:"correlation_id,omitempty"`
 	UserID        uint        `json:"user_id,omitempty"`
 }
 
-var log *logrus.Logger
+// Logger writes JSON log entries to an io.Writer. It is safe for concurrent
+// use; writes to its output are serialized.
+type Logger struct {
+	entries *logrus.Logger
+}
 
-func init() {
-	log = logrus.New()
-	log.SetFormatter(&logrus.JSONFormatter{
+// New returns a Logger writing to w
+func New(w io.Writer) *Logger {
+	entries := logrus.New()
+	entries.SetFormatter(newFormatter())
+	entries.SetOutput(w)
+	return &Logger{entries: entries}
+}
+
+func newFormatter() logrus.Formatter {
+	return &logrus.JSONFormatter{
 		TimestampFormat: time.RFC3339,
 		FieldMap: logrus.FieldMap{
 			logrus.FieldKeyTime: "@timestamp",
 		},
-	})
+	}
+}
+
+// Error logs an error with context
+func (l *Logger) Error(err error, context map[string]interface{}) {
+	l.entries.WithFields(logrus.Fields(context)).Error(err)
+}
+
+// Info logs an info message with context
+func (l *Logger) Info(msg string, context map[string]interface{}) {
+	l.entries.WithFields(logrus.Fields(context)).Info(msg)
+}
 
-	// Create logs directory if it doesn't exist
-	logsDir := "logs"
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		log.Fatal(err)
+// Warn logs a warning message with context
+func (l *Logger) Warn(msg string, context map[string]interface{}) {
+	l.entries.WithFields(logrus.Fields(context)).Warn(msg)
+}
+
+// Debug logs a debug message with context
+func (l *Logger) Debug(msg string, context map[string]interface{}) {
+	l.entries.WithFields(logrus.Fields(context)).Debug(msg)
+}
+
+// SetLevel sets the lowest level l writes: debug, info, warn or error
+func (l *Logger) SetLevel(level string) error {
+	switch strings.ToLower(level) {
+	case "debug":
+		l.entries.SetLevel(logrus.DebugLevel)
+	case "info":
+		l.entries.SetLevel(logrus.InfoLevel)
+	case "warn":
+		l.entries.SetLevel(logrus.WarnLevel)
+	case "error":
+		l.entries.SetLevel(logrus.ErrorLevel)
+	default:
+		return fmt.Errorf("log level must be debug, info, warn or error, got %q", level)
 	}
+	return nil
+}
 
-	// Set up daily rotating file
-	currentTime := time.Now()
-	logFileName := filepath.Join(logsDir, fmt.Sprintf("app-%s.log", currentTime.Format("2006-01-02")))
-	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatal(err)
+// std is the Logger behind the package-level functions
+var std atomic.Pointer[Logger]
+
+// SetDefault makes l the Logger behind the package-level functions and
+// returns the one it replaces, e.g. so a test can capture logs in a buffer
+// and restore the previous Logger afterwards
+func SetDefault(l *Logger) *Logger {
+	return std.Swap(l)
+}
+
+// Default returns the Logger behind the package-level functions
+func Default() *Logger {
+	return std.Load()
+}
+
+// CorrelationIDHeader carries the ID linking the log lines of one request
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// CorrelationIDKey is the gin context key holding the request's correlation ID
+const CorrelationIDKey = "correlation_id"
+
+// CorrelationID returns the request's correlation ID, preferring the one stored
+// in the context over the request header
+func CorrelationID(c *gin.Context) string {
+	if id := c.GetString(CorrelationIDKey); id != "" {
+		return id
 	}
+	return c.GetHeader(CorrelationIDHeader)
+}
+
+// LatencyKey is the gin context key holding the request latency measured by
+// JSONLogMiddleware, so other middleware can reuse it
+const LatencyKey = "latency"
+
+func init() {
+	fileLogging.Store(true)
+	logBodies.Store(true)
+	setupOutput(logsDir, time.Now, os.Stdout)
+}
+
+// logsDir holds the daily log files
+const logsDir = "logs"
 
-	// Write to both file and stdout
-	log.SetOutput(io.MultiWriter(os.Stdout, logFile))
+// fileLogging records whether the current day's log file could be opened, so
+// an unwritable file is only warned about once
+var fileLogging atomic.Bool
+
+// setupOutput writes logs to stdout and the day's file in dir, moving to a new
+// file whenever the date given by now changes. When the file cannot be
+// created, e.g. in a read-only container, it logs to stdout only.
+func setupOutput(dir string, now func() time.Time, stdout io.Writer) {
+	out := &dailyFile{dir: dir, now: now, stdout: stdout}
+	out.mu.Lock()
+	out.rotate(now().Format("2006-01-02"))
+	out.mu.Unlock()
+
+	SetDefault(New(out))
+}
+
+// dailyFile writes to stdout and the log file of the current day. The date is
+// checked on every write, so the file changes at midnight whether or not a
+// request arrives then.
+type dailyFile struct {
+	dir    string
+	now    func() time.Time
+	stdout io.Writer
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+func (d *dailyFile) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if day := d.now().Format("2006-01-02"); day != d.day {
+		d.rotate(day)
+	}
+	n, err := d.stdout.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if d.file != nil {
+		return d.file.Write(p)
+	}
+	return n, nil
 }
 
-// rotateLogFile creates a new log file for the current day
-func rotateLogFile() {
-	logsDir := "logs"
-	currentTime := time.Now()
-	logFileName := filepath.Join(logsDir, fmt.Sprintf("app-%s.log", currentTime.Format("2006-01-02")))
+// rotate closes the current file and opens the one for day. A file that cannot
+// be opened is retried on the next day. The caller holds d.mu.
+func (d *dailyFile) rotate(day string) {
+	d.closeFile()
+	d.day = day
 
-	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	file, err := openLogFile(d.dir, day)
 	if err != nil {
-		log.Error(err)
+		if fileLogging.Swap(false) {
+			d.warnStdoutOnly(err)
+		}
 		return
 	}
+	d.file = file
+	fileLogging.Store(true)
+}
+
+// warnStdoutOnly writes the stdout fallback warning straight to stdout, as
+// logging it would re-enter the logger from inside its own write
+func (d *dailyFile) warnStdoutOnly(err error) {
+	entry := &logrus.Entry{
+		Data:    logrus.Fields{logrus.ErrorKey: err},
+		Time:    d.now(),
+		Level:   logrus.WarnLevel,
+		Message: "log file is not writable, logging to stdout only",
+	}
+	if line, formatErr := newFormatter().Format(entry); formatErr == nil {
+		_, _ = d.stdout.Write(line)
+	}
+}
+
+// Close closes the current log file
+func (d *dailyFile) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closeFile()
+}
+
+func (d *dailyFile) closeFile() error {
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// openLogFile opens the day's log file in dir, creating the directory if needed
+func openLogFile(dir string, day string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	logFileName := filepath.Join(dir, fmt.Sprintf("app-%s.log", day))
+	return os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
 
-	log.SetOutput(io.MultiWriter(os.Stdout, logFile))
+// logBodies controls whether request log entries include the request and
+// response bodies; sizes are logged either way
+var logBodies atomic.Bool
+
+// SetBodyLogging enables or disables request and response bodies in request
+// log entries. It is meant to be called once at startup.
+func SetBodyLogging(enabled bool) {
+	logBodies.Store(enabled)
 }
 
 // JSONLogMiddleware is a Gin middleware that logs requests in JSON format
 func JSONLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Check if we need to rotate the log file
-		currentTime := time.Now()
-		if currentTime.Hour() == 0 && currentTime.Minute() == 0 {
-			rotateLogFile()
-		}
+		withBodies := logBodies.Load()
 
-		// Read the request body
+		// Read the request body, or only count it when bodies are not logged
 		var requestBody interface{}
+		var requestBytes int64
+		var counter *countingReader
 		if c.Request.Body != nil {
-			bodyBytes, _ := io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			_ = json.Unmarshal(bodyBytes, &requestBody)
+			if withBodies {
+				bodyBytes, _ := io.ReadAll(c.Request.Body)
+				c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+				_ = json.Unmarshal(bodyBytes, &requestBody)
+				requestBytes = int64(len(bodyBytes))
+			} else {
+				counter = &countingReader{ReadCloser: c.Request.Body}
+				c.Request.Body = counter
+			}
 		}
 
 		// Create a custom response writer to capture the response
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
+		var blw *bodyLogWriter
+		if withBodies {
+			blw = &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+			c.Writer = blw
+		}
 
 		// Start timer
 		start := time.Now()
 
 		// Process request
 		c.Next()
+		latency := time.Since(start)
+		c.Set(LatencyKey, latency)
 
 		// Parse response body
 		var responseBody interface{}
-		if len(blw.body.String()) > 0 {
+		if blw != nil && blw.body.Len() > 0 {
 			_ = json.Unmarshal(blw.body.Bytes(), &responseBody)
 		}
 
+		// Handlers may not read the whole body; the declared length is then
+		// the better measure
+		if counter != nil {
+			requestBytes = max(counter.n, c.Request.ContentLength)
+		}
+
 		// Get user ID from context if available
 		var userID uint
 		if id, exists := c.Get("user_id"); exists {
@@ -114,23 +305,23 @@ func JSONLogMiddleware() gin.HandlerFunc {
 
 		// Create log entry
 		entry := &JSONLogEntry{
-			Timestamp:    time.Now().Format(time.RFC3339),
-			Level:        getLogLevel(c.Writer.Status()),
-			Method:       c.Request.Method,
-			Path:         c.Request.URL.Path,
-			Status:       c.Writer.Status(),
-			Latency:      float64(time.Since(start)) / float64(time.Millisecond),
-			ClientIP:     c.ClientIP(),
-			UserAgent:    c.Request.UserAgent(),
-			RequestBody:  requestBody,
-			ResponseBody: responseBody,
-			UserID:       userID,
+			Timestamp:     time.Now().Format(time.RFC3339),
+			Level:         getLogLevel(c.Writer.Status()),
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Status:        c.Writer.Status(),
+			Latency:       float64(latency) / float64(time.Millisecond),
+			ClientIP:      c.ClientIP(),
+			UserAgent:     c.Request.UserAgent(),
+			RequestBody:   requestBody,
+			ResponseBody:  responseBody,
+			RequestBytes:  requestBytes,
+			ResponseBytes: max(c.Writer.Size(), 0),
+			UserID:        userID,
 		}
 
 		// Add correlation ID if present
-		if corrID := c.GetHeader("X-Correlation-ID"); corrID != "" {
-			entry.CorrelationID = corrID
-		}
+		entry.CorrelationID = CorrelationID(c)
 
 		// Log errors if any
 		if len(c.Errors) > 0 {
@@ -139,7 +330,7 @@ func JSONLogMiddleware() gin.HandlerFunc {
 
 		// Log the entry
 		logJSON, _ := json.Marshal(entry)
-		log.Info(string(logJSON))
+		Default().Info(string(logJSON), nil)
 	}
 }
 
@@ -154,6 +345,18 @@ func (w bodyLogWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// countingReader counts the request body bytes read by handlers
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
 // getLogLevel returns the appropriate log level based on status code
 func getLogLevel(status int) string {
 	switch {
@@ -166,24 +369,30 @@ func getLogLevel(status int) string {
 	}
 }
 
+// SetLevel sets the lowest level the default Logger writes. It is meant to be
+// called once at startup.
+func SetLevel(level string) error {
+	return Default().SetLevel(level)
+}
+
 // Error logs an error message with context
 func Error(err error, context map[string]interface{}) {
-	log.WithFields(logrus.Fields(context)).Error(err)
+	Default().Error(err, context)
 }
 
 // Info logs an info message with context
 func Info(msg string, context map[string]interface{}) {
-	log.WithFields(logrus.Fields(context)).Info(msg)
+	Default().Info(msg, context)
 }
 
 // Warn logs a warning message with context
 func Warn(msg string, context map[string]interface{}) {
-	log.WithFields(logrus.Fields(context)).Warn(msg)
+	Default().Warn(msg, context)
 }
 
 // Debug logs a debug message with context
 func Debug(msg string, context map[string]interface{}) {
-	log.WithFields(logrus.Fields(context)).Debug(msg)
+	Default().Debug(msg, context)
 }
 
 // LogEndpointError logs structured endpoint errors for Kibana
@@ -197,7 +406,6 @@ func LogEndpointError(c *gin.Context, handler string, err error, statusCode int,
 		"user_agent":    c.Request.UserAgent(),
 		"error_type":    "endpoint_error",
 		"error_message": err.Error(),
-		"@timestamp":    time.Now().Format(time.RFC3339),
 	}
 
 	// Add user ID if available
@@ -206,7 +414,7 @@ func LogEndpointError(c *gin.Context, handler string, err error, statusCode int,
 	}
 
 	// Add correlation ID if present
-	if corrID := c.GetHeader("X-Correlation-ID"); corrID != "" {
+	if corrID := CorrelationID(c); corrID != "" {
 		context["correlation_id"] = corrID
 	}
 
@@ -230,7 +438,6 @@ func LogEndpointTimeout(c *gin.Context, handler string, timeout time.Duration, a
 		"error_type":      "timeout_error",
 		"error_message":   "Request timeout",
 		"timeout_seconds": timeout.Seconds(),
-		"@timestamp":      time.Now().Format(time.RFC3339),
 	}
 
 	// Add user ID if available
@@ -239,7 +446,7 @@ func LogEndpointTimeout(c *gin.Context, handler string, timeout time.Duration, a
 	}
 
 	// Add correlation ID if present
-	if corrID := c.GetHeader("X-Correlation-ID"); corrID != "" {
+	if corrID := CorrelationID(c); corrID != "" {
 		context["correlation_id"] = corrID
 	}
 
@@ -263,7 +470,6 @@ func LogValidationError(c *gin.Context, handler string, validationErrors map[str
 		"error_type":        "validation_error",
 		"error_message":     "Validation failed",
 		"validation_errors": validationErrors,
-		"@timestamp":        time.Now().Format(time.RFC3339),
 	}
 
 	// Add user ID if available
@@ -272,7 +478,7 @@ func LogValidationError(c *gin.Context, handler string, validationErrors map[str
 	}
 
 	// Add correlation ID if present
-	if corrID := c.GetHeader("X-Correlation-ID"); corrID != "" {
+	if corrID := CorrelationID(c); corrID != "" {
 		context["correlation_id"] = corrID
 	}
 
@@ -295,11 +501,10 @@ func LogAuthError(c *gin.Context, handler string, err error, additionalContext m
 		"user_agent":    c.Request.UserAgent(),
 		"error_type":    "auth_error",
 		"error_message": err.Error(),
-		"@timestamp":    time.Now().Format(time.RFC3339),
 	}
 
 	// Add correlation ID if present
-	if corrID := c.GetHeader("X-Correlation-ID"); corrID != "" {
+	if corrID := CorrelationID(c); corrID != "" {
 		context["correlation_id"] = corrID
 	}
 
@@ -310,3 +515,29 @@ func LogAuthError(c *gin.Context, handler string, err error, additionalContext m
 
 	Warn("Authentication error", context)
 }
+
+// LogPanic logs a recovered panic with its stack trace for Kibana
+func LogPanic(c *gin.Context, recovered interface{}, stack []byte) {
+	context := map[string]interface{}{
+		"method":        c.Request.Method,
+		"path":          c.Request.URL.Path,
+		"status_code":   500,
+		"client_ip":     c.ClientIP(),
+		"user_agent":    c.Request.UserAgent(),
+		"error_type":    "panic",
+		"error_message": fmt.Sprint(recovered),
+		"stack":         string(stack),
+	}
+
+	// Add user ID if available
+	if userID, exists := c.Get("user_id"); exists {
+		context["user_id"] = userID
+	}
+
+	// Add correlation ID if present
+	if corrID := CorrelationID(c); corrID != "" {
+		context["correlation_id"] = corrID
+	}
+
+	Default().entries.WithFields(logrus.Fields(context)).Error("Panic recovered")
+}
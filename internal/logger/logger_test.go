@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupOutput(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() {
+		SetDefault(previous)
+		fileLogging.Store(true)
+	})
+	day := func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("unwritable logs directory falls back to stdout", func(t *testing.T) {
+		// A regular file where the directory should be cannot be created over,
+		// even when the tests run as root
+		blocked := filepath.Join(t.TempDir(), "logs")
+		require.NoError(t, os.WriteFile(blocked, nil, 0644))
+
+		var stdout bytes.Buffer
+		fileLogging.Store(true)
+		setupOutput(blocked, day, &stdout)
+		Info("still logging", nil)
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], "logging to stdout only")
+		assert.Contains(t, lines[1], "still logging")
+	})
+
+	t.Run("the fallback is only warned about once", func(t *testing.T) {
+		blocked := filepath.Join(t.TempDir(), "logs")
+		require.NoError(t, os.WriteFile(blocked, nil, 0644))
+
+		var stdout bytes.Buffer
+		fileLogging.Store(true)
+		setupOutput(blocked, day, &stdout)
+		setupOutput(blocked, day, &stdout)
+
+		assert.Equal(t, 1, strings.Count(stdout.String(), "logging to stdout only"))
+	})
+
+	t.Run("writable directory logs to stdout and the day's file", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "logs")
+
+		var stdout bytes.Buffer
+		setupOutput(dir, day, &stdout)
+		Info("to both", nil)
+
+		assert.Contains(t, stdout.String(), "to both")
+		content, err := os.ReadFile(filepath.Join(dir, "app-2025-06-01.log"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "to both")
+	})
+
+	t.Run("a date change moves to the new day's file", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "logs")
+		now := time.Date(2025, 6, 1, 23, 59, 59, 0, time.UTC)
+		clock := func() time.Time { return now }
+
+		var stdout bytes.Buffer
+		setupOutput(dir, clock, &stdout)
+		Info("before midnight", nil)
+		now = now.Add(2 * time.Second)
+		Info("after midnight", nil)
+
+		first, err := os.ReadFile(filepath.Join(dir, "app-2025-06-01.log"))
+		require.NoError(t, err)
+		second, err := os.ReadFile(filepath.Join(dir, "app-2025-06-02.log"))
+		require.NoError(t, err)
+		assert.Contains(t, string(first), "before midnight")
+		assert.NotContains(t, string(first), "after midnight")
+		assert.Contains(t, string(second), "after midnight")
+		assert.NotContains(t, string(second), "before midnight")
+	})
+}
+
+func TestJSONLogMiddleware_Sizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := Default()
+	t.Cleanup(func() {
+		SetDefault(previous)
+		SetBodyLogging(true)
+	})
+
+	request := func(t *testing.T, body io.Reader) JSONLogEntry {
+		var buf bytes.Buffer
+		SetDefault(New(&buf))
+
+		router := gin.New()
+		router.Use(JSONLogMiddleware())
+		router.POST("/echo", func(c *gin.Context) {
+			data, _ := io.ReadAll(c.Request.Body)
+			c.Data(http.StatusOK, "application/json", append(data, data...))
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/echo", body)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var line struct {
+			Msg string `json:"msg"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		var entry JSONLogEntry
+		require.NoError(t, json.Unmarshal([]byte(line.Msg), &entry))
+		return entry
+	}
+
+	t.Run("with bodies", func(t *testing.T) {
+		SetBodyLogging(true)
+
+		entry := request(t, bytes.NewBufferString(`{"a":1}`))
+
+		assert.Equal(t, int64(7), entry.RequestBytes)
+		assert.Equal(t, 14, entry.ResponseBytes)
+		assert.NotNil(t, entry.RequestBody)
+	})
+
+	t.Run("without bodies only sizes are logged", func(t *testing.T) {
+		SetBodyLogging(false)
+
+		entry := request(t, bytes.NewBufferString(`{"a":1}`))
+
+		assert.Equal(t, int64(7), entry.RequestBytes)
+		assert.Equal(t, 14, entry.ResponseBytes)
+		assert.Nil(t, entry.RequestBody)
+		assert.Nil(t, entry.ResponseBody)
+	})
+
+	t.Run("without bodies an unknown length is counted", func(t *testing.T) {
+		SetBodyLogging(false)
+
+		// A plain reader leaves ContentLength unset
+		entry := request(t, io.MultiReader(bytes.NewBufferString(`{"a":1}`)))
+
+		assert.Equal(t, int64(7), entry.RequestBytes)
+	})
+}
+
+func TestCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me", nil)
+
+	assert.Empty(t, CorrelationID(c))
+
+	c.Request.Header.Set(CorrelationIDHeader, "from-header")
+	assert.Equal(t, "from-header", CorrelationID(c))
+
+	c.Set(CorrelationIDKey, "from-context")
+	assert.Equal(t, "from-context", CorrelationID(c))
+}
+
+func TestLogger_ConcurrentWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var first, second bytes.Buffer
+	loggers := []*Logger{New(&first), New(&second)}
+	previous := SetDefault(loggers[0])
+	t.Cleanup(func() { SetDefault(previous) })
+
+	router := gin.New()
+	router.Use(JSONLogMiddleware())
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				Info("concurrent", map[string]interface{}{"writer": i})
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+			}
+		}()
+	}
+	// Swap the default logger while the writers run
+	for i := 0; i < perWriter; i++ {
+		SetDefault(loggers[i%2])
+	}
+	wg.Wait()
+
+	lines := strings.Count(first.String(), "\n") + strings.Count(second.String(), "\n")
+	assert.Equal(t, 2*writers*perWriter, lines)
+	for _, line := range strings.Split(strings.TrimSpace(first.String()+second.String()), "\n") {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	cases := []struct {
+		level string
+		debug bool
+		info  bool
+	}{
+		{"debug", true, true},
+		{"info", false, true},
+		{"WARN", false, false},
+		{"error", false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.level, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf)
+			require.NoError(t, l.SetLevel(tc.level))
+
+			l.Debug("debug entry", nil)
+			l.Info("info entry", nil)
+			l.Error(assert.AnError, nil)
+
+			assert.Equal(t, tc.debug, strings.Contains(buf.String(), "debug entry"))
+			assert.Equal(t, tc.info, strings.Contains(buf.String(), "info entry"))
+			assert.Contains(t, buf.String(), assert.AnError.Error())
+		})
+	}
+
+	t.Run("unknown level", func(t *testing.T) {
+		assert.Error(t, New(io.Discard).SetLevel("verbose"))
+	})
+
+	t.Run("applies to the package functions", func(t *testing.T) {
+		var buf bytes.Buffer
+		previous := SetDefault(New(&buf))
+		t.Cleanup(func() { SetDefault(previous) })
+		require.NoError(t, SetLevel("debug"))
+
+		Debug("surfaced", nil)
+
+		assert.Contains(t, buf.String(), "surfaced")
+	})
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrorLogFields describes the structured fields written by LogEndpointError,
+// LogEndpointTimeout, LogValidationError, LogAuthError and LogPanic. It only exists to
+// generate the index mapping; the helpers themselves log plain maps.
+type ErrorLogFields struct {
+	Timestamp        string            `json:"@timestamp" es:"date"`
+	Level            string            `json:"level"`
+	Message          string            `json:"msg" es:"text"`
+	Handler          string            `json:"handler"`
+	Method           string            `json:"method"`
+	Path             string            `json:"path"`
+	StatusCode       int               `json:"status_code"`
+	ClientIP         string            `json:"client_ip" es:"ip"`
+	UserAgent        string            `json:"user_agent"`
+	ErrorType        string            `json:"error_type"`
+	ErrorMessage     string            `json:"error_message" es:"text"`
+	TimeoutSeconds   float64           `json:"timeout_seconds"`
+	ValidationErrors map[string]string `json:"validation_errors"`
+	Stack            string            `json:"stack" es:"text"`
+	UserID           uint              `json:"user_id"`
+	CorrelationID    string            `json:"correlation_id"`
+}
+
+// IndexMapping returns the Elasticsearch mapping for everything the logger
+// emits: request log entries and structured error fields
+func IndexMapping() map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, v := range []interface{}{JSONLogEntry{}, ErrorLogFields{}} {
+		for name, field := range fieldMappings(reflect.TypeOf(v)) {
+			if _, ok := properties[name]; !ok {
+				properties[name] = field
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"dynamic":    true,
+		"properties": properties,
+	}
+}
+
+// IndexTemplate wraps IndexMapping in a composable index template for the
+// given index patterns
+func IndexTemplate(patterns ...string) map[string]interface{} {
+	sort.Strings(patterns)
+	return map[string]interface{}{
+		"index_patterns": patterns,
+		"template": map[string]interface{}{
+			"mappings": IndexMapping(),
+		},
+	}
+}
+
+// fieldMappings maps each JSON field of a struct to its Elasticsearch type.
+// An `es` struct tag overrides the type inferred from the Go kind.
+func fieldMappings(t reflect.Type) map[string]interface{} {
+	fields := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if esType := f.Tag.Get("es"); esType != "" {
+			fields[name] = map[string]interface{}{"type": esType}
+			continue
+		}
+		fields[name] = kindMapping(f.Type)
+	}
+	return fields
+}
+
+func kindMapping(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "long"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "double"}
+	case reflect.Map:
+		return map[string]interface{}{"type": "flattened"}
+	case reflect.Interface:
+		// Request and response bodies are arbitrary JSON; keep them in _source only
+		return map[string]interface{}{"type": "object", "enabled": false}
+	default:
+		return map[string]interface{}{"type": "keyword"}
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureFields runs fn and returns every JSON key the logger wrote, including
+// the keys of request log entries embedded in the message
+func captureFields(t *testing.T, fn func()) map[string]struct{} {
+	t.Helper()
+	var buf bytes.Buffer
+	defer SetDefault(SetDefault(New(&buf)))
+
+	fn()
+
+	keys := make(map[string]struct{})
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		for key := range line {
+			keys[key] = struct{}{}
+		}
+
+		var entry map[string]interface{}
+		if msg, ok := line["msg"].(string); ok && json.Unmarshal([]byte(msg), &entry) == nil {
+			for key := range entry {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+	return keys
+}
+
+func TestIndexMapping_CoversLoggedFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := captureFields(t, func() {
+		router := gin.New()
+		router.Use(JSONLogMiddleware())
+		router.POST("/fail", func(c *gin.Context) {
+			c.Set("user_id", uint(1))
+			_ = c.Error(errors.New("boom"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "boom"})
+		})
+		req, _ := http.NewRequest("POST", "/fail", bytes.NewBufferString(`{"a":1}`))
+		req.Header.Set("X-Correlation-ID", "corr-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("GET", "/contacts", nil)
+		c.Request.Header.Set("X-Correlation-ID", "corr-2")
+		c.Set("user_id", uint(1))
+
+		LogEndpointError(c, "ListContacts", errors.New("db down"), http.StatusInternalServerError, nil)
+		LogEndpointTimeout(c, "ListContacts", 30*time.Second, nil)
+		LogValidationError(c, "Register", map[string]string{"email": "invalid"}, nil)
+		LogAuthError(c, "Login", errors.New("invalid password"), nil)
+		LogPanic(c, "boom", []byte("goroutine 1 [running]:"))
+	})
+
+	properties := IndexMapping()["properties"].(map[string]interface{})
+	require.NotEmpty(t, keys)
+	for key := range keys {
+		assert.Contains(t, properties, key, "logged field %q has no mapping", key)
+	}
+}
+
+func TestIndexMapping_Types(t *testing.T) {
+	properties := IndexMapping()["properties"].(map[string]interface{})
+
+	assert.Equal(t, map[string]interface{}{"type": "date"}, properties["@timestamp"])
+	assert.Equal(t, map[string]interface{}{"type": "double"}, properties["latency_ms"])
+	assert.Equal(t, map[string]interface{}{"type": "long"}, properties["status"])
+	assert.Equal(t, map[string]interface{}{"type": "ip"}, properties["client_ip"])
+	assert.Equal(t, map[string]interface{}{"type": "keyword"}, properties["error_type"])
+	assert.Equal(t, map[string]interface{}{"type": "flattened"}, properties["validation_errors"])
+	assert.Equal(t, map[string]interface{}{"type": "object", "enabled": false}, properties["request_body"])
+
+	template := IndexTemplate("user-service-*")
+	assert.Equal(t, []string{"user-service-*"}, template["index_patterns"])
+}
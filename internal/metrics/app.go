@@ -0,0 +1,16 @@
+package metrics
+
+// Default is the registry served on /metrics
+var Default = NewRegistry()
+
+// Metrics of the service
+var (
+	HTTPRequests = Default.Counter("http_requests_total",
+		"HTTP requests handled, by method, route and status.", "method", "route", "status")
+	HTTPRequestDuration = Default.Histogram("http_request_duration_seconds",
+		"HTTP request latency in seconds, by method, route and status.", DefaultBuckets, "method", "route", "status")
+	LoginAttempts = Default.Counter("user_service_login_attempts_total",
+		"Login attempts, by result (success or failure).", "result")
+	ContactsCreated = Default.Counter("user_service_contacts_created_total",
+		"Contacts created, including batch creates and imports.")
+)
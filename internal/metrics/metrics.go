@@ -0,0 +1,229 @@
+// Package metrics keeps counters and histograms and exposes them in the
+// Prometheus text format
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the latency buckets, in seconds, the Prometheus client
+// libraries use by default
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector is a metric family the registry exposes
+type collector interface {
+	write(buf *bytes.Buffer)
+}
+
+// Registry holds the metrics exposed by one scrape endpoint
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers a counter partitioned by the given label names
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: family{name, help, labels}, values: map[string]*counterValue{}}
+	r.register(c)
+	return c
+}
+
+// Histogram registers a histogram with the given upper bounds, which must be
+// sorted, partitioned by the given label names
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: family{name, help, labels}, buckets: buckets, values: map[string]*histogramValue{}}
+	r.register(h)
+	return h
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Handler serves the registry's metrics in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var buf bytes.Buffer
+		r.mu.Lock()
+		for _, c := range r.collectors {
+			c.write(&buf)
+		}
+		r.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// family is the name, help text and label names shared by a metric's series
+type family struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (f family) writeHeader(buf *bytes.Buffer, kind string) {
+	buf.WriteString("# HELP " + f.name + " " + strings.ReplaceAll(f.help, "\n", `\n`) + "\n")
+	buf.WriteString("# TYPE " + f.name + " " + kind + "\n")
+}
+
+// key identifies the series of the given label values
+func (f family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic("metrics: " + f.name + " takes " + strconv.Itoa(len(f.labels)) + " label values")
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders the label set of a series, followed by extra pairs
+func (f family) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, name := range f.labels {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the series keys of values in a stable order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter with one series per set of label values
+type CounterVec struct {
+	family
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+// Inc adds one to the series of the given label values
+func (c *CounterVec) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add adds v, which must not be negative, to the series of the given label values
+func (c *CounterVec) Add(v float64, labels ...string) {
+	key := c.key(labels)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	series, ok := c.values[key]
+	if !ok {
+		series = &counterValue{labels: append([]string(nil), labels...)}
+		c.values[key] = series
+	}
+	series.value += v
+}
+
+// Value returns the current value of the series of the given label values
+func (c *CounterVec) Value(labels ...string) float64 {
+	key := c.key(labels)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if series, ok := c.values[key]; ok {
+		return series.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(buf *bytes.Buffer) {
+	c.writeHeader(buf, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A counter without labels has a single series, reported from zero
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		buf.WriteString(c.name + " 0\n")
+	}
+	for _, key := range sortedKeys(c.values) {
+		series := c.values[key]
+		buf.WriteString(c.name + c.labelPairs(series.labels) + " " + formatFloat(series.value) + "\n")
+	}
+}
+
+// HistogramVec is a histogram with one series per set of label values
+type HistogramVec struct {
+	family
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe records v in the series of the given label values
+func (h *HistogramVec) Observe(v float64, labels ...string) {
+	key := h.key(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.values[key]
+	if !ok {
+		series = &histogramValue{labels: append([]string(nil), labels...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = series
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += v
+}
+
+func (h *HistogramVec) write(buf *bytes.Buffer) {
+	h.writeHeader(buf, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		series := h.values[key]
+		for i, bound := range h.buckets {
+			buf.WriteString(h.name + "_bucket" + h.labelPairs(series.labels, "le", formatFloat(bound)) + " " + strconv.FormatUint(series.counts[i], 10) + "\n")
+		}
+		buf.WriteString(h.name + "_bucket" + h.labelPairs(series.labels, "le", "+Inf") + " " + strconv.FormatUint(series.count, 10) + "\n")
+		buf.WriteString(h.name + "_sum" + h.labelPairs(series.labels) + " " + formatFloat(series.sum) + "\n")
+		buf.WriteString(h.name + "_count" + h.labelPairs(series.labels) + " " + strconv.FormatUint(series.count, 10) + "\n")
+	}
+}
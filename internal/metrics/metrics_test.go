@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(r *Registry) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return w
+}
+
+func TestRegistry_Counter(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Requests.", "path")
+	created := r.Counter("created_total", "Created.")
+
+	assert.Contains(t, scrape(r).Body.String(), "created_total 0\n")
+
+	requests.Inc("/b")
+	requests.Inc("/a")
+	requests.Add(2, `/"quoted"`)
+	created.Add(3)
+
+	w := scrape(r)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "# HELP requests_total Requests.\n"+
+		"# TYPE requests_total counter\n"+
+		`requests_total{path="/\"quoted\""} 2`+"\n"+
+		`requests_total{path="/a"} 1`+"\n"+
+		`requests_total{path="/b"} 1`+"\n"+
+		"# HELP created_total Created.\n"+
+		"# TYPE created_total counter\n"+
+		"created_total 3\n", w.Body.String())
+	assert.Equal(t, float64(1), requests.Value("/a"))
+	assert.Equal(t, float64(0), requests.Value("/missing"))
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	r := NewRegistry()
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(2, "/a")
+
+	assert.Equal(t, "# HELP latency_seconds Latency.\n"+
+		"# TYPE latency_seconds histogram\n"+
+		`latency_seconds_bucket{route="/a",le="0.1"} 1`+"\n"+
+		`latency_seconds_bucket{route="/a",le="1"} 2`+"\n"+
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3`+"\n"+
+		`latency_seconds_sum{route="/a"} 2.55`+"\n"+
+		`latency_seconds_count{route="/a"} 3`+"\n", scrape(r).Body.String())
+}
+
+func TestCounterVec_WrongLabelCount(t *testing.T) {
+	c := NewRegistry().Counter("requests_total", "Requests.", "path")
+	assert.Panics(t, func() { c.Inc() })
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/internal/app/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddlewareWithKeys(t *testing.T) {
+	const keysJSON = `{"2025-01":"old_secret","2025-06":"new_secret"}`
+
+	current, err := token.NewKeySet("", keysJSON, "2025-06")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddlewareWithKeys(current))
+	router.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id")})
+	})
+
+	send := func(tokenString string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("token signed with the current key", func(t *testing.T) {
+		tokenString, err := token.NewKeySetService(current).Generate(1, "user")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, send(tokenString))
+	})
+
+	t.Run("token signed with an older key still in the set", func(t *testing.T) {
+		previous, err := token.NewKeySet("", keysJSON, "2025-01")
+		require.NoError(t, err)
+		tokenString, err := token.NewKeySetService(previous).Generate(1, "user")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, send(tokenString))
+	})
+
+	t.Run("token signed with a key outside the set", func(t *testing.T) {
+		tokenString, err := token.NewService("stranger_secret").Generate(1, "user")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, send(tokenString))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": 1,
+			"iat":     time.Now().Add(-2 * time.Hour).Unix(),
+			"exp":     time.Now().Add(-time.Hour).Unix(),
+		})
+		expired.Header["kid"] = "2025-06"
+		tokenString, err := expired.SignedString([]byte("new_secret"))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Token has expired")
+	})
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	const secret = "test_secret"
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddleware(secret))
+	router.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id")})
+	})
+
+	send := func(header string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	sign := func(method jwt.SigningMethod, claims jwt.MapClaims) string {
+		tokenString, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return tokenString
+	}
+
+	valid, err := token.NewService(secret).Generate(42, "user")
+	require.NoError(t, err)
+
+	t.Run("valid token sets the user id", func(t *testing.T) {
+		w := send("Bearer " + valid)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":42}`, w.Body.String())
+	})
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		error  string
+	}{
+		{"missing header", "", "Authorization header is required"},
+		{"missing scheme", valid, "Invalid authorization header format"},
+		{"wrong scheme", "Basic " + valid, "Invalid authorization header format"},
+		{"extra parts", "Bearer " + valid + " extra", "Invalid authorization header format"},
+		{"malformed token", "Bearer not-a-jwt", "Invalid or expired token"},
+		{"bad signature", "Bearer " + valid[:len(valid)-2] + "xx", "Invalid or expired token"},
+		{"unexpected signing method", "Bearer " + sign(jwt.SigningMethodHS512, jwt.MapClaims{
+			"user_id": 42,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}), "Invalid or expired token"},
+		{"no expiry", "Bearer " + sign(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 42}), "Invalid or expired token"},
+		{"expired token", "Bearer " + sign(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": 42,
+			"exp":     time.Now().Add(-time.Hour).Unix(),
+		}), "Token has expired"},
+		{"no user id", "Bearer " + sign(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}), "Invalid token claims"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := send(tc.header)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), tc.error)
+		})
+	}
+}
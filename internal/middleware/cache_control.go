@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NoStore marks responses as never cacheable, for auth and mutating endpoints
+func NoStore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Header("Pragma", "no-cache")
+		c.Next()
+	}
+}
+
+// PrivateCache lets browsers (but not shared proxies) briefly cache safe reads.
+// Non-GET requests are marked no-store.
+func PrivateCache(maxAge time.Duration) gin.HandlerFunc {
+	value := fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Header("Cache-Control", value)
+		} else {
+			c.Header("Cache-Control", "no-store")
+		}
+		c.Next()
+	}
+}
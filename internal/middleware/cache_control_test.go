@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCacheRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }
+
+	auth := router.Group("/api/v1/auth")
+	auth.Use(NoStore())
+	auth.POST("/login", ok)
+
+	protected := router.Group("/api/v1")
+	protected.Use(PrivateCache(30 * time.Second))
+	protected.GET("/me", ok)
+	protected.PUT("/me", ok)
+	protected.GET("/contacts/:id", ok)
+	protected.POST("/contacts", ok)
+	protected.DELETE("/contacts/:id", ok)
+
+	admin := protected.Group("/admin")
+	admin.Use(NoStore())
+	admin.GET("/invite-codes", ok)
+
+	return router
+}
+
+func TestCacheControl(t *testing.T) {
+	router := setupCacheRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{"auth endpoint", http.MethodPost, "/api/v1/auth/login", "no-store"},
+		{"profile read", http.MethodGet, "/api/v1/me", "private, max-age=30"},
+		{"profile update", http.MethodPut, "/api/v1/me", "no-store"},
+		{"contact read", http.MethodGet, "/api/v1/contacts/1", "private, max-age=30"},
+		{"contact create", http.MethodPost, "/api/v1/contacts", "no-store"},
+		{"contact delete", http.MethodDelete, "/api/v1/contacts/1", "no-store"},
+		{"admin read", http.MethodGet, "/api/v1/admin/invite-codes", "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, w.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"user-service/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxCorrelationIDLength bounds client supplied correlation IDs
+const maxCorrelationIDLength = 128
+
+// CorrelationID gives every request a correlation ID, keeping a well-formed
+// X-Correlation-ID sent by the client and generating a UUID otherwise. The ID
+// is stored in the context for the logger and echoed in the response header.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logger.CorrelationIDHeader)
+		if !validCorrelationID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(logger.CorrelationIDKey, id)
+		c.Header(logger.CorrelationIDHeader, id)
+		c.Next()
+	}
+}
+
+// validCorrelationID accepts IDs that are safe to log and echo: letters,
+// digits and -_.: up to maxCorrelationIDLength characters
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
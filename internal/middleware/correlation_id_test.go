@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CorrelationID())
+	router.GET("/me", func(c *gin.Context) {
+		c.String(http.StatusOK, logger.CorrelationID(c))
+	})
+
+	request := func(header string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+		if header != "" {
+			req.Header.Set(logger.CorrelationIDHeader, header)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("generated when missing", func(t *testing.T) {
+		w := request("")
+		id := w.Header().Get(logger.CorrelationIDHeader)
+		_, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, id, w.Body.String())
+
+		assert.NotEqual(t, id, request("").Header().Get(logger.CorrelationIDHeader))
+	})
+
+	t.Run("client id is kept", func(t *testing.T) {
+		w := request("web-1234")
+		assert.Equal(t, "web-1234", w.Header().Get(logger.CorrelationIDHeader))
+		assert.Equal(t, "web-1234", w.Body.String())
+	})
+
+	t.Run("malformed client id is replaced", func(t *testing.T) {
+		for _, header := range []string{"bad id\"}", strings.Repeat("a", maxCorrelationIDLength+1)} {
+			id := request(header).Header().Get(logger.CorrelationIDHeader)
+			_, err := uuid.Parse(id)
+			assert.NoError(t, err, header)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type, If-Match, If-None-Match, X-Correlation-ID"
+	corsExposeHeaders = "ETag, X-Correlation-ID"
+	corsMaxAge        = "600"
+)
+
+// CORS lets browsers on the allowed origins call the API. allowedOrigins is a
+// comma-separated list of origins, or "*" for any. Preflight requests are
+// answered here: 204 for allowed origins, 403 for others.
+func CORS(allowedOrigins string) gin.HandlerFunc {
+	allowAll := false
+	origins := make(map[string]struct{})
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		switch origin {
+		case "":
+		case "*":
+			allowAll = true
+		default:
+			origins[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		_, listed := origins[strings.ToLower(origin)]
+		allowed := allowAll || listed
+		if allowed {
+			if allowAll {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Add("Vary", "Origin")
+			}
+			c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+
+		if c.Request.Method != http.MethodOptions || c.GetHeader("Access-Control-Request-Method") == "" {
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+		c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+		c.Header("Access-Control-Max-Age", corsMaxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
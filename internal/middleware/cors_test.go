@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCORSRouter(allowedOrigins string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(allowedOrigins))
+	router.GET("/api/v1/me", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/api/v1/me", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "authorization, x-correlation-id")
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	t.Run("allowed origin", func(t *testing.T) {
+		router := setupCORSRouter("https://app.example.com, https://admin.example.com/")
+
+		w := corsRequest(router, http.MethodGet, "https://admin.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+		w = corsRequest(router, http.MethodOptions, "https://app.example.com")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Correlation-ID")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodDelete)
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		router := setupCORSRouter("https://app.example.com")
+
+		w := corsRequest(router, http.MethodGet, "https://evil.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = corsRequest(router, http.MethodOptions, "https://evil.example.com")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		router := setupCORSRouter("*")
+
+		w := corsRequest(router, http.MethodGet, "https://anything.example.com")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Vary"))
+
+		w = corsRequest(router, http.MethodOptions, "https://anything.example.com")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("same-origin requests are untouched", func(t *testing.T) {
+		w := corsRequest(setupCORSRouter("*"), http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
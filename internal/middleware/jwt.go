@@ -1,50 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-	"strings"
-
-	"github.com/golang-jwt/jwt"
-	"github.com/labstack/echo/v4"
-)
-
-// JWTAuth creates a middleware for JWT authentication
-func JWTAuth(secret string) echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			authHeader := c.Request().Header.Get("Authorization")
-			if authHeader == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Missing authorization header")
-			}
-
-			// Check if the header has the Bearer prefix
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid authorization header format")
-			}
-
-			tokenString := parts[1]
-
-			// Parse the token
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid token signing method")
-				}
-				return []byte(secret), nil
-			})
-
-			if err != nil {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
-			}
-
-			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				// Add the user ID to the context
-				userID := uint(claims["user_id"].(float64))
-				c.Set("user_id", userID)
-				return next(c)
-			}
-
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
-		}
-	}
-}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"user-service/internal/logger"
+	"user-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts requests and records their latency by method, route and
+// status. The route is the matched pattern, keeping path IDs out of the
+// labels; unmatched requests share one route. The latency measured by
+// JSONLogMiddleware is used when it runs inside this middleware.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		latency, ok := c.Value(logger.LatencyKey).(time.Duration)
+		if !ok {
+			latency = time.Since(start)
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.Inc(c.Request.Method, route, status)
+		metrics.HTTPRequestDuration.Observe(latency.Seconds(), c.Request.Method, route, status)
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/internal/logger"
+	"user-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/contacts/:id", func(c *gin.Context) {
+		c.Set(logger.LatencyKey, 3*time.Second)
+		c.Status(http.StatusNoContent)
+	})
+
+	before := metrics.HTTPRequests.Value(http.MethodGet, "/contacts/:id", "204")
+	unmatchedBefore := metrics.HTTPRequests.Value(http.MethodGet, "unmatched", "404")
+	for _, path := range []string{"/contacts/1", "/contacts/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are labelled with the route pattern, not the path
+	assert.Equal(t, before+2, metrics.HTTPRequests.Value(http.MethodGet, "/contacts/:id", "204"))
+	assert.Equal(t, unmatchedBefore+1, metrics.HTTPRequests.Value(http.MethodGet, "unmatched", "404"))
+
+	w := httptest.NewRecorder()
+	metrics.Default.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	// The latency stored by the logger is reused
+	assert.Contains(t, w.Body.String(), `http_request_duration_seconds_bucket{method="GET",route="/contacts/:id",status="204",le="2.5"} 0`)
+}
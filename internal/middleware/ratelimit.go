@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"user-service/internal/app/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitedCode is the error code carried by every 429 response
+const RateLimitedCode = "RATE_LIMITED"
+
+// RespondRateLimited aborts the request with 429, a Retry-After header and the
+// standard rate-limit body. All rate-limiting and lockout paths should use it.
+func RespondRateLimited(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, models.Response{
+		Status:     0,
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "Too many requests",
+		Data: gin.H{
+			"code":                RateLimitedCode,
+			"retry_after_seconds": seconds,
+		},
+	})
+}
+
+// RateLimiter is an in-memory fixed-window limiter keyed by an arbitrary string
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter allows limit requests per key in each window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit.
+// When it is not, the time until the window resets is returned.
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.prune(now)
+		l.windows[key] = &rateWindow{start: now, count: 1}
+		return true, 0
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// prune drops expired windows so the map does not grow without bound
+func (l *RateLimiter) prune(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// RateLimit throttles requests per key using limiter
+func RateLimit(limiter *RateLimiter, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, retryAfter := limiter.Allow(key(c), time.Now()); !ok {
+			RespondRateLimited(c, retryAfter)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientIPKey keys rate limits by the client IP address
+func ClientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// UserKey keys rate limits by the authenticated user, falling back to the client IP
+func UserKey(c *gin.Context) string {
+	if userID := c.GetUint("user_id"); userID != 0 {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(NewRateLimiter(2, time.Minute), ClientIPKey))
+	router.POST("/login", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send().Code)
+	assert.Equal(t, http.StatusOK, send().Code)
+
+	w := send()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.True(t, retryAfter >= 1 && retryAfter <= 60)
+
+	var response models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 0, response.Status)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, RateLimitedCode, data["code"])
+	assert.Equal(t, float64(retryAfter), data["retry_after_seconds"])
+}
+
+func TestRateLimiter_WindowReset(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Minute)
+	now := time.Now()
+
+	ok, _ := limiter.Allow("key", now)
+	assert.True(t, ok)
+
+	ok, retryAfter := limiter.Allow("key", now.Add(20*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	ok, _ = limiter.Allow("other", now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	ok, _ = limiter.Allow("key", now.Add(time.Minute))
+	assert.True(t, ok)
+}
+
+func TestRespondRateLimited_RoundsUp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	RespondRateLimited(c, 1500*time.Millisecond)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+	"user-service/internal/app/models"
+	"user-service/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handlerPanic carries a panic raised in another goroutine of the request,
+// such as the timeout middleware's, together with the stack where it happened
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// Recovery turns a panic in a later handler into a 500 response and logs it
+// with its stack trace. The correlation ID is read when the panic is caught,
+// so Recovery can run before the CorrelationID middleware.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := debug.Stack()
+			if p, ok := recovered.(*handlerPanic); ok {
+				recovered, stack = p.value, p.stack
+			}
+			logger.LogPanic(c, recovered, stack)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Status:     0,
+				StatusCode: http.StatusInternalServerError,
+				Message:    "Internal server error",
+				Data:       gin.H{},
+			})
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+	"user-service/internal/logger"
+	"user-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	router.GET("/ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	timed := router.Group("/timed")
+	timed.Use(TimeoutMiddleware(time.Second))
+	timed.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	for _, path := range []string{"/panic", "/timed/panic"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			var response models.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			assert.Equal(t, "Internal server error", response.Message)
+		})
+	}
+
+	t.Run("requests after a panic are served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/ok", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRecovery_Ordering(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	panicking := func(c *gin.Context) { panic("boom") }
+
+	// The chain SetupRoutes wires, with a panic in each layer
+	for _, tc := range []struct {
+		name    string
+		path    string
+		chain   []gin.HandlerFunc
+		counted bool
+	}{
+		{"handler", "/panic/handler", []gin.HandlerFunc{Recovery(), CorrelationID(), Tracing(), Metrics(), Recovery(), panicking}, true},
+		{"middleware outside the inner Recovery", "/panic/middleware", []gin.HandlerFunc{Recovery(), CorrelationID(), panicking, Metrics(), Recovery()}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET(tc.path, append(tc.chain, func(c *gin.Context) { c.Status(http.StatusOK) })...)
+			before := metrics.HTTPRequests.Value(http.MethodGet, tc.path, "500")
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(logger.CorrelationIDHeader, "req-123")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, "req-123", w.Header().Get(logger.CorrelationIDHeader))
+			if tc.counted {
+				// The inner Recovery lets Metrics record the panic as a 500
+				assert.Equal(t, before+1, metrics.HTTPRequests.Value(http.MethodGet, tc.path, "500"))
+			}
+		})
+	}
+}
@@ -1,23 +1,15 @@
 package middleware
 
-import (
-	"os"
-
-	"github.com/labstack/echo/v4"
-)
-
-func BasicAuthValidator(username, password string, c echo.Context) (bool, error) {
-	authUsername := os.Getenv("BASIC_AUTH_USERNAME")
-	authPassword := os.Getenv("BASIC_AUTH_PASSWORD")
-
-	lang := c.Request().Header.Get("Accept-Language")
-	if lang == "" {
-		return false, nil
+import "github.com/gin-gonic/gin"
+
+// SecureHeaders adds security headers to the response
+func SecureHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-XSS-Protection", "1; mode=block")
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("Content-Security-Policy", "default-src 'self'")
+		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
+		c.Next()
 	}
-
-	// Save downstream
-	c.Set("lang", lang)
-	c.Set("auth", c.Request().Header.Get(echo.HeaderAuthorization))
-
-	return username == authUsername && password == authPassword, nil
 }
@@ -1,58 +1,216 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"runtime/debug"
+	"sync"
 	"time"
+	"user-service/internal/app/models"
 	"user-service/internal/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
-// TimeoutMiddleware adds timeout handling to requests
+// TimeoutMiddleware cancels the request context after timeout and answers 408
+// if the handler has not started its response by then. The handler's later
+// writes are discarded. The middleware still waits for the handler to return,
+// since gin reuses the context afterwards, so handlers should honour
+// c.Request.Context().
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Create a channel to signal timeout
-		timeoutChan := make(chan struct{})
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
 
-		// Start a goroutine that will close the channel after timeout
-		go func() {
-			time.Sleep(timeout)
-			close(timeoutChan)
-		}()
+		tw := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx, timeout: timeout, header: http.Header{}, status: c.Writer.Status()}
+		c.Writer = tw
+		defer func() { c.Writer = tw.ResponseWriter }()
 
 		// Create a channel to signal request completion
 		doneChan := make(chan struct{})
+		panicChan := make(chan *handlerPanic, 1)
 
 		// Start the request processing in a goroutine
 		go func() {
 			defer close(doneChan)
+			// A panic here would crash the server, so hand it to the
+			// request goroutine for Recovery to handle
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicChan <- &handlerPanic{value: recovered, stack: debug.Stack()}
+				}
+			}()
 			c.Next()
 		}()
 
 		// Wait for either completion or timeout
 		select {
 		case <-doneChan:
-			// Request completed normally
-			return
-		case <-timeoutChan:
-			// Request timed out
-			if !c.Writer.Written() {
-				// Log timeout error
-				logger.LogEndpointTimeout(c, "TimeoutMiddleware", timeout, map[string]interface{}{
-					"middleware": "timeout",
-				})
-
-				c.AbortWithStatusJSON(http.StatusRequestTimeout, gin.H{
-					"status":     0,
-					"statusCode": http.StatusRequestTimeout,
-					"message":    "Request timeout",
-					"data": gin.H{
-						"error":           "Request processing took too long",
-						"timeout_seconds": timeout.Seconds(),
-					},
-				})
+		case <-ctx.Done():
+			// Answer now rather than when the handler gets round to returning
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.mu.Lock()
+				tw.start()
+				tw.mu.Unlock()
 			}
-			return
+			<-doneChan
+		}
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+		default:
+		}
+
+		tw.mu.Lock()
+		tw.finish()
+		timedOut := tw.timedOut
+		tw.mu.Unlock()
+
+		if timedOut {
+			logger.LogEndpointTimeout(c, "TimeoutMiddleware", timeout, map[string]interface{}{
+				"middleware": "timeout",
+			})
+		}
+	}
+}
+
+// timeoutWriter holds back the handler's response until it starts, which after
+// the deadline means the 408 response. Once either has started the other is
+// ignored. The handler's headers are kept apart until its response starts.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx     context.Context
+	timeout time.Duration
+
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	statusSet bool
+	started   bool
+	timedOut  bool
+}
+
+// start begins the response: the handler's status and headers, or the 408
+// response once the deadline has passed. It must be called with mu held and
+// reports whether the handler may write.
+func (w *timeoutWriter) start() bool {
+	if w.timedOut {
+		return false
+	}
+	if !w.started {
+		if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+			w.writeTimeout()
+			return false
+		}
+		dst := w.ResponseWriter.Header()
+		for name, values := range w.header {
+			dst[name] = values
 		}
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.WriteHeaderNow()
+		w.started = true
+	}
+	return true
+}
+
+// finish completes a response the handler returned without starting: a status
+// it set is sent, otherwise only its headers are handed to the underlying
+// writer, which gin then sends as usual. It must be called with mu held.
+func (w *timeoutWriter) finish() {
+	if w.started || w.timedOut {
+		return
+	}
+	if w.statusSet || errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.start()
+		return
 	}
+	dst := w.ResponseWriter.Header()
+	for name, values := range w.header {
+		dst[name] = values
+	}
+}
+
+// writeTimeout sends the 408 response. It must be called with mu held.
+func (w *timeoutWriter) writeTimeout() {
+	w.timedOut = true
+
+	body, _ := json.Marshal(models.Response{
+		Status:     0,
+		StatusCode: http.StatusRequestTimeout,
+		Message:    "Request timeout",
+		Data: gin.H{
+			"error":           "Request processing took too long",
+			"timeout_seconds": w.timeout.Seconds(),
+		},
+	})
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusRequestTimeout)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started && !w.timedOut {
+		w.status = code
+		w.statusSet = true
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.start()
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.timedOut {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Written reports true once the response has started or timed out, so
+// handlers checking it stop writing
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started || w.timedOut
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Size()
 }
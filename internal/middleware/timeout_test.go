@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/app/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutMiddleware(50 * time.Millisecond))
+
+	handlerErr := make(chan error, 1)
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/status-only", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		handlerErr <- c.Request.Context().Err()
+		c.Header("ETag", `"late"`)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/stubborn", func(c *gin.Context) {
+		time.Sleep(150 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// assertSingleTimeout checks the body holds the 408 response and nothing else
+	assertSingleTimeout := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusRequestTimeout, w.Code)
+		decoder := json.NewDecoder(strings.NewReader(w.Body.String()))
+		var response models.Response
+		require.NoError(t, decoder.Decode(&response))
+		assert.Equal(t, http.StatusRequestTimeout, response.StatusCode)
+		assert.Equal(t, "Request timeout", response.Message)
+		assert.Equal(t, io.EOF, decoder.Decode(&struct{}{}))
+	}
+
+	t.Run("fast handler responds normally", func(t *testing.T) {
+		w := request("/fast")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+		assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	})
+
+	t.Run("status without a body is sent", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request("/status-only").Code)
+	})
+
+	t.Run("unknown routes keep the 404 response", func(t *testing.T) {
+		w := request("/missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "404 page not found", w.Body.String())
+	})
+
+	t.Run("slow handler is cancelled", func(t *testing.T) {
+		w := request("/slow")
+		assertSingleTimeout(t, w)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.ErrorIs(t, <-handlerErr, context.DeadlineExceeded)
+	})
+
+	t.Run("handler ignoring cancellation cannot write", func(t *testing.T) {
+		assertSingleTimeout(t, request("/stubborn"))
+	})
+}
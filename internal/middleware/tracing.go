@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"user-service/internal/logger"
+	"user-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing starts a server span per request, named by method and route and
+// continuing the caller's trace when a traceparent header is sent. The span
+// carries the correlation ID, so it must run after CorrelationID.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracing.Enabled() {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := tracing.ContextWithTraceparent(c.Request.Context(), c.GetHeader(tracing.TraceparentHeader))
+		ctx, span := tracing.StartKind(ctx, c.Request.Method+" "+route, tracing.KindServer)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("correlation_id", logger.CorrelationID(c))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", status)
+		if status >= 500 {
+			span.RecordError(errors.New(http.StatusText(status)))
+		}
+	}
+}
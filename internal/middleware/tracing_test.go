@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/internal/logger"
+	"user-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracing.NewRecorder()
+	tracing.SetExporter(recorder)
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+
+	router := gin.New()
+	router.Use(CorrelationID(), Tracing())
+	var handlerSpan *tracing.Span
+	router.GET("/contacts/:id", func(c *gin.Context) {
+		handlerSpan = tracing.FromContext(c.Request.Context())
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/7", nil)
+	req.Header.Set(logger.CorrelationIDHeader, "req-123")
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Same(t, span, handlerSpan)
+	assert.Equal(t, "GET /contacts/:id", span.Name)
+	assert.Equal(t, tracing.KindServer, span.Kind)
+	assert.Equal(t, "req-123", span.Attribute("correlation_id"))
+	assert.Equal(t, "/contacts/:id", span.Attribute("http.route"))
+	assert.Equal(t, http.StatusServiceUnavailable, span.Attribute("http.response.status_code"))
+	assert.Equal(t, "Service Unavailable", span.Error())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Traceparent()[3:35])
+}
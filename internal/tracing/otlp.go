@@ -0,0 +1,240 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"user-service/internal/logger"
+)
+
+// Batching limits of the OTLP exporter
+const (
+	otlpBatchSize     = 512
+	otlpQueueSize     = 2048
+	otlpFlushInterval = 5 * time.Second
+)
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector using
+// OTLP over HTTP with JSON encoding. Spans arriving while the queue is full
+// are dropped rather than slowing requests down.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	Client      *http.Client
+
+	queue    chan *Span
+	done     chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// NewOTLPExporter creates an exporter posting to the collector at endpoint,
+// e.g. http://otel-collector:4318, and starts its background sender
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	e := &OTLPExporter{
+		url:         url,
+		serviceName: serviceName,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan *Span, otlpQueueSize),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues the span for the next batch
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+	}
+}
+
+// Shutdown sends the queued spans and stops the exporter, giving up when ctx is done
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.done) })
+	select {
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, otlpBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			logger.Warn("Failed to export spans", map[string]interface{}{
+				"spans": len(batch),
+				"error": err.Error(),
+			})
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == otlpBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.done:
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) == otlpBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.Client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP JSON messages, see opentelemetry-proto's trace service
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+// otlpStatusError is the OTLP status code of a failed span
+const otlpStatusError = 2
+
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		}
+		if span.ParentID != ([8]byte{}) {
+			s.ParentSpanID = hex.EncodeToString(span.ParentID[:])
+		}
+		for _, attr := range span.Attributes() {
+			s.Attributes = append(s.Attributes, otlpAttribute(attr.Key, attr.Value))
+		}
+		if msg := span.Error(); msg != "" {
+			s.Status = &otlpStatus{Code: otlpStatusError, Message: msg}
+		}
+		out = append(out, s)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpKeyValue{otlpAttribute("service.name", e.serviceName)}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: e.serviceName}, Spans: out}},
+	}}}
+}
+
+func otlpAttribute(key string, value interface{}) otlpKeyValue {
+	var v otlpValue
+	switch value := value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.Itoa(value)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case uint:
+		s := strconv.FormatUint(uint64(value), 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
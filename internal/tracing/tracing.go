@@ -0,0 +1,239 @@
+// Package tracing records spans of work done for a request and hands finished
+// spans to an exporter. Without an exporter, starting a span does nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Span kinds, numbered as in OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// TraceparentHeader carries the W3C trace context linking spans across services
+const TraceparentHeader = "traceparent"
+
+// Exporter receives spans once they have ended
+type Exporter interface {
+	Export(span *Span)
+}
+
+type exporterHolder struct{ exporter Exporter }
+
+var current atomic.Pointer[exporterHolder]
+
+// SetExporter sends finished spans to e. A nil exporter turns tracing off.
+func SetExporter(e Exporter) {
+	if e == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&exporterHolder{exporter: e})
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Attribute is a key and a string, integer, float or boolean value
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is a timed operation within a trace. A nil span, returned while
+// tracing is off, ignores every call.
+type Span struct {
+	TraceID   [16]byte
+	SpanID    [8]byte
+	ParentID  [8]byte
+	Name      string
+	Kind      int
+	StartTime time.Time
+	EndTime   time.Time
+
+	mu         sync.Mutex
+	attributes []Attribute
+	err        string
+	ended      bool
+	exporter   Exporter
+}
+
+type spanKey struct{}
+
+// FromContext returns the span carried by ctx, if any
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts an internal span named name, a child of the span carried by ctx
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal)
+}
+
+// StartKind starts a span of the given kind, a child of the span carried by ctx
+func StartKind(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	holder := current.Load()
+	if holder == nil {
+		return ctx, nil
+	}
+
+	span := &Span{Name: name, Kind: kind, StartTime: time.Now(), exporter: holder.exporter}
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(remoteParent); ok {
+		span.TraceID = remote.traceID
+		span.ParentID = remote.spanID
+	} else {
+		_, _ = rand.Read(span.TraceID[:])
+	}
+	_, _ = rand.Read(span.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttribute records key with value on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.attributes {
+		if s.attributes[i].Key == key {
+			s.attributes[i].Value = value
+			return
+		}
+	}
+	s.attributes = append(s.attributes, Attribute{Key: key, Value: value})
+}
+
+// Attributes returns the attributes recorded on the span
+func (s *Span) Attributes() []Attribute {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attribute(nil), s.attributes...)
+}
+
+// Attribute returns the value recorded for key, or nil
+func (s *Span) Attribute(key string) interface{} {
+	for _, attr := range s.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+// RecordError marks the span as failed with err; a nil error is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+// Error returns the message of the error the span failed with, if any
+func (s *Span) Error() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// End ends the span and exports it. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.exporter.Export(s)
+}
+
+// Traceparent returns the W3C trace context header value identifying the span
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(s.TraceID[:]) + "-" + hex.EncodeToString(s.SpanID[:]) + "-01"
+}
+
+// remoteParent is a span of another service, taken from a traceparent header
+type remoteParent struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+type remoteKey struct{}
+
+// ContextWithTraceparent returns a copy of ctx under which spans without a
+// local parent continue the trace in the traceparent header value. Values
+// that are not valid version 00 trace contexts are ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+
+	var remote remoteParent
+	if _, err := hex.Decode(remote.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(remote.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if remote.traceID == ([16]byte{}) || remote.spanID == ([8]byte{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, remote)
+}
+
+// Recorder keeps finished spans in memory, for tests
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Export records the span
+func (r *Recorder) Export(span *Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// Spans returns the recorded spans in the order they ended
+func (r *Recorder) Spans() []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Span(nil), r.spans...)
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useRecorder(t *testing.T) *Recorder {
+	t.Helper()
+	recorder := NewRecorder()
+	SetExporter(recorder)
+	t.Cleanup(func() { SetExporter(nil) })
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	t.Run("disabled spans are no-ops", func(t *testing.T) {
+		ctx, span := Start(context.Background(), "operation")
+
+		assert.Nil(t, span)
+		assert.Nil(t, FromContext(ctx))
+		span.SetAttribute("key", "value")
+		span.RecordError(errors.New("failed"))
+		span.End()
+	})
+
+	t.Run("children share the trace", func(t *testing.T) {
+		recorder := useRecorder(t)
+
+		ctx, parent := Start(context.Background(), "parent")
+		_, child := Start(ctx, "child")
+		child.RecordError(errors.New("failed"))
+		child.End()
+		child.End()
+		parent.End()
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[0].Name)
+		assert.Equal(t, parent.TraceID, child.TraceID)
+		assert.Equal(t, parent.SpanID, child.ParentID)
+		assert.Equal(t, [8]byte{}, parent.ParentID)
+		assert.Equal(t, "failed", child.Error())
+	})
+
+	t.Run("traceparent continues the caller's trace", func(t *testing.T) {
+		useRecorder(t)
+		ctx := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, span := Start(ctx, "operation")
+
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-", span.Traceparent()[:36])
+		assert.Equal(t, [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}, span.ParentID)
+	})
+
+	t.Run("invalid traceparent is ignored", func(t *testing.T) {
+		for _, header := range []string{"", "garbage", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+			ctx := ContextWithTraceparent(context.Background(), header)
+			assert.Nil(t, ctx.Value(remoteKey{}), header)
+		}
+	})
+}
+
+func TestOTLPExporter(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, "user-service")
+	SetExporter(exporter)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	ctx, parent := StartKind(context.Background(), "GET /me", KindServer)
+	_, child := Start(ctx, "service.GetUserProfile")
+	child.SetAttribute("user.id", uint(7))
+	child.RecordError(errors.New("record not found"))
+	child.End()
+	parent.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, exporter.Shutdown(shutdownCtx))
+
+	var request otlpRequest
+	require.NoError(t, json.Unmarshal(<-bodies, &request))
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Equal(t, "user-service", *request.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, "service.GetUserProfile", spans[0].Name)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Len(t, spans[0].TraceID, 32)
+	assert.Equal(t, KindServer, spans[1].Kind)
+	assert.Equal(t, "user.id", spans[0].Attributes[0].Key)
+	assert.Equal(t, "7", *spans[0].Attributes[0].Value.IntValue)
+	assert.Equal(t, &otlpStatus{Code: otlpStatusError, Message: "record not found"}, spans[0].Status)
+	assert.Empty(t, spans[1].ParentSpanID)
+}
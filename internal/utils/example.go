@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validationExamples enables example payloads in bind failure responses
+var validationExamples bool
+
+// SetValidationExamples enables or disables example payloads in bind failure
+// responses. It is meant to be called once at startup.
+func SetValidationExamples(enabled bool) {
+	validationExamples = enabled
+}
+
+// ValidationExamplesEnabled reports whether bind failures carry an example payload
+func ValidationExamplesEnabled() bool {
+	return validationExamples
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// PayloadExample builds an example of the JSON payload v is decoded from,
+// using the json tags for names and an `example` tag or the field's binding
+// rules and type for values
+func PayloadExample(v interface{}) interface{} {
+	return exampleValue(reflect.TypeOf(v), "")
+}
+
+func exampleValue(t reflect.Type, binding string) interface{} {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return "2025-01-01T00:00:00Z"
+	case t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(unmarshalerType):
+		// Custom decoders accept shapes reflection cannot see
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if example, ok := f.Tag.Lookup("example"); ok {
+				fields[name] = example
+				continue
+			}
+			fields[name] = exampleValue(f.Type, f.Tag.Get("binding"))
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		return []interface{}{exampleValue(t.Elem(), "")}
+	case reflect.Map:
+		return map[string]interface{}{}
+	case reflect.String:
+		return exampleString(binding)
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return exampleNumber(binding)
+	case reflect.Float32, reflect.Float64:
+		return float64(exampleNumber(binding))
+	default:
+		return nil
+	}
+}
+
+// exampleString picks a string satisfying common binding rules
+func exampleString(binding string) string {
+	for _, rule := range strings.Split(binding, ",") {
+		switch {
+		case rule == "email":
+			return "user@example.com"
+		case rule == "url":
+			return "https://example.com"
+		case strings.HasPrefix(rule, "oneof="):
+			return strings.Fields(strings.TrimPrefix(rule, "oneof="))[0]
+		}
+	}
+	return "string"
+}
+
+// exampleNumber picks a number satisfying a min binding rule
+func exampleNumber(binding string) int {
+	for _, rule := range strings.Split(binding, ",") {
+		if value, ok := strings.CutPrefix(rule, "min="); ok {
+			if n, err := strconv.Atoi(value); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadExample(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type request struct {
+		Name     string    `json:"name" binding:"required"`
+		Email    string    `json:"email" binding:"required,email"`
+		Website  *string   `json:"website,omitempty" binding:"omitempty,url"`
+		Kind     string    `json:"kind" binding:"oneof=home work"`
+		Uses     int       `json:"uses" binding:"required,min=1"`
+		Active   bool      `json:"active"`
+		Tags     []string  `json:"tags"`
+		Address  address   `json:"address"`
+		StartsAt time.Time `json:"starts_at"`
+		Code     string    `json:"code" example:"ABC123"`
+		Internal string    `json:"-"`
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"name":      "string",
+		"email":     "user@example.com",
+		"website":   "https://example.com",
+		"kind":      "home",
+		"uses":      1,
+		"active":    false,
+		"tags":      []interface{}{"string"},
+		"address":   map[string]interface{}{"city": "string"},
+		"starts_at": "2025-01-01T00:00:00Z",
+		"code":      "ABC123",
+	}, PayloadExample(&request{}))
+
+	t.Run("slices of requests", func(t *testing.T) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"city": "string"}}, PayloadExample([]address{}))
+	})
+}
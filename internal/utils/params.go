@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseIDParam parses a positive integer ID from the named path parameter. On
+// failure it writes the standard "Invalid contact ID" 400 response and returns
+// false, and the handler should stop.
+func ParseIDParam(c *gin.Context, name string) (uint, bool) {
+	return ParseIDParamWithMessage(c, name, "Invalid contact ID")
+}
+
+// ParseIDParamWithMessage is ParseIDParam with a custom error message
+func ParseIDParamWithMessage(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, strconv.IntSize)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":      0,
+			"status_code": http.StatusBadRequest,
+			"message":     message,
+			"data":        gin.H{},
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
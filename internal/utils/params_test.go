@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIDParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parse := func(value string) (uint, bool, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: value}}
+		id, ok := ParseIDParam(c, "id")
+		return id, ok, w
+	}
+
+	t.Run("valid ID", func(t *testing.T) {
+		id, ok, w := parse("42")
+
+		assert.True(t, ok)
+		assert.Equal(t, uint(42), id)
+		assert.Empty(t, w.Body.Bytes())
+	})
+
+	for _, value := range []string{"abc", "0", "-1", "", "1.5", "99999999999999999999"} {
+		t.Run("rejects "+value, func(t *testing.T) {
+			id, ok, w := parse(value)
+
+			assert.False(t, ok)
+			assert.Zero(t, id)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "Invalid contact ID", body["message"])
+			assert.Equal(t, float64(http.StatusBadRequest), body["status_code"])
+		})
+	}
+
+	t.Run("custom message", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "jobId", Value: "x"}}
+
+		_, ok := ParseIDParamWithMessage(c, "jobId", "Invalid import job ID")
+
+		assert.False(t, ok)
+		assert.Contains(t, w.Body.String(), "Invalid import job ID")
+	})
+}
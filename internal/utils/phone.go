@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Phone output formats. Stored phone numbers are canonical E.164 digits, i.e.
+// the country calling code followed by the national number without the "+".
+const (
+	PhoneFormatE164          = "e164"
+	PhoneFormatNational      = "national"
+	PhoneFormatInternational = "international"
+)
+
+// NormalizePhone returns the canonical stored form of a phone number: E.164
+// digits without the leading "+". Spaces, dashes, dots and parentheses are
+// dropped and a single leading "+" is accepted. ok is false for anything else
+// and for numbers that are not 7 to 15 digits starting with a country code.
+func NormalizePhone(phone string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	phone = strings.TrimPrefix(phone, "+")
+
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	normalized := digits.String()
+	if len(normalized) < 7 || len(normalized) > 15 || normalized[0] == '0' {
+		return "", false
+	}
+	return normalized, true
+}
+
+// ValidPhoneFormat reports whether format is a known phone output format
+func ValidPhoneFormat(format string) bool {
+	switch format {
+	case PhoneFormatE164, PhoneFormatNational, PhoneFormatInternational:
+		return true
+	}
+	return false
+}
+
+// trunkPrefixes maps the calling codes FormatPhone understands to the prefix
+// dialled before national numbers in that region
+var trunkPrefixes = map[string]string{
+	"1":   "",
+	"7":   "8",
+	"31":  "0",
+	"33":  "0",
+	"34":  "",
+	"39":  "",
+	"44":  "0",
+	"49":  "0",
+	"60":  "0",
+	"61":  "0",
+	"62":  "0",
+	"63":  "0",
+	"65":  "",
+	"66":  "0",
+	"81":  "0",
+	"82":  "0",
+	"84":  "0",
+	"86":  "0",
+	"91":  "0",
+	"852": "",
+	"971": "0",
+}
+
+// FormatPhone renders a stored phone number in the given format. National and
+// international formats need the calling code to be known; numbers with an
+// unknown calling code are returned in E.164 instead.
+func FormatPhone(phone, format string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	if digits == "" {
+		return phone
+	}
+
+	e164 := "+" + digits
+	if format != PhoneFormatNational && format != PhoneFormatInternational {
+		return e164
+	}
+
+	code, national, ok := splitCallingCode(digits)
+	if !ok {
+		return e164
+	}
+
+	if code == "1" && len(national) == 10 {
+		// North American numbers: (NPA) NXX-XXXX
+		if format == PhoneFormatNational {
+			return "(" + national[:3] + ") " + national[3:6] + "-" + national[6:]
+		}
+		return "+1 " + national[:3] + "-" + national[3:6] + "-" + national[6:]
+	}
+
+	grouped := groupDigits(national)
+	if format == PhoneFormatNational {
+		return trunkPrefixes[code] + grouped
+	}
+	return "+" + code + " " + grouped
+}
+
+// splitCallingCode separates a known calling code from the national number
+func splitCallingCode(digits string) (string, string, bool) {
+	for length := 3; length >= 1; length-- {
+		if len(digits) <= length {
+			continue
+		}
+		if _, ok := trunkPrefixes[digits[:length]]; ok {
+			return digits[:length], digits[length:], true
+		}
+	}
+	return "", "", false
+}
+
+// groupDigits splits a national number into blocks of four from the right
+func groupDigits(national string) string {
+	var groups []string
+	for len(national) > 4 {
+		groups = append([]string{national[len(national)-4:]}, groups...)
+		national = national[:len(national)-4]
+	}
+	return strings.Join(append([]string{national}, groups...), " ")
+}
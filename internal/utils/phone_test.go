@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone string
+		want  string
+		ok    bool
+	}{
+		{"digits", "14155552671", "14155552671", true},
+		{"leading plus is dropped", "+14155552671", "14155552671", true},
+		{"spaces", " +44 20 7946 0958 ", "442079460958", true},
+		{"dashes", "1-415-555-2671", "14155552671", true},
+		{"parentheses", "+1 (415) 555-2671", "14155552671", true},
+		{"dots", "62.812.3456.7890", "6281234567890", true},
+		{"letters", "+1 415 CALL NOW", "", false},
+		{"plus inside the number", "1+4155552671", "", false},
+		{"other symbols", "+1#4155552671", "", false},
+		{"national trunk prefix", "081234567890", "", false},
+		{"too short", "+123456", "", false},
+		{"too long", "+1234567890123456", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizePhone(tt.phone)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatPhone(t *testing.T) {
+	tests := []struct {
+		name          string
+		phone         string
+		e164          string
+		national      string
+		international string
+	}{
+		{"north american number", "14155552671", "+14155552671", "(415) 555-2671", "+1 415-555-2671"},
+		{"uk number", "442079460958", "+442079460958", "020 7946 0958", "+44 20 7946 0958"},
+		{"indonesian mobile", "6281234567890", "+6281234567890", "0812 3456 7890", "+62 812 3456 7890"},
+		{"region without a trunk prefix", "390612345678", "+390612345678", "06 1234 5678", "+39 06 1234 5678"},
+		{"unknown calling code falls back to e164", "99912345678", "+99912345678", "+99912345678", "+99912345678"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.e164, FormatPhone(tt.phone, PhoneFormatE164))
+			assert.Equal(t, tt.national, FormatPhone(tt.phone, PhoneFormatNational))
+			assert.Equal(t, tt.international, FormatPhone(tt.phone, PhoneFormatInternational))
+		})
+	}
+
+	t.Run("number stored with a plus sign", func(t *testing.T) {
+		assert.Equal(t, "+14155552671", FormatPhone("+14155552671", PhoneFormatE164))
+	})
+
+	t.Run("known formats", func(t *testing.T) {
+		assert.True(t, ValidPhoneFormat(PhoneFormatNational))
+		assert.False(t, ValidPhoneFormat("local"))
+	})
+}
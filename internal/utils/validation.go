@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
@@ -8,6 +9,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// validationStatus is the status code of request binding and validation failures
+var validationStatus = http.StatusBadRequest
+
+// SetValidationStatus sets the status code written for request binding and
+// validation failures. Only 400 and 422 are accepted; it is meant to be called
+// once at startup.
+func SetValidationStatus(code int) error {
+	if code != http.StatusBadRequest && code != http.StatusUnprocessableEntity {
+		return fmt.Errorf("validation status code must be %d or %d, got %d", http.StatusBadRequest, http.StatusUnprocessableEntity, code)
+	}
+	validationStatus = code
+	return nil
+}
+
+// ValidationStatus returns the status code for request binding and validation failures
+func ValidationStatus() int {
+	return validationStatus
+}
+
 // EmailValidationError represents an email validation error
 type EmailValidationError struct {
 	Field   string
@@ -24,9 +44,9 @@ func ValidateEmail(email string) bool {
 // ValidateEmailWithResponse validates email and returns appropriate JSON response if invalid
 func ValidateEmailWithResponse(c *gin.Context, email string, fieldName string) bool {
 	if !ValidateEmail(email) {
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(validationStatus, gin.H{
 			"status":      0,
-			"status_code": http.StatusBadRequest,
+			"status_code": validationStatus,
 			"message":     "Validation failed",
 			"data": gin.H{
 				"error": fieldName + " must be a valid email address",
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetValidationStatus(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationStatus(http.StatusBadRequest) })
+
+	t.Run("defaults to 400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, ValidationStatus())
+	})
+
+	t.Run("422 applies to the email helpers", func(t *testing.T) {
+		require.NoError(t, SetValidationStatus(http.StatusUnprocessableEntity))
+
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		assert.False(t, ValidateEmailField(c, "not-an-email"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"status_code":422`)
+	})
+
+	t.Run("other codes are rejected", func(t *testing.T) {
+		require.NoError(t, SetValidationStatus(http.StatusBadRequest))
+
+		assert.Error(t, SetValidationStatus(http.StatusConflict))
+		assert.Equal(t, http.StatusBadRequest, ValidationStatus())
+	})
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss is returned by Get for keys that are not cached
+var ErrMiss = errors.New("cache miss")
+
+// DefaultTimeout bounds each Redis call, so an unreachable server slows
+// requests down by at most this much before callers fall back
+const DefaultTimeout = 200 * time.Millisecond
+
+// Store is a cache shared between service instances
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// RedisStore is a Store backed by Redis
+type RedisStore struct {
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewRedisStore creates a store using client, giving each call DefaultTimeout
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, timeout: DefaultTimeout}
+}
+
+// Get returns the value cached under key, or ErrMiss
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set caches value under key for ttl
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete invalidates keys. It runs even when ctx is already cancelled, since a
+// missed invalidation leaves stale data behind until it expires.
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+	defer cancel()
+
+	return s.client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis serves the few RESP commands RedisStore sends, keeping values in
+// memory. Expiry is recorded but not enforced.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]string
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeRedis{listener: listener, data: map[string]string{}, ttls: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) ttl(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ttls[key]
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.reply(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		f.data[args[1]] = args[2]
+		if len(args) > 4 {
+			f.ttls[args[1]] = strings.ToLower(args[3]) + " " + args[4]
+		}
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := f.data[key]; ok {
+				delete(f.data, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+// readCommand reads one RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	server := startFakeRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: server.listener.Addr().String()})
+	defer client.Close()
+
+	store := NewRedisStore(client)
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "profile:1")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, store.Set(ctx, "profile:1", []byte("cached"), time.Minute))
+	value, err := store.Get(ctx, "profile:1")
+	require.NoError(t, err)
+	assert.Equal(t, "cached", string(value))
+	assert.Equal(t, "ex 60", server.ttl("profile:1"))
+
+	require.NoError(t, store.Delete(ctx, "profile:1"))
+	_, err = store.Get(ctx, "profile:1")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedisStore_Unavailable(t *testing.T) {
+	// Nothing listens on a closed listener's address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer client.Close()
+	store := NewRedisStore(client)
+
+	_, err = store.Get(context.Background(), "profile:1")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMiss)
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PingTimeout bounds how long a health ping waits on the database
+const PingTimeout = 2 * time.Second
+
+// Ping checks that the database behind gormDB is reachable, giving up after PingTimeout
+func Ping(ctx context.Context, gormDB *gorm.DB) error {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	return PingSQL(ctx, sqlDB)
+}
+
+// PingSQL checks that sqlDB is reachable, giving up after PingTimeout
+func PingSQL(ctx context.Context, sqlDB *sql.DB) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats returns the connection pool statistics of the database behind gormDB
+func Stats(gormDB *gorm.DB) sql.DBStats {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB
+}
+
+func TestPing(t *testing.T) {
+	t.Run("open database", func(t *testing.T) {
+		gormDB := openTestDB(t)
+
+		assert.NoError(t, Ping(context.Background(), gormDB))
+	})
+
+	t.Run("closed database fails promptly", func(t *testing.T) {
+		gormDB := openTestDB(t)
+		sqlDB, err := gormDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		start := time.Now()
+		err = Ping(context.Background(), gormDB)
+
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), PingTimeout)
+	})
+}
+
+func TestStats(t *testing.T) {
+	gormDB := openTestDB(t)
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(3)
+
+	require.NoError(t, Ping(context.Background(), gormDB))
+
+	stats := Stats(gormDB)
+	assert.Equal(t, 3, stats.MaxOpenConnections)
+	assert.GreaterOrEqual(t, stats.OpenConnections, 1)
+}
+
+func TestWarmup(t *testing.T) {
+	t.Run("opens and pings the requested connections", func(t *testing.T) {
+		gormDB := openTestDB(t)
+		sqlDB, err := gormDB.DB()
+		require.NoError(t, err)
+		sqlDB.SetMaxIdleConns(3)
+
+		_, err = Warmup(context.Background(), gormDB, 3)
+
+		require.NoError(t, err)
+		stats := Stats(gormDB)
+		assert.Equal(t, 3, stats.OpenConnections)
+		assert.Equal(t, 3, stats.Idle)
+	})
+
+	t.Run("capped at the open connection limit", func(t *testing.T) {
+		gormDB := openTestDB(t)
+		sqlDB, err := gormDB.DB()
+		require.NoError(t, err)
+		sqlDB.SetMaxOpenConns(2)
+
+		_, err = Warmup(context.Background(), gormDB, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, Stats(gormDB).OpenConnections)
+	})
+
+	t.Run("zero connections is a no-op", func(t *testing.T) {
+		gormDB := openTestDB(t)
+		before := Stats(gormDB).OpenConnections
+
+		_, err := Warmup(context.Background(), gormDB, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, before, Stats(gormDB).OpenConnections)
+	})
+
+	t.Run("closed database fails", func(t *testing.T) {
+		gormDB := openTestDB(t)
+		sqlDB, err := gormDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		_, err = Warmup(context.Background(), gormDB, 2)
+
+		assert.Error(t, err)
+	})
+}
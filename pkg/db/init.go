@@ -27,5 +27,22 @@ func InitDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := ConfigurePool(database, cfg); err != nil {
+		return nil, err
+	}
+
 	return database, nil
 }
+
+// ConfigurePool applies the connection pool limits of cfg to the database
+// behind gormDB. Zero open connections or lifetime means no limit.
+func ConfigurePool(gormDB *gorm.DB, cfg configs.Config) error {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	return nil
+}
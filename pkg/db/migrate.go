@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"log"
 	"user-service/internal/app/migrations"
 
@@ -28,3 +29,22 @@ func RunMigrations(db *gorm.DB) error {
 	log.Println("Database migrations completed successfully")
 	return nil
 }
+
+// EnsureUniqueUserPhone applies the optional unique index on users.phone,
+// logging every duplicated phone number when existing data prevents it
+func EnsureUniqueUserPhone(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	err = migrations.NewRunner(sqlDB).ApplyOptional(migrations.UniqueUserPhoneMigration())
+
+	var duplicates *migrations.DuplicateUserPhonesError
+	if errors.As(err, &duplicates) {
+		for _, d := range duplicates.Duplicates {
+			log.Printf("Duplicate user phone %s shared by %d users", d.Phone, d.Count)
+		}
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrQueryTimeout is returned by statements cancelled by UseQueryTimeout
+var ErrQueryTimeout = errors.New("database query timed out")
+
+const (
+	timeoutCancelKey = "timeout:cancel"
+	timeoutParentKey = "timeout:parent"
+)
+
+// UseQueryTimeout registers gorm callbacks bounding every statement by
+// timeout, on top of any deadline its context already has, so a slow query is
+// cancelled instead of holding a connection. Row statements are not bounded as
+// their rows are read after the callbacks run.
+func UseQueryTimeout(gormDB *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	callbacks := gormDB.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("timeout:before_create", startTimeout(timeout)),
+		callbacks.Create().After("gorm:create").Register("timeout:after_create", endTimeout(timeout)),
+		callbacks.Query().Before("gorm:query").Register("timeout:before_query", startTimeout(timeout)),
+		callbacks.Query().After("gorm:query").Register("timeout:after_query", endTimeout(timeout)),
+		callbacks.Update().Before("gorm:update").Register("timeout:before_update", startTimeout(timeout)),
+		callbacks.Update().After("gorm:update").Register("timeout:after_update", endTimeout(timeout)),
+		callbacks.Delete().Before("gorm:delete").Register("timeout:before_delete", startTimeout(timeout)),
+		callbacks.Delete().After("gorm:delete").Register("timeout:after_delete", endTimeout(timeout)),
+		callbacks.Raw().Before("gorm:raw").Register("timeout:before_raw", startTimeout(timeout)),
+		callbacks.Raw().After("gorm:raw").Register("timeout:after_raw", endTimeout(timeout)),
+	)
+}
+
+func startTimeout(timeout time.Duration) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+		tx.InstanceSet(timeoutParentKey, tx.Statement.Context)
+		tx.InstanceSet(timeoutCancelKey, cancel)
+		tx.Statement.Context = ctx
+	}
+}
+
+func endTimeout(timeout time.Duration) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(timeoutCancelKey)
+		if !ok {
+			return
+		}
+		if tx.Error != nil && errors.Is(tx.Statement.Context.Err(), context.DeadlineExceeded) {
+			tx.Error = fmt.Errorf("%w after %s: %w", ErrQueryTimeout, timeout, tx.Error)
+		}
+		value.(context.CancelFunc)()
+		// A chain reused after Count shares the statement, so the next call
+		// must start from the caller's context, not the cancelled one
+		if parent, ok := tx.InstanceGet(timeoutParentKey); ok {
+			tx.Statement.Context = parent.(context.Context)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUseQueryTimeout(t *testing.T) {
+	type item struct {
+		ID   uint
+		Name string
+	}
+	gormDB := openTestDB(t)
+	require.NoError(t, gormDB.AutoMigrate(&item{}))
+	require.NoError(t, UseQueryTimeout(gormDB, 50*time.Millisecond))
+
+	t.Run("fast statements succeed", func(t *testing.T) {
+		require.NoError(t, gormDB.WithContext(context.Background()).Create(&item{Name: "a"}).Error)
+		var items []item
+		require.NoError(t, gormDB.WithContext(context.Background()).Find(&items).Error)
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("a chain reused after Count keeps working", func(t *testing.T) {
+		chain := gormDB.WithContext(context.Background()).Model(&item{}).Where("name = ?", "a")
+		var total int64
+		require.NoError(t, chain.Count(&total).Error)
+		var items []item
+		require.NoError(t, chain.Find(&items).Error)
+		assert.Equal(t, int64(1), total)
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("cancelled context fails fast", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		var items []item
+		err := gormDB.WithContext(ctx).Find(&items).Error
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrQueryTimeout)
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("slow query is cancelled", func(t *testing.T) {
+		slowDB := openTestDB(t)
+		require.NoError(t, slowDB.AutoMigrate(&item{}))
+		require.NoError(t, UseQueryTimeout(slowDB, 10*time.Millisecond))
+		// Stand in for a slow query by running out the deadline before it is sent
+		require.NoError(t, slowDB.Callback().Query().After("timeout:before_query").Before("gorm:query").
+			Register("test:slow", func(*gorm.DB) { time.Sleep(20 * time.Millisecond) }))
+
+		var items []item
+		err := slowDB.WithContext(context.Background()).Find(&items).Error
+
+		assert.ErrorIs(t, err, ErrQueryTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
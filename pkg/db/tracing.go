@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"user-service/internal/tracing"
+
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "tracing:span"
+
+// UseTracing registers gorm callbacks starting a client span around every
+// statement, named by operation and table, e.g. "db.query contacts", as a
+// child of the span in the statement's context
+func UseTracing(gormDB *gorm.DB) error {
+	callbacks := gormDB.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		_, span := tracing.StartKind(tx.Statement.Context, "db."+operation, tracing.KindClient)
+		if span != nil {
+			tx.InstanceSet(tracingSpanKey, span)
+		}
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(*tracing.Span)
+	if tx.Statement.Table != "" {
+		span.Name += " " + tx.Statement.Table
+		span.SetAttribute("db.sql.table", tx.Statement.Table)
+	}
+	span.SetAttribute("db.system", tx.Dialector.Name())
+	span.SetAttribute("db.statement", tx.Statement.SQL.String())
+	span.SetAttribute("db.rows_affected", tx.Statement.RowsAffected)
+	if !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+	}
+	span.End()
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Warmup opens n pooled connections up front and pings each, so requests right
+// after startup do not pay connection setup latency. n is capped at the pool's
+// open connection limit; the connections return to the idle pool afterwards, so
+// n should not exceed the idle limit. It returns how long the warmup took.
+func Warmup(ctx context.Context, gormDB *gorm.DB, n int) (time.Duration, error) {
+	start := time.Now()
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return 0, err
+	}
+	if maxOpen := sqlDB.Stats().MaxOpenConnections; maxOpen > 0 && n > maxOpen {
+		n = maxOpen
+	}
+
+	// Holding every connection until all are pinged forces the pool to open n
+	// distinct connections instead of reusing the first one
+	conns := make([]*sql.Conn, 0, max(n, 0))
+	defer func() {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return time.Since(start), err
+		}
+		conns = append(conns, conn)
+
+		pingCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+		err = conn.PingContext(pingCtx)
+		cancel()
+		if err != nil {
+			return time.Since(start), err
+		}
+	}
+	return time.Since(start), nil
+}
@@ -89,10 +89,11 @@ func (tdb *TestDB) MigrateTestDB() error {
 
 // TestUser creates a test user for testing
 func TestUser() *models.User {
+	phone := "1234567890"
 	return &models.User{
 		FullName: "Test User",
 		Email:    "test@example.com",
-		Phone:    "+1234567890",
+		Phone:    &phone,
 		Password: "hashedpassword",
 	}
 }